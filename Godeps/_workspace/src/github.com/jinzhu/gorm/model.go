@@ -1,10 +0,0 @@
-package gorm
-
-import "time"
-
-type Model struct {
-	ID        uint `gorm:"primary_key"`
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	DeletedAt *time.Time
-}
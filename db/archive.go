@@ -0,0 +1,521 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ArchiveVersion is the version of the archive format written by Export
+const ArchiveVersion = 1
+
+// Archive is a copy of the bot data that can be moved to another database.
+// Rows keep their ids, so importing the same archive twice leaves the
+// database as after the first import
+type Archive struct {
+	Version       int
+	SchemaVersion int
+	CreatedAt     time.Time
+	Projects      []Project
+	Users         []User
+	Settings      []Setting
+	Timers        []Timer
+	TimerSegments []TimerSegment
+	TimerClaims   []TimerClaim
+	Vacations     []Vacation
+	PokerSessions []PokerSession
+	PokerStories  []PokerStory
+	PokerVotes    []PokerVote
+}
+
+// Export returns an archive of the bot data. Secret settings are left out
+// unless secrets is true, and are kept encrypted otherwise
+func Export(secrets bool) (*Archive, error) {
+	a, err := Archives.Dump(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := SchemaVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	a.Version = ArchiveVersion
+	a.SchemaVersion = version
+	a.CreatedAt = time.Now().UTC()
+
+	if !secrets {
+		settings := []Setting{}
+		for _, s := range a.Settings {
+			if !IsSecretSetting(s.Name) && !isEncrypted(s.Value) {
+				settings = append(settings, s)
+			}
+		}
+		a.Settings = settings
+	}
+
+	return a, nil
+}
+
+// Import validates the archive and writes it to the database, replacing
+// the rows with the same ids. Rows of archives written before the bot kept
+// data per team go to DefaultTeam
+func Import(a *Archive) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+
+	for i := range a.Projects {
+		a.Projects[i].TeamID = TeamKey(a.Projects[i].TeamID)
+	}
+	for i := range a.Users {
+		a.Users[i].TeamID = TeamKey(a.Users[i].TeamID)
+	}
+	for i := range a.Timers {
+		a.Timers[i].TeamID = TeamKey(a.Timers[i].TeamID)
+	}
+	for i := range a.PokerSessions {
+		a.PokerSessions[i].TeamID = TeamKey(a.PokerSessions[i].TeamID)
+	}
+	for i := range a.Vacations {
+		a.Vacations[i].TeamID = TeamKey(a.Vacations[i].TeamID)
+	}
+	for i := range a.Settings {
+		s := &a.Settings[i]
+		s.TeamID, s.User = storedSettingOwner(*s)
+	}
+
+	return Archives.Restore(context.Background(), a)
+}
+
+// storedSettingOwner returns the team and owner of a setting read from an
+// archive or a store file. Team settings written before settings had teams
+// are owned by the team they name
+func storedSettingOwner(s Setting) (string, string) {
+	if s.Scope == ScopeTeam {
+		return settingOwner(s.User, s.Scope, s.User)
+	}
+	return settingOwner(s.TeamID, s.Scope, s.User)
+}
+
+// ReadArchive decodes an archive written by Write
+func ReadArchive(r io.Reader) (*Archive, error) {
+	a := &Archive{}
+	if err := json.NewDecoder(r).Decode(a); err != nil {
+		return nil, fmt.Errorf("Invalid archive: %s", err)
+	}
+	return a, nil
+}
+
+// Write encodes the archive as JSON
+func (a *Archive) Write(w io.Writer) error {
+	b, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+// Summary describes how many rows of each kind the archive holds
+func (a *Archive) Summary() string {
+	return fmt.Sprintf(
+		"%d projects, %d users, %d settings, %d timers, %d timer segments, "+
+			"%d timer claims, %d vacations, %d poker sessions, %d poker stories "+
+			"and %d poker votes",
+		len(a.Projects), len(a.Users), len(a.Settings), len(a.Timers),
+		len(a.TimerSegments), len(a.TimerClaims), len(a.Vacations), len(a.PokerSessions),
+		len(a.PokerStories), len(a.PokerVotes))
+}
+
+// Validate checks the archive can be imported by this build, returning
+// every problem found
+func (a *Archive) Validate() error {
+	errs := []string{}
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Sprintf(format, args...))
+	}
+
+	if a.Version < 1 || a.Version > ArchiveVersion {
+		return fmt.Errorf("Unsupported archive version %d, this build reads up to version %d",
+			a.Version, ArchiveVersion)
+	}
+	if a.SchemaVersion > LatestVersion() {
+		return fmt.Errorf("Archive was exported from schema version %d but this build only knows up to %d",
+			a.SchemaVersion, LatestVersion())
+	}
+
+	ids := map[string]map[int]bool{}
+	unique := func(kind string, id int) {
+		if ids[kind] == nil {
+			ids[kind] = map[int]bool{}
+		}
+		if id < 1 {
+			fail("%s with invalid id %d", kind, id)
+		} else if ids[kind][id] {
+			fail("duplicate %s id %d", kind, id)
+		}
+		ids[kind][id] = true
+	}
+	keys := map[string]bool{}
+	uniqueKey := func(kind, key string) {
+		if keys[kind+"/"+key] {
+			fail("duplicate %s %s", kind, key)
+		}
+		keys[kind+"/"+key] = true
+	}
+
+	for _, p := range a.Projects {
+		unique("project", p.Id)
+		uniqueKey("project", TeamKey(p.TeamID)+" "+p.Name)
+		if p.Name == "" {
+			fail("project %d has no name", p.Id)
+		}
+	}
+	for _, u := range a.Users {
+		if u.Id == nil {
+			fail("user %s has no id", u.Name)
+			continue
+		}
+		unique("user", *u.Id)
+		uniqueKey("user", TeamKey(u.TeamID)+" "+u.Name)
+		if u.Name == "" {
+			fail("user %d has no name", *u.Id)
+		}
+	}
+	for _, s := range a.Settings {
+		unique("setting", s.Id)
+		team, owner := storedSettingOwner(s)
+		uniqueKey("setting", team+" "+s.Scope+" "+owner+" "+s.Name)
+		if !ValidScope(s.Scope) || s.User == "" || s.Name == "" {
+			fail("setting %d must have a scope, owner and name", s.Id)
+		}
+		s.TeamID, s.User = team, owner
+		if _, err := decryptSetting(s); err != nil {
+			fail("setting %d: %s, configure the keys of the exporting bot", s.Id, err)
+		}
+	}
+	for _, t := range a.Timers {
+		unique("timer", t.ID)
+		if t.User == "" || t.Name == "" || t.CreatedAt == nil {
+			fail("timer %d must have a user, name and start time", t.ID)
+		}
+	}
+	for _, seg := range a.TimerSegments {
+		unique("timer segment", seg.ID)
+		if !ids["timer"][seg.TimerID] {
+			fail("timer segment %d belongs to missing timer %d", seg.ID, seg.TimerID)
+		}
+		if seg.StartedAt == nil {
+			fail("timer segment %d has no start time", seg.ID)
+		}
+	}
+	for _, c := range a.TimerClaims {
+		unique("timer claim", c.ID)
+		if !ids["timer"][c.TimerID] {
+			fail("timer claim %d belongs to missing timer %d", c.ID, c.TimerID)
+		}
+	}
+	for _, v := range a.Vacations {
+		unique("vacation", v.ID)
+		if v.StartDate == nil || v.EndDate == nil {
+			fail("vacation %d must have start and end dates", v.ID)
+		}
+	}
+	for _, ps := range a.PokerSessions {
+		unique("poker session", ps.ID)
+	}
+	for _, ps := range a.PokerStories {
+		unique("poker story", ps.ID)
+		if !ids["poker session"][ps.SessionID] {
+			fail("poker story %d belongs to missing session %d", ps.ID, ps.SessionID)
+		}
+	}
+	for _, v := range a.PokerVotes {
+		unique("poker vote", v.ID)
+		uniqueKey("poker vote", fmt.Sprintf("%d %s", v.StoryID, v.User))
+		if !ids["poker story"][v.StoryID] {
+			fail("poker vote %d belongs to missing story %d", v.ID, v.StoryID)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("Invalid archive:\n%s", strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+//---------- Postgres
+
+type pgArchives struct{}
+
+func (pgArchives) Dump(ctx context.Context) (*Archive, error) {
+	a := &Archive{}
+	var err error
+
+	if a.Projects, err = Projects.Every(ctx); err != nil {
+		return nil, err
+	}
+	if a.Users, err = Users.Every(ctx); err != nil {
+		return nil, err
+	}
+	if a.Settings, err = Settings.Every(ctx); err != nil {
+		return nil, err
+	}
+	if a.Timers, err = Timers.Every(ctx); err != nil {
+		return nil, err
+	}
+
+	con, err := handle()
+	if err != nil {
+		return nil, err
+	}
+
+	dump := func(query string, fn func(row scanner) error) error {
+		rows, err := con.QueryContext(ctx, query)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			if err := fn(rows); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	}
+
+	err = dump(`
+    SELECT "id", "timer_id", "started_at", "ended_at"
+    FROM "timer_segments" ORDER BY "id"`, func(row scanner) error {
+		seg, err := setTimerSegment(row)
+		if err == nil {
+			a.TimerSegments = append(a.TimerSegments, *seg)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = dump(`
+    SELECT "id", "timer_id", "pivotal_story", "mavenlink_story",
+           "time_entry_id", "minutes", "created_at"
+    FROM "timer_claims" ORDER BY "id"`, func(row scanner) error {
+		c, err := setTimerClaim(row)
+		if err == nil {
+			a.TimerClaims = append(a.TimerClaims, *c)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = dump(`
+    SELECT "id", "team_id", "user", "description", "start_date", "end_date"
+    FROM "vacations" ORDER BY "id"`, func(row scanner) error {
+		v, err := setVacation(row)
+		if err == nil {
+			a.Vacations = append(a.Vacations, *v)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = dump(`
+    SELECT "id", "team_id", "channel", "title", "users", "finished_at"
+    FROM "poker_sessions" ORDER BY "id"`, func(row scanner) error {
+		ps, err := setPokerSession(row)
+		if err == nil {
+			a.PokerSessions = append(a.PokerSessions, *ps)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = dump(`
+    SELECT "id", "poker_session_id", "title", "estimation"
+    FROM "poker_stories" ORDER BY "id"`, func(row scanner) error {
+		ps, err := setPokerStory(row)
+		if err == nil {
+			a.PokerStories = append(a.PokerStories, *ps)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = dump(`
+    SELECT "id", "poker_story_id", "user", "vote"
+    FROM "poker_votes" ORDER BY "id"`, func(row scanner) error {
+		v, err := setPokerVote(row)
+		if err == nil {
+			a.PokerVotes = append(a.PokerVotes, *v)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Restore upserts every row by id in a single transaction and moves the
+// id sequences past the imported rows
+func (pgArchives) Restore(ctx context.Context, a *Archive) error {
+	return withTx(ctx, func(tx *sql.Tx) error {
+		up := func(table string, cols []string, rows [][]interface{}) error {
+			for _, row := range rows {
+				if err := upsert(ctx, tx, table, cols, row); err != nil {
+					return fmt.Errorf("%s %v: %s", table, row[0], err)
+				}
+			}
+
+			_, err := tx.ExecContext(ctx, `
+        SELECT setval(pg_get_serial_sequence('"`+table+`"', 'id'),
+                      GREATEST((SELECT MAX("id") FROM "`+table+`"), 1))`)
+			return err
+		}
+
+		rows := [][]interface{}{}
+		for _, p := range a.Projects {
+			rows = append(rows, []interface{}{p.Id, p.TeamID, p.Name, p.PivotalId,
+				p.MavenlinkId, p.CreatedBy, p.MvnSprintStoryId, p.Channel})
+		}
+		err := up("projects", []string{"id", "team_id", "name", "pivotal_id",
+			"mavenlink_id", "created_by", "mvn_sprint_story_id", "channel"}, rows)
+		if err != nil {
+			return err
+		}
+
+		rows = [][]interface{}{}
+		for _, u := range a.Users {
+			rows = append(rows, []interface{}{*u.Id, u.TeamID, u.Name, u.PivotalId, u.MavenlinkId})
+		}
+		err = up("users", []string{"id", "team_id", "name", "pivotal_id", "mavenlink_id"}, rows)
+		if err != nil {
+			return err
+		}
+
+		// an imported setting replaces the one with the same owner and name
+		rows = [][]interface{}{}
+		for _, s := range a.Settings {
+			_, err := tx.ExecContext(ctx, `
+        DELETE FROM settings
+        WHERE "team_id" = $1 AND "scope" = $2 AND "user" = $3 AND "name" = $4
+              AND "id" <> $5`,
+				s.TeamID, s.Scope, s.User, s.Name, s.Id)
+			if err != nil {
+				return err
+			}
+			rows = append(rows, []interface{}{s.Id, s.TeamID, s.Scope, s.User, s.Name, s.Value})
+		}
+		err = up("settings", []string{"id", "team_id", "scope", "user", "name", "value"}, rows)
+		if err != nil {
+			return err
+		}
+
+		rows = [][]interface{}{}
+		for _, t := range a.Timers {
+			rows = append(rows, []interface{}{t.ID, t.TeamID, t.User, t.Name, t.CreatedAt,
+				t.FinishedAt, t.PausedAt, t.PauseReason, int64(t.Adjustment / time.Second),
+				t.Notes, t.PivotalStory, t.MavenlinkStory, t.NudgedAt})
+		}
+		err = up("timers", []string{"id", "team_id", "user", "name", "created_at",
+			"finished_at", "paused_at", "pause_reason", "adjustment", "notes",
+			"pivotal_story", "mavenlink_story", "nudged_at"}, rows)
+		if err != nil {
+			return err
+		}
+
+		rows = [][]interface{}{}
+		for _, seg := range a.TimerSegments {
+			rows = append(rows, []interface{}{seg.ID, seg.TimerID, seg.StartedAt, seg.EndedAt})
+		}
+		err = up("timer_segments", []string{"id", "timer_id", "started_at", "ended_at"}, rows)
+		if err != nil {
+			return err
+		}
+
+		rows = [][]interface{}{}
+		for _, c := range a.TimerClaims {
+			rows = append(rows, []interface{}{c.ID, c.TimerID, c.PivotalStory,
+				c.MavenlinkStory, c.TimeEntryID, c.Minutes, c.CreatedAt})
+		}
+		err = up("timer_claims", []string{"id", "timer_id", "pivotal_story",
+			"mavenlink_story", "time_entry_id", "minutes", "created_at"}, rows)
+		if err != nil {
+			return err
+		}
+
+		rows = [][]interface{}{}
+		for _, v := range a.Vacations {
+			rows = append(rows, []interface{}{v.ID, v.TeamID, v.User, v.Description,
+				v.StartDate, v.EndDate})
+		}
+		err = up("vacations", []string{"id", "team_id", "user", "description",
+			"start_date", "end_date"}, rows)
+		if err != nil {
+			return err
+		}
+
+		rows = [][]interface{}{}
+		for _, ps := range a.PokerSessions {
+			rows = append(rows, []interface{}{ps.ID, ps.TeamID, ps.Channel, ps.Title,
+				ps.Users, ps.FinishedAt})
+		}
+		err = up("poker_sessions", []string{"id", "team_id", "channel", "title",
+			"users", "finished_at"}, rows)
+		if err != nil {
+			return err
+		}
+
+		rows = [][]interface{}{}
+		for _, ps := range a.PokerStories {
+			rows = append(rows, []interface{}{ps.ID, ps.SessionID, ps.Title, ps.Estimation})
+		}
+		err = up("poker_stories", []string{"id", "poker_session_id", "title", "estimation"}, rows)
+		if err != nil {
+			return err
+		}
+
+		rows = [][]interface{}{}
+		for _, v := range a.PokerVotes {
+			rows = append(rows, []interface{}{v.ID, v.StoryID, v.User, v.Vote})
+		}
+		return up("poker_votes", []string{"id", "poker_story_id", "user", "vote"}, rows)
+	})
+}
+
+// upsert inserts a row, or updates the row with the same id. The id must
+// be the first column
+func upsert(ctx context.Context, q querier, table string, cols []string, values []interface{}) error {
+	params := []string{}
+	updates := []string{}
+	for i, c := range cols {
+		params = append(params, fmt.Sprintf("$%d", i+1))
+		if i > 0 {
+			updates = append(updates, fmt.Sprintf(`"%s" = EXCLUDED."%s"`, c, c))
+		}
+	}
+
+	_, err := q.ExecContext(ctx, `
+    INSERT INTO "`+table+`" ("`+strings.Join(cols, `", "`)+`")
+    VALUES (`+strings.Join(params, ", ")+`)
+    ON CONFLICT ("id") DO UPDATE SET `+strings.Join(updates, ", "), values...)
+	return err
+}
@@ -0,0 +1,167 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// AuditEvent records a command run by a user or a change the bot made
+// on an external system on behalf of a user
+type AuditEvent struct {
+	ID        int
+	TeamID    string
+	User      string
+	Channel   string
+	Kind      string
+	Robot     string
+	Action    string
+	Target    string
+	Details   string
+	CreatedAt *time.Time
+}
+
+// AuditFilter narrows down the events returned by GetAuditEvents
+type AuditFilter struct {
+	TeamID string
+	User   string
+	Robot  string
+	From   *time.Time
+	To     *time.Time
+	Limit  int
+}
+
+var secretRegex = regexp.MustCompile(`([A-Za-z_]+)=\S+`)
+
+// RecordCommand adds an audit event for a command dispatched to a robot
+func RecordCommand(team, user, channel, robot, text string) {
+	recordAuditEvent(AuditEvent{
+		TeamID:  team,
+		User:    user,
+		Channel: channel,
+		Kind:    "command",
+		Robot:   robot,
+		Action:  "run",
+		Details: secretRegex.ReplaceAllString(text, "${1}=[redacted]"),
+	})
+}
+
+// RecordChange adds an audit event for a change made on an external system,
+// like a Pivotal story update or a Mavenlink time entry
+func RecordChange(team, user, system, action, target, details string) {
+	recordAuditEvent(AuditEvent{
+		TeamID:  team,
+		User:    user,
+		Kind:    "change",
+		Robot:   system,
+		Action:  action,
+		Target:  target,
+		Details: details,
+	})
+}
+
+// recordAuditEvent stores the event, only logging failures since auditing
+// must never break the action being audited
+func recordAuditEvent(e AuditEvent) {
+	if err := CreateAuditEvent(e); err != nil {
+		log.Printf("Error recording audit event %+v: %s", e, err)
+	}
+}
+
+func CreateAuditEvent(e AuditEvent) error {
+	e.TeamID = TeamKey(e.TeamID)
+	return Audit.Create(context.Background(), e)
+}
+
+// GetAuditEvents returns the most recent events of the filter's team
+// matching the filter
+func GetAuditEvents(f AuditFilter) ([]AuditEvent, error) {
+	f.TeamID = TeamKey(f.TeamID)
+	if f.Limit < 1 {
+		f.Limit = 50
+	}
+	return Audit.Find(context.Background(), f)
+}
+
+//---------- Postgres
+
+type pgAudit struct{}
+
+func (pgAudit) Create(ctx context.Context, e AuditEvent) error {
+	return pgExec(ctx, `
+    INSERT INTO audit_events
+    ("team_id", "user", "channel", "kind", "robot", "action", "target", "details")
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		e.TeamID, e.User, e.Channel, e.Kind, e.Robot, e.Action, e.Target, e.Details)
+}
+
+func (pgAudit) Find(ctx context.Context, f AuditFilter) ([]AuditEvent, error) {
+	con, err := handle()
+	if err != nil {
+		return nil, err
+	}
+
+	where := `"team_id" = $1`
+	args := []interface{}{f.TeamID}
+	cond := func(c string, v interface{}) {
+		args = append(args, v)
+		where += fmt.Sprintf(` AND %s $%d`, c, len(args))
+	}
+
+	if f.User != "" {
+		cond(`"user" =`, f.User)
+	}
+	if f.Robot != "" {
+		cond(`"robot" =`, f.Robot)
+	}
+	if f.From != nil {
+		cond(`"created_at" >=`, *f.From)
+	}
+	if f.To != nil {
+		cond(`"created_at" <`, *f.To)
+	}
+
+	rows, err := con.QueryContext(ctx, `
+    SELECT
+      "id", "team_id", "user", "channel", "kind", "robot", "action", "target",
+      "details", "created_at"
+    FROM audit_events
+    WHERE `+where+`
+    ORDER BY "id" DESC
+    LIMIT `+fmt.Sprintf("%d", f.Limit), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []AuditEvent{}
+	for rows.Next() {
+		e, err := setAuditEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		events = append(events, *e)
+	}
+
+	return events, rows.Err()
+}
+
+func setAuditEvent(row scanner) (*AuditEvent, error) {
+	var createdAt pq.NullTime
+
+	e := AuditEvent{}
+	err := row.Scan(&e.ID, &e.TeamID, &e.User, &e.Channel, &e.Kind, &e.Robot,
+		&e.Action, &e.Target, &e.Details, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	e.CreatedAt = nullTime(createdAt)
+
+	return &e, nil
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// StoryCard is a message the bot posted about a story, so reactions to it
+// can act on the story
+type StoryCard struct {
+	ID             int
+	TeamID         string
+	Channel        string
+	Timestamp      string
+	PivotalStory   string
+	MavenlinkStory string
+	CreatedAt      *time.Time
+}
+
+// CreateStoryCard records the story a message is about
+func CreateStoryCard(c StoryCard) error {
+	c.TeamID = TeamKey(c.TeamID)
+	return StoryCards.Create(context.Background(), c)
+}
+
+// GetStoryCard returns the card posted as the message with timestamp ts in
+// channel, or nil if the message isn't a story card
+func GetStoryCard(team, channel, ts string) (*StoryCard, error) {
+	return StoryCards.Get(context.Background(), TeamKey(team), channel, ts)
+}
+
+//---------- Postgres
+
+type pgStoryCards struct{}
+
+func (pgStoryCards) Create(ctx context.Context, c StoryCard) error {
+	return pgExec(ctx, `
+    INSERT INTO "story_cards"
+    ("team_id", "channel", "ts", "pivotal_story", "mavenlink_story")
+    VALUES ($1, $2, $3, $4, $5)`,
+		c.TeamID, c.Channel, c.Timestamp, c.PivotalStory, c.MavenlinkStory)
+}
+
+func (pgStoryCards) Get(ctx context.Context, team, channel, ts string) (*StoryCard, error) {
+	con, err := handle()
+	if err != nil {
+		return nil, err
+	}
+
+	return setStoryCard(con.QueryRowContext(ctx, `
+    SELECT
+      "id", "team_id", "channel", "ts", "pivotal_story", "mavenlink_story",
+      "created_at"
+    FROM "story_cards"
+    WHERE "team_id" = $1 AND "channel" = $2 AND "ts" = $3`, team, channel, ts))
+}
+
+// setStoryCard scans a story card, returning nil if a single row query
+// had no results
+func setStoryCard(row scanner) (*StoryCard, error) {
+	var createdAt pq.NullTime
+
+	c := StoryCard{}
+	err := row.Scan(&c.ID, &c.TeamID, &c.Channel, &c.Timestamp, &c.PivotalStory,
+		&c.MavenlinkStory, &createdAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.CreatedAt = nullTime(createdAt)
+
+	return &c, nil
+}
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Conversation holds the state of a multi-step dialog between the bot
+// and a user in a given channel
+type Conversation struct {
+	ID        int
+	TeamID    string
+	User      string
+	Channel   string
+	Dialog    string
+	Step      int
+	Answers   map[string]string
+	UpdatedAt *time.Time
+}
+
+// GetConversation returns the ongoing conversation for user in channel
+// or nil if there is none
+func GetConversation(team, user, channel string) (*Conversation, error) {
+	return Conversations.Get(context.Background(), TeamKey(team), user, channel)
+}
+
+// SaveConversation creates or updates the conversation for its
+// user and channel
+func SaveConversation(c *Conversation) error {
+	c.TeamID = TeamKey(c.TeamID)
+	return Conversations.Save(context.Background(), c)
+}
+
+// DeleteConversation ends the conversation for user in channel
+func DeleteConversation(team, user, channel string) error {
+	return Conversations.Delete(context.Background(), TeamKey(team), user, channel)
+}
+
+//---------- Postgres
+
+type pgConversations struct{}
+
+func (pgConversations) Get(ctx context.Context, team, user, channel string) (*Conversation, error) {
+	con, err := handle()
+	if err != nil {
+		return nil, err
+	}
+
+	return setConversation(con.QueryRowContext(ctx, `
+    SELECT
+      "id", "team_id", "user", "channel", "dialog", "step", "answers",
+      "updated_at"
+    FROM "conversations"
+    WHERE "team_id" = $1 AND "user" = $2 AND "channel" = $3`, team, user, channel))
+}
+
+func (pgConversations) Save(ctx context.Context, c *Conversation) error {
+	answers, err := json.Marshal(c.Answers)
+	if err != nil {
+		return err
+	}
+
+	return withTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `
+      UPDATE "conversations"
+      SET "dialog" = $1, "step" = $2, "answers" = $3,
+          "updated_at" = CURRENT_TIMESTAMP
+      WHERE "team_id" = $4 AND "user" = $5 AND "channel" = $6`,
+			c.Dialog, c.Step, string(answers), c.TeamID, c.User, c.Channel)
+		if err != nil {
+			return err
+		}
+
+		if n, err := res.RowsAffected(); err != nil || n > 0 {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+      INSERT INTO "conversations"
+      ("team_id", "user", "channel", "dialog", "step", "answers", "updated_at")
+      VALUES ($1, $2, $3, $4, $5, $6, CURRENT_TIMESTAMP)`,
+			c.TeamID, c.User, c.Channel, c.Dialog, c.Step, string(answers))
+		return err
+	})
+}
+
+func (pgConversations) Delete(ctx context.Context, team, user, channel string) error {
+	return pgExec(ctx, `
+    DELETE FROM "conversations"
+    WHERE "team_id" = $1 AND "user" = $2 AND "channel" = $3`, team, user, channel)
+}
+
+// setConversation scans a conversation, returning nil if a single row
+// query had no results
+func setConversation(row scanner) (*Conversation, error) {
+	var answers string
+	var updatedAt pq.NullTime
+
+	c := Conversation{}
+	err := row.Scan(&c.ID, &c.TeamID, &c.User, &c.Channel, &c.Dialog, &c.Step,
+		&answers, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.Answers = map[string]string{}
+	if err := json.Unmarshal([]byte(answers), &c.Answers); err != nil {
+		return nil, err
+	}
+
+	c.UpdatedAt = nullTime(updatedAt)
+
+	return &c, nil
+}
@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	pool     *sql.DB
+	poolOnce sync.Once
+	poolErr  error
+)
+
+// querier is implemented both by the pool and by transactions, so the same
+// queries can run standalone or as part of a multi-step operation
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// scanner is implemented by both *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// handle returns the connection pool shared by the whole package, opening
+// it on first use. The pool size is set by DATABASE_MAX_CONNECTIONS
+func handle() (*sql.DB, error) {
+	poolOnce.Do(func() {
+		pool, poolErr = sql.Open("postgres", os.Getenv("DATABASE_URL"))
+		if poolErr != nil {
+			return
+		}
+
+		max := 10
+		if n, err := strconv.Atoi(os.Getenv("DATABASE_MAX_CONNECTIONS")); err == nil && n > 0 {
+			max = n
+		}
+		pool.SetMaxOpenConns(max)
+		pool.SetMaxIdleConns(max)
+	})
+
+	return pool, poolErr
+}
+
+// pgExec runs a statement that returns no rows on the pool
+func pgExec(ctx context.Context, query string, args ...interface{}) error {
+	con, err := handle()
+	if err != nil {
+		return err
+	}
+
+	_, err = con.ExecContext(ctx, query, args...)
+	return err
+}
+
+// withTx runs fn in a transaction, committing if it succeeds and rolling
+// back otherwise
+func withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	con, err := handle()
+	if err != nil {
+		return err
+	}
+
+	tx, err := con.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// nullTime converts a scanned timestamp into the pointer used by the models
+func nullTime(t pq.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
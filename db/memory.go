@@ -0,0 +1,1262 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// memData is everything kept by the in-memory backend, and the layout of
+// the file written by the file backend
+type memData struct {
+	LastID        int
+	Projects      []Project
+	Users         []User
+	Settings      []Setting
+	Timers        []Timer
+	TimerSegments []TimerSegment
+	TimerClaims   []TimerClaim
+	PokerSessions []PokerSession
+	PokerStories  []PokerStory
+	PokerVotes    []PokerVote
+	Vacations     []Vacation
+	Reminders     []Reminder
+	Conversations []Conversation
+	StoryCards    []StoryCard
+	AuditEvents   []AuditEvent
+	UndoActions   []UndoAction
+	Undone        map[int]bool
+}
+
+// memStore implements every repository on top of plain slices. When path
+// is set the data is loaded from it and written back after each change
+type memStore struct {
+	sync.Mutex
+	path string
+	data memData
+}
+
+func newMemStore(path string) (*memStore, error) {
+	s := &memStore{path: path, data: memData{Undone: map[int]bool{}}}
+	if path == "" {
+		return s, nil
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, &s.data); err != nil {
+		return nil, fmt.Errorf("reading %s: %s", path, err)
+	}
+	if s.data.Undone == nil {
+		s.data.Undone = map[int]bool{}
+	}
+	// files written before settings had scopes only have user settings
+	for i := range s.data.Settings {
+		if s.data.Settings[i].Scope == "" {
+			s.data.Settings[i].Scope = ScopeUser
+		}
+	}
+	s.data.defaultTeams()
+
+	return s, nil
+}
+
+// defaultTeams gives the rows written before the bot kept data per team
+// to DefaultTeam
+func (d *memData) defaultTeams() {
+	for i := range d.Projects {
+		d.Projects[i].TeamID = TeamKey(d.Projects[i].TeamID)
+	}
+	for i := range d.Users {
+		d.Users[i].TeamID = TeamKey(d.Users[i].TeamID)
+	}
+	for i := range d.Timers {
+		d.Timers[i].TeamID = TeamKey(d.Timers[i].TeamID)
+	}
+	for i := range d.PokerSessions {
+		d.PokerSessions[i].TeamID = TeamKey(d.PokerSessions[i].TeamID)
+	}
+	for i := range d.Vacations {
+		d.Vacations[i].TeamID = TeamKey(d.Vacations[i].TeamID)
+	}
+	for i := range d.Settings {
+		s := &d.Settings[i]
+		s.TeamID, s.User = storedSettingOwner(*s)
+	}
+	for i := range d.Reminders {
+		d.Reminders[i].TeamID = TeamKey(d.Reminders[i].TeamID)
+	}
+	for i := range d.Conversations {
+		d.Conversations[i].TeamID = TeamKey(d.Conversations[i].TeamID)
+	}
+	for i := range d.StoryCards {
+		d.StoryCards[i].TeamID = TeamKey(d.StoryCards[i].TeamID)
+	}
+	for i := range d.AuditEvents {
+		d.AuditEvents[i].TeamID = TeamKey(d.AuditEvents[i].TeamID)
+	}
+	for i := range d.UndoActions {
+		d.UndoActions[i].TeamID = TeamKey(d.UndoActions[i].TeamID)
+	}
+}
+
+// nextID returns a new id, unique across all entities
+func (s *memStore) nextID() int {
+	s.data.LastID++
+	return s.data.LastID
+}
+
+// save writes the data to the store's file, if any. The file is replaced
+// atomically so a crash never leaves it half written
+func (s *memStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	b, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), ".slackbot")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// write runs fn holding the lock and saves the result. When fn or the save
+// fail the data is put back as it was, so a change is never half applied
+func (s *memStore) write(fn func() error) error {
+	s.Lock()
+	defer s.Unlock()
+
+	backup, err := s.data.clone()
+	if err != nil {
+		return err
+	}
+
+	err = fn()
+	if err == nil {
+		err = s.save()
+	}
+	if err != nil {
+		s.data = *backup
+	}
+	return err
+}
+
+// clone returns a deep copy of the data, sharing nothing with it
+func (d *memData) clone() (*memData, error) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &memData{}
+	if err := json.Unmarshal(b, c); err != nil {
+		return nil, err
+	}
+	if c.Undone == nil {
+		c.Undone = map[int]bool{}
+	}
+	return c, nil
+}
+
+// nowPtr stands in for CURRENT_TIMESTAMP
+func nowPtr() *time.Time {
+	t := time.Now()
+	return &t
+}
+
+//---------- Projects
+
+type memProjects struct{ s *memStore }
+
+func (m memProjects) Create(ctx context.Context, p Project) error {
+	return m.s.write(func() error {
+		p.Id = m.s.nextID()
+		m.s.data.Projects = append(m.s.data.Projects, p)
+		return nil
+	})
+}
+
+func (m memProjects) All(ctx context.Context, team string) ([]Project, error) {
+	m.s.Lock()
+	defer m.s.Unlock()
+
+	projects := []Project{}
+	for _, p := range m.s.data.Projects {
+		if p.TeamID == team {
+			projects = append(projects, p)
+		}
+	}
+	return projects, nil
+}
+
+func (m memProjects) Every(ctx context.Context) ([]Project, error) {
+	m.s.Lock()
+	defer m.s.Unlock()
+
+	return append([]Project{}, m.s.data.Projects...), nil
+}
+
+func (m memProjects) Get(ctx context.Context, team string, id int64) (*Project, error) {
+	return m.GetBy(ctx, team, "id", strconv.FormatInt(id, 10))
+}
+
+func (m memProjects) GetBy(ctx context.Context, team string, field string, value string) (*Project, error) {
+	m.s.Lock()
+	defer m.s.Unlock()
+
+	for _, p := range m.s.data.Projects {
+		if p.TeamID != team {
+			continue
+		}
+
+		var v string
+		switch field {
+		case "id":
+			v = strconv.Itoa(p.Id)
+		case "name":
+			v = p.Name
+		case "channel":
+			v = p.Channel
+		default:
+			return nil, fmt.Errorf("Can't find projects by %s", field)
+		}
+
+		if v == value {
+			return &p, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (m memProjects) Update(ctx context.Context, p Project) error {
+	return m.s.write(func() error {
+		for i, e := range m.s.data.Projects {
+			if e.Id == p.Id {
+				m.s.data.Projects[i] = p
+			}
+		}
+		return nil
+	})
+}
+
+//---------- Users
+
+type memUsers struct{ s *memStore }
+
+func (m memUsers) All(ctx context.Context, team string) ([]User, error) {
+	m.s.Lock()
+	defer m.s.Unlock()
+
+	users := []User{}
+	for _, u := range m.s.data.Users {
+		if u.TeamID == team {
+			users = append(users, u)
+		}
+	}
+	return users, nil
+}
+
+func (m memUsers) Every(ctx context.Context) ([]User, error) {
+	m.s.Lock()
+	defer m.s.Unlock()
+
+	return append([]User{}, m.s.data.Users...), nil
+}
+
+func (m memUsers) GetBy(ctx context.Context, team string, field string, value string) (*User, error) {
+	if field != "name" {
+		return nil, fmt.Errorf("Can't find users by %s", field)
+	}
+
+	m.s.Lock()
+	defer m.s.Unlock()
+
+	if i := m.find(team, value); i >= 0 {
+		u := m.s.data.Users[i]
+		return &u, nil
+	}
+	return nil, nil
+}
+
+func (m memUsers) Create(ctx context.Context, u User) error {
+	return m.s.write(func() error {
+		m.create(u)
+		return nil
+	})
+}
+
+func (m memUsers) Update(ctx context.Context, u User) error {
+	return m.s.write(func() error {
+		return m.update(u)
+	})
+}
+
+func (m memUsers) Save(ctx context.Context, u User) error {
+	return m.s.write(func() error {
+		i := m.find(u.TeamID, u.Name)
+		if i < 0 {
+			m.create(u)
+			return nil
+		}
+
+		u.Id = m.s.data.Users[i].Id
+		return m.update(u)
+	})
+}
+
+func (m memUsers) find(team string, name string) int {
+	for i, u := range m.s.data.Users {
+		if u.TeamID == team && u.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m memUsers) create(u User) {
+	id := m.s.nextID()
+	u.Id = &id
+	m.s.data.Users = append(m.s.data.Users, u)
+}
+
+func (m memUsers) update(u User) error {
+	for i, e := range m.s.data.Users {
+		if u.Id != nil && *e.Id == *u.Id {
+			m.s.data.Users[i] = u
+			return nil
+		}
+	}
+	return errors.New("The user wasn't updated because it doesn't exist")
+}
+
+//---------- Settings
+
+type memSettings struct{ s *memStore }
+
+func (m memSettings) Get(ctx context.Context, team, scope, owner, name string) (*Setting, error) {
+	m.s.Lock()
+	defer m.s.Unlock()
+
+	if i := m.find(team, scope, owner, name); i >= 0 {
+		s := m.s.data.Settings[i]
+		return &s, nil
+	}
+	return nil, nil
+}
+
+func (m memSettings) All(ctx context.Context, team, scope, owner string) ([]Setting, error) {
+	m.s.Lock()
+	defer m.s.Unlock()
+
+	r := []Setting{}
+	for _, s := range m.s.data.Settings {
+		if s.TeamID == team && s.Scope == scope && s.User == owner {
+			r = append(r, s)
+		}
+	}
+	return r, nil
+}
+
+func (m memSettings) Every(ctx context.Context) ([]Setting, error) {
+	m.s.Lock()
+	defer m.s.Unlock()
+
+	return append([]Setting{}, m.s.data.Settings...), nil
+}
+
+func (m memSettings) Set(ctx context.Context, team, scope, owner, name, value string) error {
+	return m.s.write(func() error {
+		if i := m.find(team, scope, owner, name); i >= 0 {
+			m.s.data.Settings[i].Value = value
+			return nil
+		}
+
+		m.s.data.Settings = append(m.s.data.Settings, Setting{
+			Id: m.s.nextID(), TeamID: team, Scope: scope, User: owner,
+			Name: name, Value: value,
+		})
+		return nil
+	})
+}
+
+func (m memSettings) Remove(ctx context.Context, team, scope, owner, name string) (bool, error) {
+	removed := false
+	err := m.s.write(func() error {
+		if i := m.find(team, scope, owner, name); i >= 0 {
+			ss := m.s.data.Settings
+			m.s.data.Settings = append(ss[:i:i], ss[i+1:]...)
+			removed = true
+		}
+		return nil
+	})
+	return removed, err
+}
+
+func (m memSettings) find(team, scope, owner, name string) int {
+	for i, s := range m.s.data.Settings {
+		if s.TeamID == team && s.Scope == scope && s.User == owner && s.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+//---------- Timers
+
+type memTimers struct{ s *memStore }
+
+func (m memTimers) Create(ctx context.Context, t Timer) error {
+	return m.s.write(func() error {
+		t.ID = m.s.nextID()
+		m.s.data.Timers = append(m.s.data.Timers, t)
+		m.s.data.TimerSegments = append(m.s.data.TimerSegments, TimerSegment{
+			ID: m.s.nextID(), TimerID: t.ID, StartedAt: t.CreatedAt,
+		})
+		return nil
+	})
+}
+
+func (m memTimers) Get(ctx context.Context, id int) (*Timer, error) {
+	return m.first(func(t Timer) bool { return t.ID == id })
+}
+
+func (m memTimers) GetByName(ctx context.Context, team string, user string, name string) (*Timer, error) {
+	timers := m.find(func(t Timer) bool {
+		return t.TeamID == team && t.User == user && t.Name == name
+	})
+
+	// the unfinished timer, otherwise the newest one
+	var found *Timer
+	for i := range timers {
+		t := &timers[i]
+		switch {
+		case found == nil:
+			found = t
+		case (t.FinishedAt == nil) != (found.FinishedAt == nil):
+			if t.FinishedAt == nil {
+				found = t
+			}
+		case t.CreatedAt.After(*found.CreatedAt):
+			found = t
+		}
+	}
+	return found, nil
+}
+
+func (m memTimers) GetStartedByName(ctx context.Context, team string, user string, name string) (*Timer, error) {
+	timers := m.find(func(t Timer) bool {
+		return t.TeamID == team && t.User == user && t.Name == name &&
+			t.FinishedAt == nil
+	})
+	if len(timers) < 1 {
+		return nil, nil
+	}
+	return &timers[len(timers)-1], nil
+}
+
+func (m memTimers) GetStartedByStory(ctx context.Context, team string, user string, pivotalStory string) (*Timer, error) {
+	timers := m.find(func(t Timer) bool {
+		return t.TeamID == team && t.User == user && t.PivotalStory == pivotalStory &&
+			t.FinishedAt == nil
+	})
+	if len(timers) < 1 {
+		return nil, nil
+	}
+	return &timers[len(timers)-1], nil
+}
+
+func (m memTimers) Running(ctx context.Context, team string, user string) ([]Timer, error) {
+	return m.find(func(t Timer) bool {
+		return t.TeamID == team && t.User == user && t.FinishedAt == nil
+	}), nil
+}
+
+func (m memTimers) AllRunning(ctx context.Context) ([]Timer, error) {
+	return m.find(func(t Timer) bool { return t.FinishedAt == nil }), nil
+}
+
+func (m memTimers) Between(ctx context.Context, team string, user string, from time.Time, to time.Time) ([]Timer, error) {
+	timers := m.find(func(t Timer) bool {
+		return t.TeamID == team && (user == "" || t.User == user) &&
+			t.CreatedAt != nil && t.CreatedAt.Before(to) &&
+			(t.FinishedAt == nil || !t.FinishedAt.Before(from))
+	})
+	sort.SliceStable(timers, func(i, j int) bool {
+		return timers[i].CreatedAt.Before(*timers[j].CreatedAt)
+	})
+	return timers, nil
+}
+
+func (m memTimers) Every(ctx context.Context) ([]Timer, error) {
+	return m.find(func(t Timer) bool { return true }), nil
+}
+
+func (m memTimers) Stop(ctx context.Context, timer Timer, at time.Time) error {
+	return m.s.write(func() error {
+		for i, t := range m.s.data.Timers {
+			if t.ID == timer.ID && t.FinishedAt == nil {
+				finished := at
+				if t.CreatedAt != nil && t.CreatedAt.After(finished) {
+					finished = *t.CreatedAt
+				}
+				m.s.data.Timers[i].FinishedAt = &finished
+				m.s.data.Timers[i].PausedAt = nil
+				m.s.data.Timers[i].PauseReason = ""
+				m.closeSegments(t.ID, at)
+			}
+		}
+		return nil
+	})
+}
+
+func (m memTimers) Segments(ctx context.Context, timerID int) ([]TimerSegment, error) {
+	m.s.Lock()
+	defer m.s.Unlock()
+
+	segments := []TimerSegment{}
+	for _, seg := range m.s.data.TimerSegments {
+		if seg.TimerID == timerID {
+			segments = append(segments, seg)
+		}
+	}
+	sort.SliceStable(segments, func(i, j int) bool {
+		return segments[i].StartedAt.Before(*segments[j].StartedAt)
+	})
+	return segments, nil
+}
+
+func (m memTimers) Pause(ctx context.Context, timerID int, at time.Time, reason string) error {
+	return m.s.write(func() error {
+		for i, t := range m.s.data.Timers {
+			if t.ID == timerID && t.PausedAt == nil && t.FinishedAt == nil {
+				m.s.data.Timers[i].PausedAt = &at
+				m.s.data.Timers[i].PauseReason = reason
+				m.closeSegments(timerID, at)
+			}
+		}
+		return nil
+	})
+}
+
+func (m memTimers) Resume(ctx context.Context, timerID int, at time.Time) error {
+	return m.s.write(func() error {
+		for i, t := range m.s.data.Timers {
+			if t.ID == timerID && t.PausedAt != nil && t.FinishedAt == nil {
+				m.s.data.Timers[i].PausedAt = nil
+				m.s.data.Timers[i].PauseReason = ""
+				m.s.data.TimerSegments = append(m.s.data.TimerSegments, TimerSegment{
+					ID: m.s.nextID(), TimerID: timerID, StartedAt: &at,
+				})
+			}
+		}
+		return nil
+	})
+}
+
+func (m memTimers) AddSegment(ctx context.Context, timerID int, from time.Time, to time.Time) error {
+	return m.s.write(func() error {
+		m.s.data.TimerSegments = append(m.s.data.TimerSegments, TimerSegment{
+			ID: m.s.nextID(), TimerID: timerID, StartedAt: &from, EndedAt: &to,
+		})
+		return nil
+	})
+}
+
+func (m memTimers) Adjust(ctx context.Context, timerID int, d time.Duration) error {
+	return m.s.write(func() error {
+		for i, t := range m.s.data.Timers {
+			if t.ID == timerID {
+				m.s.data.Timers[i].Adjustment += d
+			}
+		}
+		return nil
+	})
+}
+
+func (m memTimers) Nudge(ctx context.Context, timerID int, at time.Time) error {
+	return m.s.write(func() error {
+		for i, t := range m.s.data.Timers {
+			if t.ID == timerID {
+				m.s.data.Timers[i].NudgedAt = &at
+			}
+		}
+		return nil
+	})
+}
+
+func (m memTimers) Delete(ctx context.Context, timerID int) error {
+	return m.s.write(func() error {
+		timers := m.s.data.Timers[:0]
+		for _, t := range m.s.data.Timers {
+			if t.ID != timerID {
+				timers = append(timers, t)
+			}
+		}
+		m.s.data.Timers = timers
+
+		segments := m.s.data.TimerSegments[:0]
+		for _, seg := range m.s.data.TimerSegments {
+			if seg.TimerID != timerID {
+				segments = append(segments, seg)
+			}
+		}
+		m.s.data.TimerSegments = segments
+
+		claims := m.s.data.TimerClaims[:0]
+		for _, c := range m.s.data.TimerClaims {
+			if c.TimerID != timerID {
+				claims = append(claims, c)
+			}
+		}
+		m.s.data.TimerClaims = claims
+		return nil
+	})
+}
+
+func (m memTimers) Claims(ctx context.Context, timerID int) ([]TimerClaim, error) {
+	m.s.Lock()
+	defer m.s.Unlock()
+
+	claims := []TimerClaim{}
+	for _, c := range m.s.data.TimerClaims {
+		if c.TimerID == timerID {
+			claims = append(claims, c)
+		}
+	}
+	return claims, nil
+}
+
+func (m memTimers) AddClaim(ctx context.Context, c TimerClaim) error {
+	return m.s.write(func() error {
+		now := time.Now()
+		c.ID = m.s.nextID()
+		c.CreatedAt = &now
+		m.s.data.TimerClaims = append(m.s.data.TimerClaims, c)
+		return nil
+	})
+}
+
+func (m memTimers) AddNote(ctx context.Context, timerID int, note string) error {
+	return m.s.write(func() error {
+		for i, t := range m.s.data.Timers {
+			if t.ID == timerID && t.Notes == "" {
+				m.s.data.Timers[i].Notes = note
+			} else if t.ID == timerID {
+				m.s.data.Timers[i].Notes += "\n" + note
+			}
+		}
+		return nil
+	})
+}
+
+// closeSegments ends the open segment of the timer at at, or when it
+// started if that's later
+func (m memTimers) closeSegments(timerID int, at time.Time) {
+	for i, seg := range m.s.data.TimerSegments {
+		if seg.TimerID != timerID || seg.EndedAt != nil {
+			continue
+		}
+
+		end := at
+		if seg.StartedAt != nil && seg.StartedAt.After(end) {
+			end = *seg.StartedAt
+		}
+		m.s.data.TimerSegments[i].EndedAt = &end
+	}
+}
+
+func (m memTimers) find(match func(Timer) bool) []Timer {
+	m.s.Lock()
+	defer m.s.Unlock()
+
+	timers := []Timer{}
+	for _, t := range m.s.data.Timers {
+		if match(t) {
+			timers = append(timers, t)
+		}
+	}
+	return timers
+}
+
+func (m memTimers) first(match func(Timer) bool) (*Timer, error) {
+	m.s.Lock()
+	defer m.s.Unlock()
+
+	for _, t := range m.s.data.Timers {
+		if match(t) {
+			return &t, nil
+		}
+	}
+	return nil, nil
+}
+
+//---------- Poker
+
+type memPoker struct{ s *memStore }
+
+func (m memPoker) StartSession(ctx context.Context, team string, channel string, title string, users string) error {
+	return m.s.write(func() error {
+		m.s.data.PokerSessions = append(m.s.data.PokerSessions, PokerSession{
+			ID: m.s.nextID(), TeamID: team, Channel: channel, Title: title,
+			Users: users,
+		})
+		return nil
+	})
+}
+
+func (m memPoker) CurrentSession(ctx context.Context, team string, channel string) (*PokerSession, error) {
+	m.s.Lock()
+	defer m.s.Unlock()
+
+	for _, ps := range m.s.data.PokerSessions {
+		if ps.TeamID == team && ps.Channel == channel && ps.FinishedAt == nil {
+			return &ps, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m memPoker) FinishSession(ctx context.Context, sessionID int) error {
+	return m.s.write(func() error {
+		for i, ps := range m.s.data.PokerSessions {
+			if ps.ID == sessionID {
+				m.s.data.PokerSessions[i].FinishedAt = nowPtr()
+			}
+		}
+		return nil
+	})
+}
+
+func (m memPoker) StartStory(ctx context.Context, sessionID int, title string) error {
+	return m.s.write(func() error {
+		m.s.data.PokerStories = append(m.s.data.PokerStories, PokerStory{
+			ID: m.s.nextID(), SessionID: sessionID, Title: title,
+		})
+		return nil
+	})
+}
+
+func (m memPoker) CurrentStory(ctx context.Context, sessionID int) (*PokerStory, error) {
+	stories := m.stories(func(ps PokerStory) bool {
+		return ps.SessionID == sessionID && ps.Estimation == nil
+	})
+	if len(stories) < 1 {
+		return nil, nil
+	}
+	return &stories[0], nil
+}
+
+func (m memPoker) Stories(ctx context.Context, sessionID int) ([]PokerStory, error) {
+	return m.stories(func(ps PokerStory) bool {
+		return ps.SessionID == sessionID
+	}), nil
+}
+
+func (m memPoker) EstimatedStories(ctx context.Context, sessionID int) ([]PokerStory, error) {
+	return m.stories(func(ps PokerStory) bool {
+		return ps.SessionID == sessionID && ps.Estimation != nil
+	}), nil
+}
+
+func (m memPoker) UpdateEstimation(ctx context.Context, storyID int, estimation string) error {
+	f, err := strconv.ParseFloat(estimation, 32)
+	if err != nil {
+		return err
+	}
+
+	e := float32(f)
+	return m.s.write(func() error {
+		for i, ps := range m.s.data.PokerStories {
+			if ps.ID == storyID {
+				m.s.data.PokerStories[i].Estimation = &e
+			}
+		}
+		return nil
+	})
+}
+
+func (m memPoker) CastVote(ctx context.Context, storyID int, user string, vote string) error {
+	f, err := strconv.ParseFloat(vote, 32)
+	if err != nil {
+		return err
+	}
+
+	return m.s.write(func() error {
+		m.s.data.PokerVotes = append(m.s.data.PokerVotes, PokerVote{
+			ID: m.s.nextID(), StoryID: storyID, User: user, Vote: float32(f),
+		})
+		return nil
+	})
+}
+
+func (m memPoker) Votes(ctx context.Context, storyID int) ([]PokerVote, error) {
+	m.s.Lock()
+	defer m.s.Unlock()
+
+	votes := []PokerVote{}
+	for _, v := range m.s.data.PokerVotes {
+		if v.StoryID == storyID {
+			votes = append(votes, v)
+		}
+	}
+	return votes, nil
+}
+
+func (m memPoker) stories(match func(PokerStory) bool) []PokerStory {
+	m.s.Lock()
+	defer m.s.Unlock()
+
+	stories := []PokerStory{}
+	for _, ps := range m.s.data.PokerStories {
+		if match(ps) {
+			stories = append(stories, ps)
+		}
+	}
+	return stories
+}
+
+//---------- Vacations
+
+type memVacations struct{ s *memStore }
+
+func (m memVacations) Create(ctx context.Context, v *Vacation) error {
+	return m.s.write(func() error {
+		v.ID = m.s.nextID()
+		m.s.data.Vacations = append(m.s.data.Vacations, *v)
+		return nil
+	})
+}
+
+func (m memVacations) EndingAfter(ctx context.Context, team string, t time.Time) ([]Vacation, error) {
+	return m.find(func(v Vacation) bool {
+		return v.TeamID == team && v.EndDate != nil && !v.EndDate.Before(t)
+	}), nil
+}
+
+func (m memVacations) During(ctx context.Context, team string, t time.Time) ([]Vacation, error) {
+	return m.find(func(v Vacation) bool {
+		return v.TeamID == team && v.StartDate != nil && v.EndDate != nil &&
+			!v.StartDate.After(t) && !v.EndDate.Before(t)
+	}), nil
+}
+
+func (m memVacations) find(match func(Vacation) bool) []Vacation {
+	m.s.Lock()
+	defer m.s.Unlock()
+
+	vacations := []Vacation{}
+	for _, v := range m.s.data.Vacations {
+		if match(v) {
+			vacations = append(vacations, v)
+		}
+	}
+	return vacations
+}
+
+//---------- Reminders
+
+type memReminders struct{ s *memStore }
+
+func (m memReminders) Create(ctx context.Context, r Reminder) error {
+	return m.s.write(func() error {
+		r.ID = m.s.nextID()
+		r.SnoozedUntil = nil
+		r.Done = false
+		m.s.data.Reminders = append(m.s.data.Reminders, r)
+		return nil
+	})
+}
+
+func (m memReminders) Get(ctx context.Context, id int) (*Reminder, error) {
+	rs := m.find(func(r Reminder) bool { return r.ID == id })
+	if len(rs) < 1 {
+		return nil, nil
+	}
+	return &rs[0], nil
+}
+
+func (m memReminders) Pending(ctx context.Context, team, user string) ([]Reminder, error) {
+	return m.find(func(r Reminder) bool {
+		return r.TeamID == team && r.User == user && !r.Done
+	}), nil
+}
+
+func (m memReminders) Due(ctx context.Context, team string, t time.Time) ([]Reminder, error) {
+	return m.find(func(r Reminder) bool {
+		return r.TeamID == team && !r.Done &&
+			((r.NextRunAt != nil && !r.NextRunAt.After(t)) ||
+				(r.SnoozedUntil != nil && !r.SnoozedUntil.After(t)))
+	}), nil
+}
+
+func (m memReminders) Reschedule(ctx context.Context, id int, next *time.Time) error {
+	return m.update(id, func(r *Reminder) { r.NextRunAt = next })
+}
+
+func (m memReminders) Snooze(ctx context.Context, id int, until *time.Time) error {
+	return m.update(id, func(r *Reminder) { r.SnoozedUntil = until })
+}
+
+func (m memReminders) Cancel(ctx context.Context, id int) error {
+	return m.update(id, func(r *Reminder) {
+		r.Done = true
+		r.SnoozedUntil = nil
+	})
+}
+
+func (m memReminders) update(id int, fn func(r *Reminder)) error {
+	return m.s.write(func() error {
+		for i := range m.s.data.Reminders {
+			if m.s.data.Reminders[i].ID == id {
+				fn(&m.s.data.Reminders[i])
+			}
+		}
+		return nil
+	})
+}
+
+func (m memReminders) find(match func(Reminder) bool) []Reminder {
+	m.s.Lock()
+	defer m.s.Unlock()
+
+	reminders := []Reminder{}
+	for _, r := range m.s.data.Reminders {
+		if match(r) {
+			reminders = append(reminders, r)
+		}
+	}
+	return reminders
+}
+
+//---------- Conversations
+
+type memConversations struct{ s *memStore }
+
+func (m memConversations) Get(ctx context.Context, team string, user string, channel string) (*Conversation, error) {
+	m.s.Lock()
+	defer m.s.Unlock()
+
+	if i := m.find(team, user, channel); i >= 0 {
+		c := m.s.data.Conversations[i]
+		c.Answers = copyAnswers(c.Answers)
+		return &c, nil
+	}
+	return nil, nil
+}
+
+func (m memConversations) Save(ctx context.Context, c *Conversation) error {
+	return m.s.write(func() error {
+		saved := *c
+		saved.Answers = copyAnswers(c.Answers)
+		saved.UpdatedAt = nowPtr()
+
+		if i := m.find(c.TeamID, c.User, c.Channel); i >= 0 {
+			saved.ID = m.s.data.Conversations[i].ID
+			m.s.data.Conversations[i] = saved
+			return nil
+		}
+
+		saved.ID = m.s.nextID()
+		m.s.data.Conversations = append(m.s.data.Conversations, saved)
+		return nil
+	})
+}
+
+func (m memConversations) Delete(ctx context.Context, team string, user string, channel string) error {
+	return m.s.write(func() error {
+		if i := m.find(team, user, channel); i >= 0 {
+			cs := m.s.data.Conversations
+			m.s.data.Conversations = append(cs[:i:i], cs[i+1:]...)
+		}
+		return nil
+	})
+}
+
+//---------- Story cards
+
+type memStoryCards struct{ s *memStore }
+
+func (m memStoryCards) Create(ctx context.Context, c StoryCard) error {
+	return m.s.write(func() error {
+		c.ID = m.s.nextID()
+		c.CreatedAt = nowPtr()
+		m.s.data.StoryCards = append(m.s.data.StoryCards, c)
+		return nil
+	})
+}
+
+func (m memStoryCards) Get(ctx context.Context, team string, channel string, ts string) (*StoryCard, error) {
+	m.s.Lock()
+	defer m.s.Unlock()
+
+	for _, c := range m.s.data.StoryCards {
+		if c.TeamID == team && c.Channel == channel && c.Timestamp == ts {
+			return &c, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m memConversations) find(team string, user string, channel string) int {
+	for i, c := range m.s.data.Conversations {
+		if c.TeamID == team && c.User == user && c.Channel == channel {
+			return i
+		}
+	}
+	return -1
+}
+
+func copyAnswers(answers map[string]string) map[string]string {
+	r := map[string]string{}
+	for k, v := range answers {
+		r[k] = v
+	}
+	return r
+}
+
+//---------- Audit
+
+type memAudit struct{ s *memStore }
+
+func (m memAudit) Create(ctx context.Context, e AuditEvent) error {
+	return m.s.write(func() error {
+		e.ID = m.s.nextID()
+		e.CreatedAt = nowPtr()
+		m.s.data.AuditEvents = append(m.s.data.AuditEvents, e)
+		return nil
+	})
+}
+
+func (m memAudit) Find(ctx context.Context, f AuditFilter) ([]AuditEvent, error) {
+	m.s.Lock()
+	defer m.s.Unlock()
+
+	events := []AuditEvent{}
+	all := m.s.data.AuditEvents
+	for i := len(all) - 1; i >= 0 && len(events) < f.Limit; i-- {
+		e := all[i]
+		if e.TeamID != f.TeamID {
+			continue
+		}
+		if f.User != "" && e.User != f.User {
+			continue
+		}
+		if f.Robot != "" && e.Robot != f.Robot {
+			continue
+		}
+		if f.From != nil && e.CreatedAt.Before(*f.From) {
+			continue
+		}
+		if f.To != nil && !e.CreatedAt.Before(*f.To) {
+			continue
+		}
+
+		events = append(events, e)
+	}
+
+	return events, nil
+}
+
+//---------- Undo
+
+type memUndo struct{ s *memStore }
+
+func (m memUndo) Create(ctx context.Context, u UndoAction) error {
+	return m.s.write(func() error {
+		u.ID = m.s.nextID()
+		u.CreatedAt = nowPtr()
+		m.s.data.UndoActions = append(m.s.data.UndoActions, u)
+		return nil
+	})
+}
+
+func (m memUndo) Pending(ctx context.Context, team, user string, window time.Duration) ([]UndoAction, error) {
+	m.s.Lock()
+	defer m.s.Unlock()
+
+	since := time.Now().Add(-window)
+	actions := []UndoAction{}
+	all := m.s.data.UndoActions
+	for i := len(all) - 1; i >= 0; i-- {
+		u := all[i]
+		if u.TeamID == team && u.User == user && !m.s.data.Undone[u.ID] && !u.CreatedAt.Before(since) {
+			actions = append(actions, u)
+		}
+	}
+
+	return actions, nil
+}
+
+func (m memUndo) MarkUndone(ctx context.Context, id int) error {
+	return m.s.write(func() error {
+		m.s.data.Undone[id] = true
+		return nil
+	})
+}
+
+//---------- Archives
+
+type memArchives struct{ s *memStore }
+
+func (m memArchives) Dump(ctx context.Context) (*Archive, error) {
+	m.s.Lock()
+	defer m.s.Unlock()
+
+	d := m.s.data
+	return &Archive{
+		Projects:      append([]Project{}, d.Projects...),
+		Users:         append([]User{}, d.Users...),
+		Settings:      append([]Setting{}, d.Settings...),
+		Timers:        append([]Timer{}, d.Timers...),
+		TimerSegments: append([]TimerSegment{}, d.TimerSegments...),
+		TimerClaims:   append([]TimerClaim{}, d.TimerClaims...),
+		Vacations:     append([]Vacation{}, d.Vacations...),
+		PokerSessions: append([]PokerSession{}, d.PokerSessions...),
+		PokerStories:  append([]PokerStory{}, d.PokerStories...),
+		PokerVotes:    append([]PokerVote{}, d.PokerVotes...),
+	}, nil
+}
+
+func (m memArchives) Restore(ctx context.Context, a *Archive) error {
+	return m.s.write(func() error {
+		d := &m.s.data
+		for _, p := range a.Projects {
+			i := m.index(len(d.Projects), func(i int) bool { return d.Projects[i].Id == p.Id })
+			if i < 0 {
+				d.Projects = append(d.Projects, p)
+			} else {
+				d.Projects[i] = p
+			}
+			m.seen(p.Id)
+		}
+		for _, u := range a.Users {
+			i := m.index(len(d.Users), func(i int) bool { return *d.Users[i].Id == *u.Id })
+			if i < 0 {
+				d.Users = append(d.Users, u)
+			} else {
+				d.Users[i] = u
+			}
+			m.seen(*u.Id)
+		}
+		for _, s := range a.Settings {
+			i := m.index(len(d.Settings), func(i int) bool { return d.Settings[i].Id == s.Id })
+			if i < 0 {
+				d.Settings = append(d.Settings, s)
+			} else {
+				d.Settings[i] = s
+			}
+			m.seen(s.Id)
+		}
+		for _, t := range a.Timers {
+			i := m.index(len(d.Timers), func(i int) bool { return d.Timers[i].ID == t.ID })
+			if i < 0 {
+				d.Timers = append(d.Timers, t)
+			} else {
+				d.Timers[i] = t
+			}
+			m.seen(t.ID)
+		}
+		for _, seg := range a.TimerSegments {
+			i := m.index(len(d.TimerSegments), func(i int) bool { return d.TimerSegments[i].ID == seg.ID })
+			if i < 0 {
+				d.TimerSegments = append(d.TimerSegments, seg)
+			} else {
+				d.TimerSegments[i] = seg
+			}
+			m.seen(seg.ID)
+		}
+		for _, c := range a.TimerClaims {
+			i := m.index(len(d.TimerClaims), func(i int) bool { return d.TimerClaims[i].ID == c.ID })
+			if i < 0 {
+				d.TimerClaims = append(d.TimerClaims, c)
+			} else {
+				d.TimerClaims[i] = c
+			}
+			m.seen(c.ID)
+		}
+		for _, v := range a.Vacations {
+			i := m.index(len(d.Vacations), func(i int) bool { return d.Vacations[i].ID == v.ID })
+			if i < 0 {
+				d.Vacations = append(d.Vacations, v)
+			} else {
+				d.Vacations[i] = v
+			}
+			m.seen(v.ID)
+		}
+		for _, ps := range a.PokerSessions {
+			i := m.index(len(d.PokerSessions), func(i int) bool { return d.PokerSessions[i].ID == ps.ID })
+			if i < 0 {
+				d.PokerSessions = append(d.PokerSessions, ps)
+			} else {
+				d.PokerSessions[i] = ps
+			}
+			m.seen(ps.ID)
+		}
+		for _, ps := range a.PokerStories {
+			i := m.index(len(d.PokerStories), func(i int) bool { return d.PokerStories[i].ID == ps.ID })
+			if i < 0 {
+				d.PokerStories = append(d.PokerStories, ps)
+			} else {
+				d.PokerStories[i] = ps
+			}
+			m.seen(ps.ID)
+		}
+		for _, v := range a.PokerVotes {
+			i := m.index(len(d.PokerVotes), func(i int) bool { return d.PokerVotes[i].ID == v.ID })
+			if i < 0 {
+				d.PokerVotes = append(d.PokerVotes, v)
+			} else {
+				d.PokerVotes[i] = v
+			}
+			m.seen(v.ID)
+		}
+		return nil
+	})
+}
+
+// index returns the first of n positions matching, or -1
+func (m memArchives) index(n int, match func(i int) bool) int {
+	for i := 0; i < n; i++ {
+		if match(i) {
+			return i
+		}
+	}
+	return -1
+}
+
+// seen moves the id counter past an imported id
+func (m memArchives) seen(id int) {
+	if id > m.s.data.LastID {
+		m.s.data.LastID = id
+	}
+}
@@ -0,0 +1,172 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+)
+
+// Migration is a versioned schema change, with the SQL to apply and
+// to revert it
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+	// Data, if set, runs after Up in the same transaction, for data changes
+	// that depend on the bot's configuration
+	Data func(ctx context.Context, tx *sql.Tx) error
+}
+
+// LatestVersion is the schema version this build expects
+func LatestVersion() int {
+	if len(Migrations) < 1 {
+		return 0
+	}
+	return Migrations[len(Migrations)-1].Version
+}
+
+// SchemaVersion returns the version of the last migration applied
+// to the database. Storage backends other than Postgres have no schema and
+// are always at the latest version
+func SchemaVersion() (int, error) {
+	if !usesPostgres() {
+		return LatestVersion(), nil
+	}
+
+	con, err := handle()
+	if err != nil {
+		return 0, err
+	}
+
+	return schemaVersion(con)
+}
+
+// CheckSchema fails if the database has migrations pending, so the bot
+// doesn't run against a schema it doesn't know
+func CheckSchema() error {
+	version, err := SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	if latest := LatestVersion(); version < latest {
+		return fmt.Errorf(
+			"Database schema is at version %d but version %d is required. Run `slackbot migrate` first.",
+			version, latest)
+	}
+
+	return nil
+}
+
+// Migrate applies every pending migration, each in its own transaction,
+// returning the ones applied
+func Migrate() ([]Migration, error) {
+	if !usesPostgres() {
+		return nil, nil
+	}
+
+	con, err := handle()
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := schemaVersion(con)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := []Migration{}
+	for _, m := range Migrations {
+		if m.Version <= version {
+			continue
+		}
+
+		log.Printf("Applying migration %d - %s", m.Version, m.Name)
+		err := apply(m.Up, m.Data, `
+      INSERT INTO "schema_migrations" ("version", "name")
+      VALUES ($1, $2)`, m.Version, m.Name)
+		if err != nil {
+			return applied, fmt.Errorf("migration %d - %s: %s", m.Version, m.Name, err)
+		}
+
+		applied = append(applied, m)
+	}
+
+	return applied, nil
+}
+
+// Rollback reverts the last steps applied migrations, returning the ones
+// reverted
+func Rollback(steps int) ([]Migration, error) {
+	if !usesPostgres() {
+		return nil, nil
+	}
+
+	con, err := handle()
+	if err != nil {
+		return nil, err
+	}
+
+	version, err := schemaVersion(con)
+	if err != nil {
+		return nil, err
+	}
+
+	reverted := []Migration{}
+	for i := len(Migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+		m := Migrations[i]
+		if m.Version > version {
+			continue
+		}
+
+		log.Printf("Reverting migration %d - %s", m.Version, m.Name)
+		err := apply(m.Down, nil, `
+      DELETE FROM "schema_migrations" WHERE "version" = $1`, m.Version)
+		if err != nil {
+			return reverted, fmt.Errorf("migration %d - %s: %s", m.Version, m.Name, err)
+		}
+
+		reverted = append(reverted, m)
+	}
+
+	return reverted, nil
+}
+
+func schemaVersion(con *sql.DB) (int, error) {
+	_, err := con.Exec(`
+    CREATE TABLE IF NOT EXISTS "schema_migrations" (
+      "version" int NOT NULL,
+      "name" varchar(255) NOT NULL,
+      "applied_at" timestamp default CURRENT_TIMESTAMP,
+      CONSTRAINT schema_migrations_pkey PRIMARY KEY (version)
+    )`)
+	if err != nil {
+		return 0, err
+	}
+
+	var version int
+	err = con.QueryRow(`
+    SELECT COALESCE(MAX("version"), 0) FROM "schema_migrations"`).Scan(&version)
+	return version, err
+}
+
+// apply runs the schema change, the data change if any, and the
+// bookkeeping query atomically
+func apply(change string, data func(context.Context, *sql.Tx) error, query string, args ...interface{}) error {
+	ctx := context.Background()
+	return withTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, change); err != nil {
+			return err
+		}
+		if data != nil {
+			if err := data(ctx, tx); err != nil {
+				return err
+			}
+		}
+
+		_, err := tx.ExecContext(ctx, query, args...)
+		return err
+	})
+}
@@ -0,0 +1,448 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"os"
+)
+
+// Migrations is the ordered list of schema changes. Never change a migration
+// that was already released, add a new one instead.
+//
+// The first ones create the tables that used to live in create.sql and
+// are written to be applied on top of databases created with it.
+var Migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create_settings",
+		Up: `
+    CREATE TABLE IF NOT EXISTS "settings" (
+      "id" bigserial NOT NULL,
+      "user" varchar(255) NOT NULL,
+      "name" varchar(255) NOT NULL,
+      "value" text NOT NULL,
+      "created_at" timestamp default CURRENT_TIMESTAMP,
+      CONSTRAINT settings_pkey PRIMARY KEY (id)
+    ) WITH (OIDS=FALSE);`,
+		Down: `
+    DROP TABLE IF EXISTS "settings";`,
+	},
+	{
+		Version: 2,
+		Name:    "create_projects",
+		Up: `
+    CREATE TABLE IF NOT EXISTS "projects" (
+      "id" bigserial NOT NULL,
+      "name" varchar(255) NOT NULL,
+      "pivotal_id" int NOT NULL,
+      "mavenlink_id" int NOT NULL,
+      "created_by" varchar(255) NOT NULL,
+      "mvn_sprint_story_id" varchar(255),
+      "channel" varchar(255),
+      "created_at" timestamp default CURRENT_TIMESTAMP,
+      CONSTRAINT projects_pkey PRIMARY KEY (id),
+      CONSTRAINT projects_name UNIQUE ("name")
+    ) WITH (OIDS=FALSE);`,
+		Down: `
+    DROP TABLE IF EXISTS "projects";`,
+	},
+	{
+		Version: 3,
+		Name:    "create_users",
+		Up: `
+    CREATE TABLE IF NOT EXISTS "users" (
+      "id" bigserial NOT NULL,
+      "name" varchar(255) NOT NULL,
+      "pivotal_id" int NULL,
+      "mavenlink_id" int NULL,
+      "created_at" timestamp default CURRENT_TIMESTAMP,
+      CONSTRAINT users_pkey PRIMARY KEY (id),
+      CONSTRAINT users_name UNIQUE ("name")
+    ) WITH (OIDS=FALSE);`,
+		Down: `
+    DROP TABLE IF EXISTS "users";`,
+	},
+	{
+		Version: 4,
+		Name:    "create_activities",
+		Up: `
+    CREATE TABLE IF NOT EXISTS "activities" (
+      "id" bigserial NOT NULL,
+      "user" varchar(255) NOT NULL,
+      "channel" varchar(255) NOT NULL,
+      "task" varchar(255) NOT NULL,
+      "token" varchar(255) NOT NULL,
+      "created_at" timestamp default NULL,
+      CONSTRAINT activities_pkey PRIMARY KEY (id)
+    ) WITH (OIDS=FALSE);`,
+		Down: `
+    DROP TABLE IF EXISTS "activities";`,
+	},
+	{
+		Version: 5,
+		Name:    "create_poker_sessions",
+		Up: `
+    CREATE TABLE IF NOT EXISTS "poker_sessions" (
+      "id" bigserial NOT NULL,
+      "channel" varchar(255) NOT NULL,
+      "title" varchar(255) NOT NULL,
+      "users" varchar(255) NOT NULL,
+      "finished_at" timestamp default NULL,
+      "created_at" timestamp default CURRENT_TIMESTAMP,
+      CONSTRAINT poker_sessions_pkey PRIMARY KEY (id)
+    ) WITH (OIDS=FALSE);`,
+		Down: `
+    DROP TABLE IF EXISTS "poker_sessions";`,
+	},
+	{
+		Version: 6,
+		Name:    "create_poker_stories",
+		Up: `
+    CREATE TABLE IF NOT EXISTS "poker_stories" (
+      "id" bigserial NOT NULL,
+      "poker_session_id" varchar(255) NOT NULL,
+      "title" varchar(255) NOT NULL,
+      "estimation" numeric NULL,
+      "created_at" timestamp default CURRENT_TIMESTAMP,
+      CONSTRAINT poker_stories_pkey PRIMARY KEY (id)
+    ) WITH (OIDS=FALSE);`,
+		Down: `
+    DROP TABLE IF EXISTS "poker_stories";`,
+	},
+	{
+		Version: 7,
+		Name:    "create_poker_votes",
+		Up: `
+    CREATE TABLE IF NOT EXISTS "poker_votes" (
+      "id" bigserial NOT NULL,
+      "poker_story_id" varchar(255) NOT NULL,
+      "user" varchar(255) NOT NULL,
+      "vote" numeric NOT NULL,
+      "created_at" timestamp default CURRENT_TIMESTAMP,
+      CONSTRAINT poker_votes_pkey PRIMARY KEY (id),
+      CONSTRAINT poker_votes_story_user UNIQUE ("poker_story_id", "user")
+    ) WITH (OIDS=FALSE);`,
+		Down: `
+    DROP TABLE IF EXISTS "poker_votes";`,
+	},
+	{
+		Version: 8,
+		Name:    "create_timers",
+		Up: `
+    CREATE TABLE IF NOT EXISTS "timers" (
+      "id" bigserial NOT NULL,
+      "user" varchar(255) NOT NULL,
+      "name" varchar(255) NOT NULL,
+      "finished_at" timestamp default NULL,
+      "created_at" timestamp default CURRENT_TIMESTAMP,
+      CONSTRAINT timers_pkey PRIMARY KEY (id)
+    ) WITH (OIDS=FALSE);`,
+		Down: `
+    DROP TABLE IF EXISTS "timers";`,
+	},
+	{
+		Version: 9,
+		Name:    "create_vacations",
+		Up: `
+    CREATE TABLE IF NOT EXISTS "vacations" (
+      "id" serial NOT NULL,
+      "user" varchar(255),
+      "start_date" timestamp with time zone,
+      "end_date" timestamp with time zone,
+      "description" varchar(255),
+      CONSTRAINT vacations_pkey PRIMARY KEY (id)
+    ) WITH (OIDS=FALSE);`,
+		Down: `
+    DROP TABLE IF EXISTS "vacations";`,
+	},
+	{
+		Version: 10,
+		Name:    "create_reminders",
+		Up: `
+    CREATE TABLE IF NOT EXISTS "reminders" (
+      "id" bigserial NOT NULL,
+      "user" varchar(255) NOT NULL,
+      "target" varchar(255) NOT NULL,
+      "message" text NOT NULL,
+      "recurrence" varchar(255) NULL,
+      "timezone" varchar(255) NOT NULL,
+      "next_run_at" timestamp default NULL,
+      "snoozed_until" timestamp default NULL,
+      "done" boolean NOT NULL default FALSE,
+      "created_at" timestamp default CURRENT_TIMESTAMP,
+      CONSTRAINT reminders_pkey PRIMARY KEY (id)
+    ) WITH (OIDS=FALSE);`,
+		Down: `
+    DROP TABLE IF EXISTS "reminders";`,
+	},
+	{
+		Version: 11,
+		Name:    "create_conversations",
+		Up: `
+    CREATE TABLE IF NOT EXISTS "conversations" (
+      "id" bigserial NOT NULL,
+      "user" varchar(255) NOT NULL,
+      "channel" varchar(255) NOT NULL,
+      "dialog" varchar(255) NOT NULL,
+      "step" int NOT NULL default 0,
+      "answers" text NOT NULL,
+      "updated_at" timestamp default CURRENT_TIMESTAMP,
+      "created_at" timestamp default CURRENT_TIMESTAMP,
+      CONSTRAINT conversations_pkey PRIMARY KEY (id),
+      CONSTRAINT conversations_user_channel UNIQUE ("user", "channel")
+    ) WITH (OIDS=FALSE);`,
+		Down: `
+    DROP TABLE IF EXISTS "conversations";`,
+	},
+	{
+		Version: 12,
+		Name:    "create_audit_events",
+		Up: `
+    CREATE TABLE IF NOT EXISTS "audit_events" (
+      "id" bigserial NOT NULL,
+      "user" varchar(255) NOT NULL,
+      "channel" varchar(255) NOT NULL default '',
+      "kind" varchar(255) NOT NULL,
+      "robot" varchar(255) NOT NULL,
+      "action" varchar(255) NOT NULL,
+      "target" varchar(255) NOT NULL default '',
+      "details" text NOT NULL default '',
+      "created_at" timestamp default CURRENT_TIMESTAMP,
+      CONSTRAINT audit_events_pkey PRIMARY KEY (id)
+    ) WITH (OIDS=FALSE);
+    CREATE INDEX IF NOT EXISTS audit_events_user ON "audit_events" ("user", "created_at");
+    CREATE INDEX IF NOT EXISTS audit_events_robot ON "audit_events" ("robot", "created_at");`,
+		Down: `
+    DROP TABLE IF EXISTS "audit_events";`,
+	},
+	{
+		Version: 13,
+		Name:    "create_undo_actions",
+		Up: `
+    CREATE TABLE IF NOT EXISTS "undo_actions" (
+      "id" bigserial NOT NULL,
+      "user" varchar(255) NOT NULL,
+      "system" varchar(255) NOT NULL,
+      "action" varchar(255) NOT NULL,
+      "target" varchar(255) NOT NULL,
+      "payload" text NOT NULL,
+      "description" text NOT NULL default '',
+      "undone" boolean NOT NULL default FALSE,
+      "created_at" timestamp default CURRENT_TIMESTAMP,
+      CONSTRAINT undo_actions_pkey PRIMARY KEY (id)
+    ) WITH (OIDS=FALSE);
+    CREATE INDEX IF NOT EXISTS undo_actions_user ON "undo_actions" ("user", "created_at");`,
+		Down: `
+    DROP TABLE IF EXISTS "undo_actions";`,
+	},
+	{
+		Version: 14,
+		Name:    "add_settings_scope",
+		Up: `
+    ALTER TABLE "settings"
+      ADD COLUMN IF NOT EXISTS "scope" varchar(16) NOT NULL default 'user';
+    CREATE INDEX IF NOT EXISTS settings_owner ON "settings" ("scope", "user", "name");`,
+		Down: `
+    DROP INDEX IF EXISTS settings_owner;
+    ALTER TABLE "settings" DROP COLUMN IF EXISTS "scope";`,
+	},
+	{
+		Version: 15,
+		Name:    "add_team_id",
+		Up: `
+    ALTER TABLE "projects"
+      ADD COLUMN IF NOT EXISTS "team_id" varchar(255) NOT NULL default 'default';
+    ALTER TABLE "users"
+      ADD COLUMN IF NOT EXISTS "team_id" varchar(255) NOT NULL default 'default';
+    ALTER TABLE "timers"
+      ADD COLUMN IF NOT EXISTS "team_id" varchar(255) NOT NULL default 'default';
+    ALTER TABLE "poker_sessions"
+      ADD COLUMN IF NOT EXISTS "team_id" varchar(255) NOT NULL default 'default';
+    ALTER TABLE "vacations"
+      ADD COLUMN IF NOT EXISTS "team_id" varchar(255) NOT NULL default 'default';
+    ALTER TABLE "projects" DROP CONSTRAINT IF EXISTS projects_name;
+    ALTER TABLE "users" DROP CONSTRAINT IF EXISTS users_name;
+    CREATE UNIQUE INDEX IF NOT EXISTS projects_team_name ON "projects" ("team_id", "name");
+    CREATE UNIQUE INDEX IF NOT EXISTS users_team_name ON "users" ("team_id", "name");
+    CREATE INDEX IF NOT EXISTS timers_team_user ON "timers" ("team_id", "user");
+    CREATE INDEX IF NOT EXISTS poker_sessions_team_channel ON "poker_sessions" ("team_id", "channel");`,
+		Down: `
+    DROP INDEX IF EXISTS poker_sessions_team_channel;
+    DROP INDEX IF EXISTS timers_team_user;
+    DROP INDEX IF EXISTS users_team_name;
+    DROP INDEX IF EXISTS projects_team_name;
+    ALTER TABLE "users" ADD CONSTRAINT users_name UNIQUE ("name");
+    ALTER TABLE "projects" ADD CONSTRAINT projects_name UNIQUE ("name");
+    ALTER TABLE "vacations" DROP COLUMN IF EXISTS "team_id";
+    ALTER TABLE "poker_sessions" DROP COLUMN IF EXISTS "team_id";
+    ALTER TABLE "timers" DROP COLUMN IF EXISTS "team_id";
+    ALTER TABLE "users" DROP COLUMN IF EXISTS "team_id";
+    ALTER TABLE "projects" DROP COLUMN IF EXISTS "team_id";`,
+	},
+	{
+		Version: 16,
+		Name:    "create_timer_segments",
+		Up: `
+    ALTER TABLE "timers"
+      ADD COLUMN IF NOT EXISTS "paused_at" timestamp default NULL,
+      ADD COLUMN IF NOT EXISTS "pause_reason" varchar(16) NOT NULL default '';
+    CREATE TABLE IF NOT EXISTS "timer_segments" (
+      "id" bigserial NOT NULL,
+      "timer_id" bigint NOT NULL REFERENCES "timers" ("id") ON DELETE CASCADE,
+      "started_at" timestamp NOT NULL,
+      "ended_at" timestamp default NULL,
+      CONSTRAINT timer_segments_pkey PRIMARY KEY (id)
+    ) WITH (OIDS=FALSE);
+    CREATE INDEX IF NOT EXISTS timer_segments_timer ON "timer_segments" ("timer_id");
+    INSERT INTO "timer_segments" ("timer_id", "started_at", "ended_at")
+      SELECT "id", COALESCE("created_at", CURRENT_TIMESTAMP), "finished_at"
+      FROM "timers";`,
+		Down: `
+    DROP TABLE IF EXISTS "timer_segments";
+    ALTER TABLE "timers" DROP COLUMN IF EXISTS "pause_reason";
+    ALTER TABLE "timers" DROP COLUMN IF EXISTS "paused_at";`,
+	},
+	{
+		Version: 17,
+		Name:    "add_timer_adjustment_and_notes",
+		Up: `
+    ALTER TABLE "timers"
+      ADD COLUMN IF NOT EXISTS "adjustment" bigint NOT NULL default 0,
+      ADD COLUMN IF NOT EXISTS "notes" text NOT NULL default '';`,
+		Down: `
+    ALTER TABLE "timers" DROP COLUMN IF EXISTS "notes";
+    ALTER TABLE "timers" DROP COLUMN IF EXISTS "adjustment";`,
+	},
+	{
+		Version: 18,
+		Name:    "create_timer_claims",
+		Up: `
+    CREATE INDEX IF NOT EXISTS timers_team_created ON "timers" ("team_id", "created_at");
+    CREATE TABLE IF NOT EXISTS "timer_claims" (
+      "id" bigserial NOT NULL,
+      "timer_id" bigint NOT NULL REFERENCES "timers" ("id") ON DELETE CASCADE,
+      "pivotal_story" varchar(32) NOT NULL default '',
+      "mavenlink_story" varchar(32) NOT NULL default '',
+      "time_entry_id" varchar(32) NOT NULL default '',
+      "minutes" integer NOT NULL default 0,
+      "created_at" timestamp NOT NULL default CURRENT_TIMESTAMP,
+      CONSTRAINT timer_claims_pkey PRIMARY KEY (id)
+    ) WITH (OIDS=FALSE);
+    CREATE INDEX IF NOT EXISTS timer_claims_timer ON "timer_claims" ("timer_id");`,
+		Down: `
+    DROP TABLE IF EXISTS "timer_claims";
+    DROP INDEX IF EXISTS timers_team_created;`,
+	},
+	{
+		Version: 19,
+		Name:    "add_timer_nudged_at",
+		Up: `
+    ALTER TABLE "timers"
+      ADD COLUMN IF NOT EXISTS "nudged_at" timestamp default NULL;`,
+		Down: `
+    ALTER TABLE "timers" DROP COLUMN IF EXISTS "nudged_at";`,
+	},
+	{
+		Version: 20,
+		Name:    "add_timer_stories",
+		Up: `
+    ALTER TABLE "timers"
+      ADD COLUMN IF NOT EXISTS "pivotal_story" varchar(32) NOT NULL default '',
+      ADD COLUMN IF NOT EXISTS "mavenlink_story" varchar(32) NOT NULL default '';
+    CREATE INDEX IF NOT EXISTS timers_pivotal_story ON "timers" ("team_id", "user", "pivotal_story");`,
+		Down: `
+    DROP INDEX IF EXISTS timers_pivotal_story;
+    ALTER TABLE "timers" DROP COLUMN IF EXISTS "mavenlink_story";
+    ALTER TABLE "timers" DROP COLUMN IF EXISTS "pivotal_story";`,
+	},
+	{
+		Version: 21,
+		Name:    "create_story_cards",
+		Up: `
+    CREATE TABLE IF NOT EXISTS "story_cards" (
+      "id" bigserial NOT NULL,
+      "team_id" varchar(255) NOT NULL default '',
+      "channel" varchar(32) NOT NULL,
+      "ts" varchar(32) NOT NULL,
+      "pivotal_story" varchar(32) NOT NULL default '',
+      "mavenlink_story" varchar(32) NOT NULL default '',
+      "created_at" timestamp NOT NULL default CURRENT_TIMESTAMP,
+      CONSTRAINT story_cards_pkey PRIMARY KEY (id)
+    ) WITH (OIDS=FALSE);
+    CREATE UNIQUE INDEX IF NOT EXISTS story_cards_message ON "story_cards" ("channel", "ts");`,
+		Down: `
+    DROP TABLE IF EXISTS "story_cards";`,
+	},
+	{
+		Version: 22,
+		Name:    "unique_settings_owner",
+		Up: `
+    DELETE FROM "settings" s USING "settings" newer
+    WHERE s."scope" = newer."scope" AND s."user" = newer."user"
+      AND s."name" = newer."name" AND s."id" < newer."id";
+    DROP INDEX IF EXISTS settings_owner;
+    CREATE UNIQUE INDEX settings_owner ON "settings" ("scope", "user", "name");`,
+		Down: `
+    DROP INDEX IF EXISTS settings_owner;
+    CREATE INDEX settings_owner ON "settings" ("scope", "user", "name");`,
+	},
+	{
+		Version: 23,
+		Name:    "add_team_id_to_settings_and_history",
+		Up: `
+    ALTER TABLE "settings"
+      ADD COLUMN IF NOT EXISTS "team_id" varchar(255) NOT NULL default 'default';
+    ALTER TABLE "reminders"
+      ADD COLUMN IF NOT EXISTS "team_id" varchar(255) NOT NULL default 'default';
+    ALTER TABLE "conversations"
+      ADD COLUMN IF NOT EXISTS "team_id" varchar(255) NOT NULL default 'default';
+    ALTER TABLE "undo_actions"
+      ADD COLUMN IF NOT EXISTS "team_id" varchar(255) NOT NULL default 'default';
+    ALTER TABLE "audit_events"
+      ADD COLUMN IF NOT EXISTS "team_id" varchar(255) NOT NULL default 'default';
+    DROP INDEX IF EXISTS settings_owner;
+    CREATE UNIQUE INDEX settings_owner ON "settings" ("team_id", "scope", "user", "name");
+    CREATE INDEX IF NOT EXISTS reminders_team_user ON "reminders" ("team_id", "user");
+    CREATE INDEX IF NOT EXISTS undo_actions_team_user ON "undo_actions" ("team_id", "user", "created_at");
+    CREATE INDEX IF NOT EXISTS audit_events_team ON "audit_events" ("team_id", "created_at");
+    ALTER TABLE "conversations" DROP CONSTRAINT IF EXISTS conversations_user_channel;
+    ALTER TABLE "conversations"
+      ADD CONSTRAINT conversations_user_channel UNIQUE ("team_id", "user", "channel");
+    DROP INDEX IF EXISTS story_cards_message;
+    CREATE UNIQUE INDEX story_cards_message ON "story_cards" ("team_id", "channel", "ts");`,
+		Data: teamSettings,
+		Down: `
+    DROP INDEX IF EXISTS story_cards_message;
+    CREATE UNIQUE INDEX story_cards_message ON "story_cards" ("channel", "ts");
+    ALTER TABLE "conversations" DROP CONSTRAINT IF EXISTS conversations_user_channel;
+    ALTER TABLE "conversations"
+      ADD CONSTRAINT conversations_user_channel UNIQUE ("user", "channel");
+    DROP INDEX IF EXISTS audit_events_team;
+    DROP INDEX IF EXISTS undo_actions_team_user;
+    DROP INDEX IF EXISTS reminders_team_user;
+    DROP INDEX IF EXISTS settings_owner;
+    CREATE INDEX settings_owner ON "settings" ("scope", "user", "name");
+    ALTER TABLE "audit_events" DROP COLUMN IF EXISTS "team_id";
+    ALTER TABLE "undo_actions" DROP COLUMN IF EXISTS "team_id";
+    ALTER TABLE "conversations" DROP COLUMN IF EXISTS "team_id";
+    ALTER TABLE "reminders" DROP COLUMN IF EXISTS "team_id";
+    ALTER TABLE "settings" DROP COLUMN IF EXISTS "team_id";`,
+	},
+}
+
+// teamSettings moves team settings to the team they're owned by. They were
+// owned by the Slack team id, which for the team whose rows predate teams
+// is DEFAULT_TEAM_ID, known as DefaultTeam
+func teamSettings(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+    UPDATE "settings"
+    SET "team_id" = CASE WHEN "user" IN ($1, '') THEN $2 ELSE "user" END
+    WHERE "scope" = 'team'`, os.Getenv("DEFAULT_TEAM_ID"), DefaultTeam)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+    UPDATE "settings" SET "user" = "team_id" WHERE "scope" = 'team'`)
+	return err
+}
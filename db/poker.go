@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
@@ -10,17 +11,18 @@ import (
 // PokerSession is session of poker planning
 type PokerSession struct {
 	ID         int
+	TeamID     string
 	Channel    string
 	Title      string
 	Users      string
 	FinishedAt *time.Time
-	Stories    []PokerStory
+	Stories    []PokerStory `json:"-"`
 }
 
 // PokerStory is a story within a PokerSession
 type PokerStory struct {
 	ID         int
-	Session    PokerSession
+	Session    PokerSession `json:"-"`
 	SessionID  int
 	Title      string
 	Estimation *float32
@@ -29,7 +31,7 @@ type PokerStory struct {
 // PokerVote is a user's vote for a story
 type PokerVote struct {
 	ID      int
-	Story   PokerStory
+	Story   PokerStory `json:"-"`
 	StoryID int
 	User    string
 	Vote    float32
@@ -39,32 +41,92 @@ type PokerVote struct {
 
 // CastVote tracks a vote for a given user
 func (s *PokerStory) CastVote(user, vote string) error {
-	con, err := connect()
+	return Poker.CastVote(context.Background(), s.ID, user, vote)
+}
+
+// GetVotes returns all votes for a given PokerStory
+func (s *PokerStory) GetVotes() ([]PokerVote, error) {
+	return Poker.Votes(context.Background(), s.ID)
+}
+
+// ------------------- Story
+
+// UpdateEstimation sets the estimation of a given PokerStory
+func (s *PokerStory) UpdateEstimation(estimation string) error {
+	return Poker.UpdateEstimation(context.Background(), s.ID, estimation)
+}
+
+// GetCurrentStory returns the most recent story with no estimation
+// (or assumed to be in the process of estimation)
+func (s *PokerSession) GetCurrentStory() (*PokerStory, error) {
+	return Poker.CurrentStory(context.Background(), s.ID)
+}
+
+// StartPokerStory creates a new PokerStory. This story will
+// then be considered the "current" story of a poker planning
+// session
+func (s *PokerSession) StartPokerStory(title string) error {
+	return Poker.StartStory(context.Background(), s.ID, title)
+}
+
+// ------------------- Session
+
+// GetStories returns all stories for a given PokerSession
+// in order of creation date
+func (s *PokerSession) GetStories() ([]PokerStory, error) {
+	return Poker.Stories(context.Background(), s.ID)
+}
+
+// GetEstimatedStories returns all the stories for a given PokerSession
+// that has not been estimated yet
+func (s *PokerSession) GetEstimatedStories() ([]PokerStory, error) {
+	return Poker.EstimatedStories(context.Background(), s.ID)
+}
+
+// Finish ends a PokerSession
+func (s *PokerSession) Finish() error {
+	return Poker.FinishSession(context.Background(), s.ID)
+}
+
+// StartPokerSession starts a poker session for a given channel
+func StartPokerSession(team, channel, title, users string) error {
+	return Poker.StartSession(context.Background(), TeamKey(team), channel, title, users)
+}
+
+// GetCurrentSession returns the current session for the given channel
+// or nil if no current session for the channel
+func GetCurrentSession(team, channel string) (*PokerSession, error) {
+	return Poker.CurrentSession(context.Background(), TeamKey(team), channel)
+}
+
+//---------- Postgres
+
+type pgPoker struct{}
+
+func (pgPoker) CastVote(ctx context.Context, storyID int, user, vote string) error {
+	con, err := handle()
 	if err != nil {
 		return err
 	}
-	defer con.Close()
 
-	_, err = con.Query(`
+	_, err = con.ExecContext(ctx, `
     INSERT INTO poker_votes
     ("poker_story_id", "user", "vote")
-    VALUES ($1, $2, $3)`, s.ID, user, vote)
+    VALUES ($1, $2, $3)`, storyID, user, vote)
 	return err
 }
 
-// GetVotes returns all votes for a given PokerStory
-func (s *PokerStory) GetVotes() ([]PokerVote, error) {
-	con, err := connect()
+func (pgPoker) Votes(ctx context.Context, storyID int) ([]PokerVote, error) {
+	con, err := handle()
 	if err != nil {
 		return nil, err
 	}
-	defer con.Close()
 
-	rows, err := con.Query(`
+	rows, err := con.QueryContext(ctx, `
     SELECT
       "id", "poker_story_id", "user", "vote"
     FROM poker_votes
-    WHERE poker_story_id = $1`, s.ID)
+    WHERE poker_story_id = $1`, storyID)
 	if err != nil {
 		return nil, err
 	}
@@ -80,145 +142,74 @@ func (s *PokerStory) GetVotes() ([]PokerVote, error) {
 		votes = append(votes, *v)
 	}
 
-	return votes, nil
-}
-
-func setPokerVote(rows *sql.Rows) (*PokerVote, error) {
-	vote := PokerVote{}
-	err := rows.Scan(&vote.ID, &vote.StoryID, &vote.User, &vote.Vote)
-	if err != nil {
-		return nil, err
-	}
-
-	return &vote, nil
+	return votes, rows.Err()
 }
 
-// ------------------- Story
-
-// UpdateEstimation sets the estimation of a given PokerStory
-func (s *PokerStory) UpdateEstimation(estimation string) error {
-	con, err := connect()
+func (pgPoker) UpdateEstimation(ctx context.Context, storyID int, estimation string) error {
+	con, err := handle()
 	if err != nil {
 		return err
 	}
-	defer con.Close()
 
-	_, err = con.Query(`
+	_, err = con.ExecContext(ctx, `
     UPDATE poker_stories
     SET "estimation" = $1
-    WHERE "id" = $2`, estimation, s.ID)
+    WHERE "id" = $2`, estimation, storyID)
 	return err
 }
 
-// GetCurrentStory returns the most recent story with no estimation
-// (or assumed to be in the process of estimation)
-func (s *PokerSession) GetCurrentStory() (*PokerStory, error) {
-	con, err := connect()
+func (pgPoker) CurrentStory(ctx context.Context, sessionID int) (*PokerStory, error) {
+	con, err := handle()
 	if err != nil {
 		return nil, err
 	}
-	defer con.Close()
 
-	rows, err := con.Query(`
+	return setPokerStory(con.QueryRowContext(ctx, `
     SELECT
       "id", "poker_session_id", "title", "estimation"
     FROM "poker_stories"
     WHERE "poker_session_id" = $1
-          AND "estimation" IS NULL`, s.ID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	if !rows.Next() {
-		return nil, nil
-	}
-
-	ps, err := setPokerStory(rows)
-	if err != nil {
-		return nil, err
-	}
-
-	return ps, nil
+          AND "estimation" IS NULL`, sessionID))
 }
 
-// StartPokerStory creates a new PokerStory. This story will
-// then be considered the "current" story of a poker planning
-// session
-func (s *PokerSession) StartPokerStory(title string) error {
-	con, err := connect()
+func (pgPoker) StartStory(ctx context.Context, sessionID int, title string) error {
+	con, err := handle()
 	if err != nil {
 		return err
 	}
-	defer con.Close()
 
-	_, err = con.Query(`
+	_, err = con.ExecContext(ctx, `
     INSERT INTO poker_stories (poker_session_id, title)
-    VALUES ($1, $2)`, s.ID, title)
+    VALUES ($1, $2)`, sessionID, title)
 	return err
 }
 
-func setPokerStory(rows *sql.Rows) (*PokerStory, error) {
-	ps := PokerStory{}
-	err := rows.Scan(&ps.ID, &ps.SessionID, &ps.Title, &ps.Estimation)
-	if err != nil {
-		return nil, err
-	}
-
-	return &ps, nil
+func (p pgPoker) Stories(ctx context.Context, sessionID int) ([]PokerStory, error) {
+	return p.stories(ctx, `
+    SELECT
+      "id", "poker_session_id", "title", "estimation"
+    FROM "poker_stories"
+    WHERE "poker_session_id" = $1
+    ORDER BY "created_at", "id"`, sessionID)
 }
 
-// ------------------- Session
-
-// GetStories returns all stories for a given PokerSession
-// in order of creation date
-func (s *PokerSession) GetStories() ([]PokerStory, error) {
-	con, err := connect()
-	if err != nil {
-		return nil, err
-	}
-	defer con.Close()
-
-	rows, err := con.Query(`
+func (p pgPoker) EstimatedStories(ctx context.Context, sessionID int) ([]PokerStory, error) {
+	return p.stories(ctx, `
     SELECT
       "id", "poker_session_id", "title", "estimation"
     FROM "poker_stories"
     WHERE "poker_session_id" = $1
-    ORDER BY created_at`, s.ID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	stories := []PokerStory{}
-	for rows.Next() {
-		ps, err := setPokerStory(rows)
-		if err != nil {
-			return nil, err
-		}
-
-		stories = append(stories, *ps)
-	}
-
-	return stories, nil
+          AND "estimation" IS NOT NULL
+    ORDER BY "created_at", "id"`, sessionID)
 }
 
-// GetEstimatedStories returns all the stories for a given PokerSession
-// that has not been estimated yet
-func (s *PokerSession) GetEstimatedStories() ([]PokerStory, error) {
-	con, err := connect()
+func (pgPoker) stories(ctx context.Context, query string, args ...interface{}) ([]PokerStory, error) {
+	con, err := handle()
 	if err != nil {
 		return nil, err
 	}
-	defer con.Close()
 
-	rows, err := con.Query(`
-    SELECT
-      "id", "poker_session_id", "title", "estimation"
-    FROM "poker_stories"
-    WHERE "poker_session_id" = $1
-          AND "estimation" IS NOT NULL
-    ORDER BY created_at`, s.ID)
+	rows, err := con.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -234,87 +225,89 @@ func (s *PokerSession) GetEstimatedStories() ([]PokerStory, error) {
 		stories = append(stories, *ps)
 	}
 
-	return stories, nil
+	return stories, rows.Err()
 }
 
-// Finish ends a PokerSession
-func (s *PokerSession) Finish() error {
-	con, err := connect()
+func (pgPoker) FinishSession(ctx context.Context, sessionID int) error {
+	con, err := handle()
 	if err != nil {
 		return err
 	}
-	defer con.Close()
 
-	_, err = con.Query(`
+	_, err = con.ExecContext(ctx, `
     UPDATE poker_sessions
     SET "finished_at" = CURRENT_TIMESTAMP
-    WHERE "id" = $1`, s.ID)
+    WHERE "id" = $1`, sessionID)
 	return err
 }
 
-// StartPokerSession starts a poker session for a given channel
-func StartPokerSession(channel, title, users string) error {
-	con, err := connect()
+func (pgPoker) StartSession(ctx context.Context, team, channel, title, users string) error {
+	con, err := handle()
 	if err != nil {
 		return err
 	}
-	defer con.Close()
 
-	_, err = con.Query(`
-    INSERT INTO poker_sessions (channel, title, users)
-    VALUES ($1, $2, $3)`,
-		channel, title, users)
+	_, err = con.ExecContext(ctx, `
+    INSERT INTO poker_sessions (team_id, channel, title, users)
+    VALUES ($1, $2, $3, $4)`,
+		team, channel, title, users)
 	return err
 }
 
-// GetCurrentSession returns the current session for the given channel
-// or nil if no current session for the channel
-func GetCurrentSession(channel string) (*PokerSession, error) {
-	con, err := connect()
+func (pgPoker) CurrentSession(ctx context.Context, team, channel string) (*PokerSession, error) {
+	con, err := handle()
 	if err != nil {
 		return nil, err
 	}
-	defer con.Close()
 
-	rows, err := con.Query(`
+	return setPokerSession(con.QueryRowContext(ctx, `
     SELECT
-      "id", "channel", "title", "users", "finished_at"
+      "id", "team_id", "channel", "title", "users", "finished_at"
     FROM "poker_sessions"
     WHERE "finished_at" IS NULL
-          AND "channel" = $1`, channel)
+          AND "team_id" = $1 AND "channel" = $2`, team, channel))
+}
+
+func setPokerVote(row scanner) (*PokerVote, error) {
+	vote := PokerVote{}
+	err := row.Scan(&vote.ID, &vote.StoryID, &vote.User, &vote.Vote)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	if !rows.Next() {
+	return &vote, nil
+}
+
+// setPokerStory scans a story, returning nil if a single row query had
+// no results
+func setPokerStory(row scanner) (*PokerStory, error) {
+	ps := PokerStory{}
+	err := row.Scan(&ps.ID, &ps.SessionID, &ps.Title, &ps.Estimation)
+	if err == sql.ErrNoRows {
 		return nil, nil
 	}
-
-	ps, err := setPokerSession(rows)
 	if err != nil {
 		return nil, err
 	}
 
-	return ps, nil
+	return &ps, nil
 }
 
-func setPokerSession(rows *sql.Rows) (*PokerSession, error) {
+// setPokerSession scans a session, returning nil if a single row query had
+// no results
+func setPokerSession(row scanner) (*PokerSession, error) {
 	ps := PokerSession{}
 	var finishedAt pq.NullTime
-	err := rows.Scan(&ps.ID, &ps.Channel, &ps.Title, &ps.Users, &finishedAt)
-	if err != nil {
-		return nil, err
+	err := row.Scan(&ps.ID, &ps.TeamID, &ps.Channel, &ps.Title, &ps.Users,
+		&finishedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
 	}
-
-	val, err := finishedAt.Value()
 	if err != nil {
 		return nil, err
 	}
 
-	if val != nil {
-		ps.FinishedAt = &finishedAt.Time
-	}
+	ps.FinishedAt = nullTime(finishedAt)
 
 	return &ps, nil
 }
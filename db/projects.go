@@ -1,18 +1,25 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"strconv"
+
+	"github.com/gistia/slackbot/dryrun"
 )
 
 type Project struct {
 	Id               int
+	TeamID           string
 	Name             string
 	Channel          string
 	PivotalId        int64
 	MavenlinkId      int64
 	MvnSprintStoryId string
 	CreatedBy        string
+	// DryRun, when active, records the project's writes instead of making
+	// them
+	DryRun *dryrun.Recorder `json:"-"`
 }
 
 func (p Project) StrPivotalId() string {
@@ -20,34 +27,83 @@ func (p Project) StrPivotalId() string {
 }
 
 func CreateProject(p Project) error {
-	con, err := connect()
+	p.TeamID = TeamKey(p.TeamID)
+	return p.DryRun.Do("db", "create_project", p.Name, p, func() error {
+		return Projects.Create(context.Background(), p)
+	})
+}
+
+func GetProjects(team string) ([]Project, error) {
+	return Projects.All(context.Background(), TeamKey(team))
+}
+
+func GetProjectByName(team string, name string) (*Project, error) {
+	return GetProjectBy(team, "name", name)
+}
+
+func GetProjectByChannel(team string, channel string) (*Project, error) {
+	return GetProjectBy(team, "channel", channel)
+}
+
+func GetProjectBy(team string, field string, s string) (*Project, error) {
+	return Projects.GetBy(context.Background(), TeamKey(team), field, s)
+}
+
+func GetProject(team string, id int64) (*Project, error) {
+	return Projects.Get(context.Background(), TeamKey(team), id)
+}
+
+func UpdateProject(p Project) error {
+	return p.DryRun.Do("db", "update_project", p.Name, p, func() error {
+		return Projects.Update(context.Background(), p)
+	})
+}
+
+//---------- Postgres
+
+const projectColumns = `
+      "id", "team_id", "name", "pivotal_id", "mavenlink_id", "created_by",
+      "mvn_sprint_story_id", "channel"`
+
+type pgProjects struct{}
+
+func (pgProjects) Create(ctx context.Context, p Project) error {
+	con, err := handle()
 	if err != nil {
 		return err
 	}
-	defer con.Close()
 
-	_, err = con.Query(`
+	_, err = con.ExecContext(ctx, `
     INSERT INTO projects
-    (name, pivotal_id, mavenlink_id, created_by,
+    (team_id, name, pivotal_id, mavenlink_id, created_by,
      mvn_sprint_story_id, channel)
-    VALUES ($1, $2, $3, $4, $5, $6)`,
-		p.Name, p.PivotalId, p.MavenlinkId, p.CreatedBy,
+    VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		p.TeamID, p.Name, p.PivotalId, p.MavenlinkId, p.CreatedBy,
 		p.MvnSprintStoryId, p.Channel)
 	return err
 }
 
-func GetProjects() ([]Project, error) {
-	con, err := connect()
+func (p pgProjects) All(ctx context.Context, team string) ([]Project, error) {
+	return p.find(ctx, `
+    SELECT`+projectColumns+`
+    FROM projects
+    WHERE "team_id" = $1`, team)
+}
+
+func (p pgProjects) Every(ctx context.Context) ([]Project, error) {
+	return p.find(ctx, `
+    SELECT`+projectColumns+`
+    FROM projects
+    ORDER BY "id"`)
+}
+
+func (pgProjects) find(ctx context.Context, query string, args ...interface{}) ([]Project, error) {
+	con, err := handle()
 	if err != nil {
 		return nil, err
 	}
-	defer con.Close()
 
-	rows, err := con.Query(`
-    SELECT
-      "id", "name", "pivotal_id", "mavenlink_id", "created_by",
-      "mvn_sprint_story_id", "channel"
-    FROM projects`)
+	rows, err := con.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -63,75 +119,40 @@ func GetProjects() ([]Project, error) {
 		ps = append(ps, *pr)
 	}
 
-	return ps, nil
-}
-
-func GetProjectByName(name string) (*Project, error) {
-	return GetProjectBy("name", name)
+	return ps, rows.Err()
 }
 
-func GetProjectByChannel(channel string) (*Project, error) {
-	return GetProjectBy("channel", channel)
-}
-
-func GetProjectBy(field string, s string) (*Project, error) {
-	con, err := connect()
+func (pgProjects) GetBy(ctx context.Context, team string, field string, s string) (*Project, error) {
+	con, err := handle()
 	if err != nil {
 		return nil, err
 	}
-	defer con.Close()
 
-	rows, err := con.Query(`
-    SELECT
-      "id", "name", "pivotal_id", "mavenlink_id", "created_by",
-      "mvn_sprint_story_id", "channel"
+	return setProject(con.QueryRowContext(ctx, `
+    SELECT`+projectColumns+`
     FROM projects
-    WHERE "`+field+`" = $1`, s)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	if !rows.Next() {
-		return nil, nil
-	}
-
-	return setProject(rows)
+    WHERE "team_id" = $1 AND "`+field+`" = $2`, team, s))
 }
 
-func GetProject(id int64) (*Project, error) {
-	con, err := connect()
+func (pgProjects) Get(ctx context.Context, team string, id int64) (*Project, error) {
+	con, err := handle()
 	if err != nil {
 		return nil, err
 	}
-	defer con.Close()
 
-	rows, err := con.Query(`
-    SELECT
-      "id", "name", "pivotal_id", "mavenlink_id", "created_by",
-      "mvn_sprint_story_id", "channel"
+	return setProject(con.QueryRowContext(ctx, `
+    SELECT`+projectColumns+`
     FROM projects
-    WHERE "id" = $1`, id)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	if !rows.Next() {
-		return nil, nil
-	}
-
-	return setProject(rows)
+    WHERE "team_id" = $1 AND "id" = $2`, team, id))
 }
 
-func UpdateProject(p Project) error {
-	con, err := connect()
+func (pgProjects) Update(ctx context.Context, p Project) error {
+	con, err := handle()
 	if err != nil {
 		return err
 	}
-	defer con.Close()
 
-	_, err = con.Query(`
+	_, err = con.ExecContext(ctx, `
     UPDATE
       projects
     SET
@@ -149,13 +170,18 @@ func UpdateProject(p Project) error {
 	return err
 }
 
-func setProject(rows *sql.Rows) (*Project, error) {
+// setProject scans a project, returning nil if a single row
+// query had no results
+func setProject(row scanner) (*Project, error) {
 	p := Project{}
 	var mvnSprintStoryId sql.NullString
 	var channel sql.NullString
-	err := rows.Scan(
-		&p.Id, &p.Name, &p.PivotalId, &p.MavenlinkId, &p.CreatedBy,
+	err := row.Scan(
+		&p.Id, &p.TeamID, &p.Name, &p.PivotalId, &p.MavenlinkId, &p.CreatedBy,
 		&mvnSprintStoryId, &channel)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,225 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Reminder is a message to be delivered to a user or channel at a given
+// time, optionally repeating
+type Reminder struct {
+	ID           int
+	TeamID       string
+	User         string
+	Target       string
+	Message      string
+	Recurrence   string
+	Timezone     string
+	NextRunAt    *time.Time
+	SnoozedUntil *time.Time
+	Done         bool
+}
+
+// IsRecurring returns true if the reminder repeats
+func (r *Reminder) IsRecurring() bool {
+	return r.Recurrence != ""
+}
+
+// IsChannel returns true if the reminder targets a channel
+// instead of a user
+func (r *Reminder) IsChannel() bool {
+	return len(r.Target) > 0 && r.Target[0] == '#'
+}
+
+// Location returns the timezone the reminder was scheduled in
+func (r *Reminder) Location() *time.Location {
+	loc, err := time.LoadLocation(r.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// CreateReminder creates a new active reminder
+func CreateReminder(r Reminder) error {
+	r.TeamID = TeamKey(r.TeamID)
+	return Reminders.Create(context.Background(), r)
+}
+
+// GetReminder returns the reminder with the given id
+func GetReminder(id int) (*Reminder, error) {
+	return Reminders.Get(context.Background(), id)
+}
+
+// GetReminders returns all pending reminders created by user
+func GetReminders(team, user string) ([]Reminder, error) {
+	return Reminders.Pending(context.Background(), TeamKey(team), user)
+}
+
+// GetDueReminders returns all reminders of team that should be delivered
+// by now, either because they are scheduled or because a snooze has expired
+func GetDueReminders(team string) ([]Reminder, error) {
+	return Reminders.Due(context.Background(), TeamKey(team), time.Now().UTC())
+}
+
+// Reschedule sets the next time the reminder will run. A nil time means
+// the reminder won't run again unless snoozed
+func (r *Reminder) Reschedule(next *time.Time) error {
+	err := Reminders.Reschedule(context.Background(), r.ID, next)
+	if err == nil {
+		r.NextRunAt = next
+	}
+	return err
+}
+
+// Snooze delivers the reminder again at the given time
+func (r *Reminder) Snooze(until *time.Time) error {
+	err := Reminders.Snooze(context.Background(), r.ID, until)
+	if err == nil {
+		r.SnoozedUntil = until
+	}
+	return err
+}
+
+// Complete marks a reminder as done. Recurring reminders only have
+// their pending snooze cleared and keep running
+func (r *Reminder) Complete() error {
+	if r.IsRecurring() {
+		return r.Snooze(nil)
+	}
+
+	return r.Cancel()
+}
+
+// Cancel stops a reminder from ever running again
+func (r *Reminder) Cancel() error {
+	err := Reminders.Cancel(context.Background(), r.ID)
+	if err == nil {
+		r.Done = true
+		r.SnoozedUntil = nil
+	}
+	return err
+}
+
+//---------- Postgres
+
+const reminderColumns = `
+      "id", "team_id", "user", "target", "message", "recurrence", "timezone",
+      "next_run_at", "snoozed_until", "done"`
+
+type pgReminders struct{}
+
+func (pgReminders) Create(ctx context.Context, r Reminder) error {
+	con, err := handle()
+	if err != nil {
+		return err
+	}
+
+	_, err = con.ExecContext(ctx, `
+    INSERT INTO reminders
+    ("team_id", "user", "target", "message", "recurrence", "timezone", "next_run_at")
+    VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		r.TeamID, r.User, r.Target, r.Message, r.Recurrence, r.Timezone, r.NextRunAt)
+	return err
+}
+
+func (pgReminders) Get(ctx context.Context, id int) (*Reminder, error) {
+	con, err := handle()
+	if err != nil {
+		return nil, err
+	}
+
+	return setReminder(con.QueryRowContext(ctx, `
+    SELECT`+reminderColumns+`
+    FROM "reminders"
+    WHERE "id" = $1`, id))
+}
+
+func (p pgReminders) Pending(ctx context.Context, team, user string) ([]Reminder, error) {
+	return p.find(ctx, `
+    SELECT`+reminderColumns+`
+    FROM "reminders"
+    WHERE "team_id" = $1 AND "user" = $2 AND "done" = FALSE
+    ORDER BY "id"`, team, user)
+}
+
+func (p pgReminders) Due(ctx context.Context, team string, t time.Time) ([]Reminder, error) {
+	return p.find(ctx, `
+    SELECT`+reminderColumns+`
+    FROM "reminders"
+    WHERE "team_id" = $1 AND "done" = FALSE
+          AND ("next_run_at" <= $2 OR "snoozed_until" <= $2)`, team, t)
+}
+
+func (pgReminders) Reschedule(ctx context.Context, id int, next *time.Time) error {
+	return pgExec(ctx, `
+    UPDATE "reminders"
+    SET "next_run_at" = $1
+    WHERE "id" = $2`, next, id)
+}
+
+func (pgReminders) Snooze(ctx context.Context, id int, until *time.Time) error {
+	return pgExec(ctx, `
+    UPDATE "reminders"
+    SET "snoozed_until" = $1
+    WHERE "id" = $2`, until, id)
+}
+
+func (pgReminders) Cancel(ctx context.Context, id int) error {
+	return pgExec(ctx, `
+    UPDATE "reminders"
+    SET "done" = TRUE, "snoozed_until" = NULL
+    WHERE "id" = $1`, id)
+}
+
+func (pgReminders) find(ctx context.Context, query string, args ...interface{}) ([]Reminder, error) {
+	con, err := handle()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := con.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reminders := []Reminder{}
+	for rows.Next() {
+		r, err := setReminder(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		reminders = append(reminders, *r)
+	}
+
+	return reminders, rows.Err()
+}
+
+// setReminder scans a reminder, returning nil if a single row query had
+// no results
+func setReminder(row scanner) (*Reminder, error) {
+	var recurrence sql.NullString
+	var nextRunAt pq.NullTime
+	var snoozedUntil pq.NullTime
+
+	r := Reminder{}
+	err := row.Scan(&r.ID, &r.TeamID, &r.User, &r.Target, &r.Message, &recurrence,
+		&r.Timezone, &nextRunAt, &snoozedUntil, &r.Done)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r.Recurrence = recurrence.String
+	r.NextRunAt = nullTime(nextRunAt)
+	r.SnoozedUntil = nullTime(snoozedUntil)
+
+	return &r, nil
+}
@@ -0,0 +1,174 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// ProjectRepository stores the projects linking Pivotal and Mavenlink
+type ProjectRepository interface {
+	Create(ctx context.Context, p Project) error
+	All(ctx context.Context, team string) ([]Project, error)
+	// Every returns the projects of all teams
+	Every(ctx context.Context) ([]Project, error)
+	Get(ctx context.Context, team string, id int64) (*Project, error)
+	// GetBy finds a project of team by the name or channel field
+	GetBy(ctx context.Context, team string, field string, value string) (*Project, error)
+	Update(ctx context.Context, p Project) error
+}
+
+// UserRepository stores the Pivotal and Mavenlink ids of Slack users
+type UserRepository interface {
+	All(ctx context.Context, team string) ([]User, error)
+	// Every returns the users of all teams
+	Every(ctx context.Context) ([]User, error)
+	// GetBy finds a user of team by the name field
+	GetBy(ctx context.Context, team string, field string, value string) (*User, error)
+	Create(ctx context.Context, u User) error
+	Update(ctx context.Context, u User) error
+	// Save creates or updates the user with the same team and name
+	Save(ctx context.Context, u User) error
+}
+
+// SettingRepository stores settings, like API tokens, of the owners within
+// each scope. Values are stored as given, encryption happens in the
+// package level functions
+type SettingRepository interface {
+	Get(ctx context.Context, team string, scope string, owner string, name string) (*Setting, error)
+	All(ctx context.Context, team string, scope string, owner string) ([]Setting, error)
+	// Every returns the settings of all owners in all scopes of all teams
+	Every(ctx context.Context) ([]Setting, error)
+	Set(ctx context.Context, team string, scope string, owner string, name string, value string) error
+	// Remove deletes the setting, returning false if it didn't exist
+	Remove(ctx context.Context, team string, scope string, owner string, name string) (bool, error)
+}
+
+// TimerRepository stores the task timers of users
+type TimerRepository interface {
+	// Create adds the timer, running since its CreatedAt
+	Create(ctx context.Context, t Timer) error
+	Get(ctx context.Context, id int) (*Timer, error)
+	// GetByName returns the unfinished timer with the name, otherwise the
+	// newest one
+	GetByName(ctx context.Context, team string, user string, name string) (*Timer, error)
+	// GetStartedByName returns the newest unfinished timer with the name
+	GetStartedByName(ctx context.Context, team string, user string, name string) (*Timer, error)
+	// GetStartedByStory returns the newest unfinished timer started for the
+	// Pivotal story
+	GetStartedByStory(ctx context.Context, team string, user string, pivotalStory string) (*Timer, error)
+	Running(ctx context.Context, team string, user string) ([]Timer, error)
+	// AllRunning returns the running timers of every team
+	AllRunning(ctx context.Context) ([]Timer, error)
+	// Between returns the timers that ran at some point between from and
+	// to, of every user of the team if user is empty
+	Between(ctx context.Context, team string, user string, from time.Time, to time.Time) ([]Timer, error)
+	// Every returns the timers of all teams
+	Every(ctx context.Context) ([]Timer, error)
+	// Stop finishes the timer with the id of t, closing its open segment
+	Stop(ctx context.Context, t Timer, at time.Time) error
+	// Segments returns the stretches of time the timer ran, oldest first
+	Segments(ctx context.Context, timerID int) ([]TimerSegment, error)
+	Pause(ctx context.Context, timerID int, at time.Time, reason string) error
+	Resume(ctx context.Context, timerID int, at time.Time) error
+	AddSegment(ctx context.Context, timerID int, from time.Time, to time.Time) error
+	// Adjust adds d, which may be negative, to the time the timer ran
+	Adjust(ctx context.Context, timerID int, d time.Duration) error
+	AddNote(ctx context.Context, timerID int, note string) error
+	// Claims returns the time entries created from the timer, oldest first
+	Claims(ctx context.Context, timerID int) ([]TimerClaim, error)
+	AddClaim(ctx context.Context, c TimerClaim) error
+	Nudge(ctx context.Context, timerID int, at time.Time) error
+	// Delete removes the timer along with its segments
+	Delete(ctx context.Context, timerID int) error
+}
+
+// PokerRepository stores planning poker sessions, stories and votes
+type PokerRepository interface {
+	StartSession(ctx context.Context, team string, channel string, title string, users string) error
+	CurrentSession(ctx context.Context, team string, channel string) (*PokerSession, error)
+	FinishSession(ctx context.Context, sessionID int) error
+	StartStory(ctx context.Context, sessionID int, title string) error
+	CurrentStory(ctx context.Context, sessionID int) (*PokerStory, error)
+	Stories(ctx context.Context, sessionID int) ([]PokerStory, error)
+	EstimatedStories(ctx context.Context, sessionID int) ([]PokerStory, error)
+	UpdateEstimation(ctx context.Context, storyID int, estimation string) error
+	CastVote(ctx context.Context, storyID int, user string, vote string) error
+	Votes(ctx context.Context, storyID int) ([]PokerVote, error)
+}
+
+// VacationRepository stores the vacations of users
+type VacationRepository interface {
+	Create(ctx context.Context, v *Vacation) error
+	// EndingAfter returns the vacations of team that didn't end by t
+	EndingAfter(ctx context.Context, team string, t time.Time) ([]Vacation, error)
+	// During returns the vacations of team that include t
+	During(ctx context.Context, team string, t time.Time) ([]Vacation, error)
+}
+
+// ReminderRepository stores one-off and recurring reminders
+type ReminderRepository interface {
+	Create(ctx context.Context, r Reminder) error
+	Get(ctx context.Context, id int) (*Reminder, error)
+	// Pending returns the reminders of user that aren't done
+	Pending(ctx context.Context, team string, user string) ([]Reminder, error)
+	// Due returns the reminders of team scheduled or snoozed until t or
+	// earlier
+	Due(ctx context.Context, team string, t time.Time) ([]Reminder, error)
+	Reschedule(ctx context.Context, id int, next *time.Time) error
+	Snooze(ctx context.Context, id int, until *time.Time) error
+	Cancel(ctx context.Context, id int) error
+}
+
+// ConversationRepository stores the ongoing dialogs, one per user
+// and channel
+type ConversationRepository interface {
+	Get(ctx context.Context, team string, user string, channel string) (*Conversation, error)
+	Save(ctx context.Context, c *Conversation) error
+	Delete(ctx context.Context, team string, user string, channel string) error
+}
+
+// StoryCardRepository stores the messages posted about stories
+type StoryCardRepository interface {
+	Create(ctx context.Context, c StoryCard) error
+	Get(ctx context.Context, team string, channel string, ts string) (*StoryCard, error)
+}
+
+// AuditRepository stores the audit trail of commands and changes
+type AuditRepository interface {
+	Create(ctx context.Context, e AuditEvent) error
+	// Find returns the most recent events matching the filter
+	Find(ctx context.Context, f AuditFilter) ([]AuditEvent, error)
+}
+
+// UndoRepository stores how to revert the changes made by the bot
+type UndoRepository interface {
+	Create(ctx context.Context, u UndoAction) error
+	// Pending returns the actions of user not yet undone that were created
+	// within window, newest first
+	Pending(ctx context.Context, team string, user string, window time.Duration) ([]UndoAction, error)
+	MarkUndone(ctx context.Context, id int) error
+}
+
+// ArchiveRepository copies the bot data in and out in bulk
+type ArchiveRepository interface {
+	Dump(ctx context.Context) (*Archive, error)
+	// Restore writes every row of the archive, replacing the rows with
+	// the same ids
+	Restore(ctx context.Context, a *Archive) error
+}
+
+// The repositories used by the package level functions, set by OpenStorage
+var (
+	Projects      ProjectRepository      = pgProjects{}
+	Users         UserRepository         = pgUsers{}
+	Settings      SettingRepository      = pgSettings{}
+	Timers        TimerRepository        = pgTimers{}
+	Poker         PokerRepository        = pgPoker{}
+	Vacations     VacationRepository     = pgVacations{}
+	Reminders     ReminderRepository     = pgReminders{}
+	Conversations ConversationRepository = pgConversations{}
+	StoryCards    StoryCardRepository    = pgStoryCards{}
+	Audit         AuditRepository        = pgAudit{}
+	Undo          UndoRepository         = pgUndo{}
+	Archives      ArchiveRepository      = pgArchives{}
+)
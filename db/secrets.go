@@ -0,0 +1,284 @@
+package db
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+)
+
+// SecretSettings are the setting names always stored encrypted. More can
+// be added with a comma separated list in SECRET_SETTINGS
+var SecretSettings = []string{"PIVOTAL_TOKEN", "MAVENLINK_TOKEN", "GITHUB_TOKEN"}
+
+// encryptedPrefix marks a value sealed by encryptSetting, followed by the
+// id of the key that wraps its data key
+const encryptedPrefix = "enc:v2:"
+
+// legacyPrefix marks the values sealed before they were bound to the team,
+// scope and owner of their setting, which only authenticate its name.
+// RotateSettingsKey seals them again
+const legacyPrefix = "enc:v1:"
+
+// errNoKeys is returned when a secret is stored without any master key
+var errNoKeys = errors.New("No settings keys configured, set SETTINGS_KEYS or SETTINGS_KEYFILE")
+
+// keyring holds the master keys, indexed by id. Values are always
+// encrypted with the current one, the others are kept to read values
+// written before a rotation
+type keyring struct {
+	keys    map[string][]byte
+	current string
+}
+
+var (
+	ring     *keyring
+	ringOnce sync.Once
+	ringErr  error
+)
+
+// IsSecretSetting returns true if the setting is stored encrypted
+func IsSecretSetting(name string) bool {
+	names := append([]string{}, SecretSettings...)
+	names = append(names, strings.Split(os.Getenv("SECRET_SETTINGS"), ",")...)
+	for _, n := range names {
+		if strings.EqualFold(strings.TrimSpace(n), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateSettingsKey returns a new random master key in the format read
+// from SETTINGS_KEYS and SETTINGS_KEYFILE
+func GenerateSettingsKey(id string) (string, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", err
+	}
+	return id + ":" + base64.StdEncoding.EncodeToString(key), nil
+}
+
+// loadKeyring reads the master keys from the file at SETTINGS_KEYFILE or,
+// if unset, from SETTINGS_KEYS. Keys are written as id:base64, one per line
+// or separated by commas, and the last one is the current key. A nil
+// keyring means no keys are configured
+func loadKeyring() (*keyring, error) {
+	ringOnce.Do(func() {
+		src := os.Getenv("SETTINGS_KEYS")
+		if path := os.Getenv("SETTINGS_KEYFILE"); path != "" {
+			b, err := ioutil.ReadFile(path)
+			if err != nil {
+				ringErr = err
+				return
+			}
+			src = string(b)
+		}
+
+		ring, ringErr = parseKeyring(src)
+	})
+
+	return ring, ringErr
+}
+
+func parseKeyring(src string) (*keyring, error) {
+	kr := &keyring{keys: map[string][]byte{}}
+	entries := strings.FieldsFunc(src, func(r rune) bool {
+		return r == '\n' || r == ','
+	})
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if e == "" || strings.HasPrefix(e, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(e, ":", 2)
+		if len(parts) < 2 || parts[0] == "" {
+			return nil, fmt.Errorf("Malformed settings key %q, use id:base64", e)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("Malformed settings key %s: %s", parts[0], err)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("Settings key %s must have 32 bytes", parts[0])
+		}
+
+		kr.keys[parts[0]] = key
+		kr.current = parts[0]
+	}
+
+	if kr.current == "" {
+		return nil, nil
+	}
+	return kr, nil
+}
+
+// encryptSetting seals the value of s with a random data key, which is
+// itself wrapped by the current master key. Secrets are never stored
+// without keys
+func encryptSetting(s Setting) (string, error) {
+	kr, err := loadKeyring()
+	if err != nil {
+		return "", err
+	}
+	if kr == nil {
+		return "", fmt.Errorf("Can't store %s: %s", s.Name, errNoKeys)
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return "", err
+	}
+
+	wrapped, err := seal(kr.keys[kr.current], dataKey, kr.current)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := seal(dataKey, []byte(s.Value), settingAD(s))
+	if err != nil {
+		return "", err
+	}
+
+	return encryptedPrefix + kr.current + ":" + wrapped + ":" + sealed, nil
+}
+
+// decryptSetting returns the plain value of s, which is its value itself
+// if it isn't encrypted. Values copied from another setting don't decrypt
+func decryptSetting(s Setting) (string, error) {
+	if !isEncrypted(s.Value) {
+		return s.Value, nil
+	}
+
+	dataKey, sealed, err := unwrap(s.Value)
+	if err != nil {
+		return "", fmt.Errorf("Can't decrypt %s: %s", s.Name, err)
+	}
+
+	ad := settingAD(s)
+	if strings.HasPrefix(s.Value, legacyPrefix) {
+		ad = s.Name
+	}
+	plain, err := unseal(dataKey, sealed, ad)
+	if err != nil {
+		return "", fmt.Errorf("Can't decrypt %s: %s", s.Name, err)
+	}
+
+	return string(plain), nil
+}
+
+// settingAD returns the additional data a secret is sealed with, binding
+// it to the team, scope, owner and name of its setting
+func settingAD(s Setting) string {
+	return strings.Join([]string{s.TeamID, s.Scope, s.User, s.Name}, "\x00")
+}
+
+// rewrapSetting wraps the data key of an encrypted value with the current
+// master key, leaving the value itself untouched
+func rewrapSetting(value string) (string, error) {
+	dataKey, sealed, err := unwrap(value)
+	if err != nil {
+		return "", err
+	}
+
+	kr, err := loadKeyring()
+	if err != nil {
+		return "", err
+	}
+
+	wrapped, err := seal(kr.keys[kr.current], dataKey, kr.current)
+	if err != nil {
+		return "", err
+	}
+
+	return encryptedPrefix + kr.current + ":" + wrapped + ":" + sealed, nil
+}
+
+func isEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix) || strings.HasPrefix(value, legacyPrefix)
+}
+
+// sealedPart returns an encrypted value without its prefix
+func sealedPart(value string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(value, encryptedPrefix), legacyPrefix)
+}
+
+// keyID returns the id of the master key used for an encrypted value
+func keyID(value string) string {
+	return strings.SplitN(sealedPart(value), ":", 2)[0]
+}
+
+// unwrap decrypts the data key of an encrypted value
+func unwrap(value string) ([]byte, string, error) {
+	parts := strings.Split(sealedPart(value), ":")
+	if len(parts) != 3 {
+		return nil, "", errors.New("malformed encrypted value")
+	}
+
+	kr, err := loadKeyring()
+	if err != nil {
+		return nil, "", err
+	}
+	if kr == nil || kr.keys[parts[0]] == nil {
+		return nil, "", fmt.Errorf("key %s isn't configured", parts[0])
+	}
+
+	dataKey, err := unseal(kr.keys[parts[0]], parts[1], parts[0])
+	if err != nil {
+		return nil, "", err
+	}
+
+	return dataKey, parts[2], nil
+}
+
+// seal encrypts data with AES-GCM, returning the nonce and the
+// ciphertext base64 encoded. The additional data must match on unseal
+func seal(key, data []byte, ad string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	out := gcm.Seal(nonce, nonce, data, []byte(ad))
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+func unseal(key []byte, s string, ad string) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < gcm.NonceSize() {
+		return nil, errors.New("malformed encrypted value")
+	}
+
+	n := gcm.NonceSize()
+	return gcm.Open(nil, b[:n], b[n:], []byte(ad))
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -1,75 +1,280 @@
 package db
 
-import "fmt"
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
 
+// The scopes a setting can be stored in. When resolving a setting the user
+// scope overrides the channel scope, which overrides the team scope
+const (
+	ScopeUser    = "user"
+	ScopeChannel = "channel"
+	ScopeTeam    = "team"
+)
+
+// Setting is a named value. User holds the owner of the setting within its
+// scope and team: a user name, a channel name or, for the team scope, the
+// team itself
 type Setting struct {
-	Id    int
-	User  string
-	Name  string
-	Value string
+	Id     int
+	TeamID string
+	Scope  string
+	User   string
+	Name   string
+	Value  string
+}
+
+// SettingContext is where a setting is being looked up from
+type SettingContext struct {
+	Team    string
+	Channel string
+	User    string
+}
+
+// owners returns the scopes and owners to look a setting up in, from
+// the most specific to the least
+func (c SettingContext) owners() [][2]string {
+	owners := [][2]string{}
+	if c.User != "" {
+		owners = append(owners, [2]string{ScopeUser, c.User})
+	}
+	if c.Channel != "" {
+		owners = append(owners, [2]string{ScopeChannel, c.Channel})
+	}
+	return append(owners, [2]string{ScopeTeam, ""})
+}
+
+// settingOwner returns the team and owner a setting is stored under. Team
+// settings are owned by the team itself
+func settingOwner(team, scope, owner string) (string, string) {
+	team = TeamKey(team)
+	if scope == ScopeTeam {
+		return team, team
+	}
+	return team, owner
+}
+
+// ValidScope returns true if scope is one of the setting scopes
+func ValidScope(scope string) bool {
+	return scope == ScopeUser || scope == ScopeChannel || scope == ScopeTeam
+}
+
+func RemoveSetting(team string, user string, name string) (bool, error) {
+	return RemoveScopedSetting(team, ScopeUser, user, name)
+}
+
+// SetSetting stores the setting, encrypting it if it's secret
+func SetSetting(team string, user string, name string, value string) error {
+	return SetScopedSetting(team, ScopeUser, user, name, value)
+}
+
+// GetSettings returns all settings of user, with secrets decrypted
+func GetSettings(team string, user string) ([]Setting, error) {
+	return GetScopedSettings(team, ScopeUser, user)
+}
+
+// GetSetting returns the setting with its value decrypted, or nil if the
+// user doesn't have it
+func GetSetting(team string, user string, name string) (*Setting, error) {
+	return GetScopedSetting(team, ScopeUser, user, name)
+}
+
+// RemoveScopedSetting deletes the setting of owner within scope, returning
+// false if it didn't exist. The owner of team settings is the team
+func RemoveScopedSetting(team, scope, owner, name string) (bool, error) {
+	team, owner = settingOwner(team, scope, owner)
+	return Settings.Remove(context.Background(), team, scope, owner, name)
 }
 
-func RemoveSetting(user string, name string) (bool, error) {
-	con, err := connect()
+// SetScopedSetting stores the setting of owner within scope, encrypting it
+// if it's secret
+func SetScopedSetting(team, scope, owner, name, value string) error {
+	team, owner = settingOwner(team, scope, owner)
+	if IsSecretSetting(name) {
+		var err error
+		s := Setting{TeamID: team, Scope: scope, User: owner, Name: name, Value: value}
+		if value, err = encryptSetting(s); err != nil {
+			return err
+		}
+	}
+
+	return Settings.Set(context.Background(), team, scope, owner, name, value)
+}
+
+// GetScopedSettings returns all settings of owner within scope, with
+// secrets decrypted
+func GetScopedSettings(team, scope, owner string) ([]Setting, error) {
+	team, owner = settingOwner(team, scope, owner)
+	settings, err := Settings.All(context.Background(), team, scope, owner)
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+
+	for i, s := range settings {
+		if settings[i].Value, err = decryptSetting(s); err != nil {
+			return nil, err
+		}
 	}
-	defer con.Close()
 
-	fmt.Println("User", user, name)
-	s, err := GetSetting(user, name)
+	return settings, nil
+}
+
+// GetScopedSetting returns the setting of owner within scope with its value
+// decrypted, or nil if there is none
+func GetScopedSetting(team, scope, owner, name string) (*Setting, error) {
+	team, owner = settingOwner(team, scope, owner)
+	s, err := Settings.Get(context.Background(), team, scope, owner, name)
+	if err != nil || s == nil {
+		return s, err
+	}
+
+	s.Value, err = decryptSetting(*s)
 	if err != nil {
-		fmt.Println("Error", err.Error())
-		return false, err
+		return nil, err
 	}
 
-	if s == nil {
-		fmt.Println("Setting nil")
-		return false, nil
+	return s, nil
+}
+
+// ResolveSetting returns the setting that applies in c, looking it up in
+// the user, channel and team scopes in that order. It returns nil if it
+// isn't set in any of them
+func ResolveSetting(c SettingContext, name string) (*Setting, error) {
+	for _, o := range c.owners() {
+		s, err := GetScopedSetting(c.Team, o[0], o[1], name)
+		if err != nil || s != nil {
+			return s, err
+		}
 	}
 
-	_, err = con.Query(`
-    DELETE FROM settings
-    WHERE "user" = $1 AND "name" = $2`, user, name)
-	return true, err
+	return nil, nil
+}
+
+// ExplainSetting returns every value the setting has in c, the one that
+// applies first, followed by the ones it overrides
+func ExplainSetting(c SettingContext, name string) ([]Setting, error) {
+	settings := []Setting{}
+	for _, o := range c.owners() {
+		s, err := GetScopedSetting(c.Team, o[0], o[1], name)
+		if err != nil {
+			return nil, err
+		}
+		if s != nil {
+			settings = append(settings, *s)
+		}
+	}
+
+	return settings, nil
 }
 
-func SetSetting(user string, name string, value string) error {
-	con, err := connect()
+// RotateSettingsKey moves every secret setting to the current master key,
+// encrypting the ones still in plain text and sealing again the ones that
+// aren't bound to their owner. Other encrypted values only have their data
+// key wrapped again. It returns how many were changed
+func RotateSettingsKey() (int, error) {
+	kr, err := loadKeyring()
 	if err != nil {
-		return err
+		return 0, err
+	}
+	if kr == nil {
+		return 0, errNoKeys
 	}
-	defer con.Close()
 
-	s, err := GetSetting(user, name)
+	ctx := context.Background()
+	settings, err := Settings.Every(ctx)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	if s == nil {
-		_, err := con.Query(`
-      INSERT INTO settings ("user", "name", "value")
-      VALUES ($1, $2, $3)`, user, name, value)
-		return err
+	n := 0
+	for _, s := range settings {
+		var value string
+		switch {
+		case strings.HasPrefix(s.Value, legacyPrefix):
+			if s.Value, err = decryptSetting(s); err == nil {
+				value, err = encryptSetting(s)
+			}
+		case isEncrypted(s.Value) && keyID(s.Value) == kr.current:
+			continue
+		case isEncrypted(s.Value):
+			value, err = rewrapSetting(s.Value)
+		case IsSecretSetting(s.Name):
+			value, err = encryptSetting(s)
+		default:
+			continue
+		}
+		if err != nil {
+			return n, fmt.Errorf("%s of %s %s: %s", s.Name, s.Scope, s.User, err)
+		}
+
+		if err := Settings.Set(ctx, s.TeamID, s.Scope, s.User, s.Name, value); err != nil {
+			return n, err
+		}
+		n++
 	}
 
-	_, err = con.Query(`
-    UPDATE settings SET "value" = $1
-    WHERE "user" = $2 AND "name" = $3`, value, user, name)
-	return err
+	return n, nil
 }
 
-func GetSettings(user string) ([]Setting, error) {
-	con, err := connect()
+//---------- Postgres
+
+const settingColumns = `"id", "team_id", "scope", "user", "name", "value"`
+
+type pgSettings struct{}
+
+func (pgSettings) Remove(ctx context.Context, team, scope, owner, name string) (bool, error) {
+	con, err := handle()
 	if err != nil {
-		return nil, err
+		return false, err
+	}
+
+	res, err := con.ExecContext(ctx, `
+    DELETE FROM settings
+    WHERE "team_id" = $1 AND "scope" = $2 AND "user" = $3 AND "name" = $4`,
+		team, scope, owner, name)
+	if err != nil {
+		return false, err
 	}
-	defer con.Close()
 
-	rows, err := con.Query(`
-    SELECT "id", "user", "name", "value"
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Set creates or updates the setting. The unique settings_owner index
+// makes the insert and the update a single statement, so concurrent sets
+// can't create duplicates
+func (pgSettings) Set(ctx context.Context, team, scope, owner, name, value string) error {
+	return pgExec(ctx, `
+    INSERT INTO settings ("team_id", "scope", "user", "name", "value")
+    VALUES ($1, $2, $3, $4, $5)
+    ON CONFLICT ("team_id", "scope", "user", "name") DO UPDATE SET "value" = EXCLUDED."value"`,
+		team, scope, owner, name, value)
+}
+
+func (p pgSettings) All(ctx context.Context, team, scope, owner string) ([]Setting, error) {
+	return p.find(ctx, `
+    SELECT `+settingColumns+`
     FROM settings
-    WHERE "user" = $1`, user)
+    WHERE "team_id" = $1 AND "scope" = $2 AND "user" = $3`, team, scope, owner)
+}
+
+func (p pgSettings) Every(ctx context.Context) ([]Setting, error) {
+	return p.find(ctx, `
+    SELECT `+settingColumns+`
+    FROM settings
+    ORDER BY "id"`)
+}
+
+func (pgSettings) find(ctx context.Context, query string, args ...interface{}) ([]Setting, error) {
+	con, err := handle()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := con.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -77,39 +282,37 @@ func GetSettings(user string) ([]Setting, error) {
 
 	r := []Setting{}
 	for rows.Next() {
-		s := Setting{}
-		err = rows.Scan(&s.Id, &s.User, &s.Name, &s.Value)
+		s, err := setSetting(rows)
 		if err != nil {
 			return nil, err
 		}
-		r = append(r, s)
+		r = append(r, *s)
 	}
 
-	return r, nil
+	return r, rows.Err()
 }
 
-func GetSetting(user string, name string) (*Setting, error) {
-	con, err := connect()
+func (pgSettings) Get(ctx context.Context, team, scope, owner, name string) (*Setting, error) {
+	con, err := handle()
 	if err != nil {
 		return nil, err
 	}
-	defer con.Close()
 
-	rows, err := con.Query(`
-    SELECT "id", "user", "name", "value"
+	return setSetting(con.QueryRowContext(ctx, `
+    SELECT `+settingColumns+`
     FROM settings
-    WHERE "user" = $1 AND "name" = $2`, user, name)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+    WHERE "team_id" = $1 AND "scope" = $2 AND "user" = $3 AND "name" = $4`,
+		team, scope, owner, name))
+}
 
-	if !rows.Next() {
+// setSetting scans a setting, returning nil if a single row query had
+// no results
+func setSetting(row scanner) (*Setting, error) {
+	s := Setting{}
+	err := row.Scan(&s.Id, &s.TeamID, &s.Scope, &s.User, &s.Name, &s.Value)
+	if err == sql.ErrNoRows {
 		return nil, nil
 	}
-
-	s := Setting{}
-	err = rows.Scan(&s.Id, &s.User, &s.Name, &s.Value)
 	if err != nil {
 		return nil, err
 	}
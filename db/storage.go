@@ -0,0 +1,98 @@
+package db
+
+import (
+	"fmt"
+	"os"
+)
+
+// The storage backends selected by the STORAGE environment variable
+const (
+	StoragePostgres = "postgres"
+	StorageFile     = "file"
+	StorageMemory   = "memory"
+)
+
+var storage = StoragePostgres
+
+// OpenStorage sets up the backend chosen by STORAGE:
+//
+//	postgres - the database at DATABASE_URL (the default)
+//	file     - a JSON file at STORAGE_PATH (slackbot.json by default)
+//	memory   - kept in memory only, lost when the bot exits
+//
+// The file and memory backends need no database, so the bot can run
+// offline. It must be called before any other function of the package
+func OpenStorage() error {
+	name := os.Getenv("STORAGE")
+	if name == "" {
+		name = StoragePostgres
+	}
+
+	switch name {
+	case StoragePostgres:
+		useStore(nil)
+	case StorageFile:
+		path := os.Getenv("STORAGE_PATH")
+		if path == "" {
+			path = "slackbot.json"
+		}
+
+		s, err := newMemStore(path)
+		if err != nil {
+			return err
+		}
+		useStore(s)
+	case StorageMemory:
+		s, _ := newMemStore("")
+		useStore(s)
+	default:
+		return fmt.Errorf("Unknown STORAGE %s, use %s, %s or %s",
+			name, StoragePostgres, StorageFile, StorageMemory)
+	}
+
+	storage = name
+	return nil
+}
+
+// StorageName returns the backend in use
+func StorageName() string {
+	return storage
+}
+
+// usesPostgres returns true if the data is in the database, the only
+// backend with a schema to migrate
+func usesPostgres() bool {
+	return storage == StoragePostgres
+}
+
+// useStore points the repositories at s, or at Postgres when s is nil
+func useStore(s *memStore) {
+	if s == nil {
+		Projects = pgProjects{}
+		Users = pgUsers{}
+		Settings = pgSettings{}
+		Timers = pgTimers{}
+		Poker = pgPoker{}
+		Vacations = pgVacations{}
+		Reminders = pgReminders{}
+		Conversations = pgConversations{}
+		StoryCards = pgStoryCards{}
+		Audit = pgAudit{}
+		Undo = pgUndo{}
+		Archives = pgArchives{}
+		return
+	}
+
+	Projects = memProjects{s}
+	Users = memUsers{s}
+	Settings = memSettings{s}
+	Timers = memTimers{s}
+	Poker = memPoker{s}
+	Vacations = memVacations{s}
+	Reminders = memReminders{s}
+	Conversations = memConversations{s}
+	StoryCards = memStoryCards{s}
+	Audit = memAudit{s}
+	Undo = memUndo{s}
+	Archives = memArchives{s}
+}
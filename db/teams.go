@@ -0,0 +1,16 @@
+package db
+
+import "os"
+
+// DefaultTeam owns the rows created before the bot kept data per team
+const DefaultTeam = "default"
+
+// TeamKey returns the team the data of a Slack team is stored under.
+// Requests without a team, and from the team in DEFAULT_TEAM_ID, use
+// DefaultTeam, so a workspace keeps the data it had before teams
+func TeamKey(teamID string) string {
+	if teamID == "" || teamID == os.Getenv("DEFAULT_TEAM_ID") {
+		return DefaultTeam
+	}
+	return teamID
+}
@@ -1,26 +1,77 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log"
 	"time"
 
+	"github.com/gistia/slackbot/dryrun"
 	"github.com/lib/pq"
 )
 
+// The reasons a timer is paused for
+const (
+	// PauseAway is the reason of timers paused while their user was away
+	PauseAway = "away"
+	// PauseManual is the reason of timers paused by their user
+	PauseManual = "manual"
+)
+
 // Timer tracks task timers for users
 type Timer struct {
-	ID         int
-	User       string
-	Name       string
-	CreatedAt  *time.Time
-	FinishedAt *time.Time
+	ID          int
+	TeamID      string
+	User        string
+	Name        string
+	CreatedAt   *time.Time
+	FinishedAt  *time.Time
+	PausedAt    *time.Time
+	PauseReason string
+	// Adjustment is added to the time the timer ran, to correct it by hand
+	Adjustment time.Duration
+	Notes      string
+	// PivotalStory and MavenlinkStory are the stories the timer was
+	// started for, which it's claimed to when they're finished
+	PivotalStory   string
+	MavenlinkStory string
+	// NudgedAt is when the user was asked about the timer being left
+	// running, so they're asked only once
+	NudgedAt *time.Time
+	// DryRun, when active, records the timer's writes instead of making
+	// them
+	DryRun *dryrun.Recorder `json:"-"`
+}
+
+// TimerSegment is a stretch of time a timer was running. A timer has a new
+// segment every time it's resumed
+type TimerSegment struct {
+	ID        int
+	TimerID   int
+	StartedAt *time.Time
+	EndedAt   *time.Time
+}
+
+// TimerClaim is part of a timer's time logged to a Mavenlink story as a
+// time entry. A timer claimed to several stories has one claim for each
+type TimerClaim struct {
+	ID             int
+	TimerID        int
+	PivotalStory   string
+	MavenlinkStory string
+	TimeEntryID    string
+	Minutes        int
+	CreatedAt      *time.Time
 }
 
 func (timer *Timer) Status() string {
 	if timer.IsFinished() {
 		return "finished"
 	}
+	if timer.IsPaused() {
+		return "paused"
+	}
 
 	return "running"
 }
@@ -29,15 +80,12 @@ func (timer *Timer) IsFinished() bool {
 	return timer.FinishedAt != nil
 }
 
-func (timer *Timer) Duration() string {
-	var endTime time.Time
-	if timer.FinishedAt == nil {
-		endTime = time.Now()
-	} else {
-		endTime = *timer.FinishedAt
-	}
+func (timer *Timer) IsPaused() bool {
+	return timer.PausedAt != nil
+}
 
-	duration := endTime.Sub(*timer.CreatedAt)
+func (timer *Timer) Duration() string {
+	duration := timer.Active()
 
 	s := ""
 	hours := int(duration.Hours())
@@ -61,202 +109,563 @@ func (timer *Timer) Duration() string {
 }
 
 func (timer *Timer) Minutes() int {
-	var endTime time.Time
-	if timer.FinishedAt == nil {
-		endTime = time.Now()
-	} else {
-		endTime = *timer.FinishedAt
-	}
-
-	duration := endTime.Sub(*timer.CreatedAt)
-	return int(duration.Minutes())
+	return int(timer.Active().Minutes())
 }
 
-// CreateTimer creates a new running timer
-func CreateTimer(user, name string) error {
-	con, err := connect()
+// Active returns how long the timer ran, leaving out the time it was
+// paused and including its adjustment
+func (timer *Timer) Active() time.Duration {
+	segments, err := timer.Segments()
 	if err != nil {
-		return err
+		log.Printf("Error loading segments of timer %d: %s", timer.ID, err)
 	}
-	defer con.Close()
-
-	_, err = con.Query(`
-    INSERT INTO timers
-    ("user", "name")
-    VALUES
-    ($1, $2)`, user, name)
-	return err
+	return timer.ActiveIn(segments, time.Time{}, time.Now())
 }
 
-func GetTimer(id int) (*Timer, error) {
-	con, err := connect()
-	if err != nil {
-		return nil, err
+// ActiveIn returns how much of the time between from and to the timer ran,
+// given its segments. Timers without segments ran from creation to finish.
+// The adjustment counts towards the period the timer was created in
+func (timer *Timer) ActiveIn(segments []TimerSegment, from, to time.Time) time.Duration {
+	if len(segments) < 1 {
+		segments = []TimerSegment{{StartedAt: timer.CreatedAt, EndedAt: timer.FinishedAt}}
 	}
-	defer con.Close()
 
-	rows, err := con.Query(`
-    SELECT
-      "id", "user", "name", "created_at", "finished_at"
-    FROM "timers"
-    WHERE "id" = $1
-		ORDER BY id DESC`, id)
-	if err != nil {
-		return nil, err
+	var d time.Duration
+	for _, seg := range segments {
+		if seg.StartedAt == nil {
+			continue
+		}
+
+		start, end := *seg.StartedAt, time.Now()
+		if seg.EndedAt != nil {
+			end = *seg.EndedAt
+		}
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			d += end.Sub(start)
+		}
 	}
-	defer rows.Close()
 
-	if !rows.Next() {
-		return nil, nil
+	created := timer.CreatedAt
+	if created == nil || (!created.Before(from) && created.Before(to)) {
+		d += timer.Adjustment
+	}
+	if d < 0 {
+		return 0
+	}
+	return d
+}
+
+// Segments returns the stretches of time the timer ran, oldest first
+func (timer *Timer) Segments() ([]TimerSegment, error) {
+	return Timers.Segments(context.Background(), timer.ID)
+}
+
+// Pause stops counting time from at on, until the timer is resumed
+func (timer *Timer) Pause(at time.Time, reason string) error {
+	return timer.DryRun.Do("db", "pause_timer", timer.Name, map[string]interface{}{"at": at, "reason": reason}, func() error {
+		return Timers.Pause(context.Background(), timer.ID, at, reason)
+	})
+}
+
+// Resume counts time again from at on
+func (timer *Timer) Resume(at time.Time) error {
+	return timer.DryRun.Do("db", "resume_timer", timer.Name, map[string]interface{}{"at": at}, func() error {
+		return Timers.Resume(context.Background(), timer.ID, at)
+	})
+}
+
+// AddSegment counts the time between from and to, like the time the user
+// was away, as time the timer ran
+func (timer *Timer) AddSegment(from, to time.Time) error {
+	return timer.DryRun.Do("db", "add_timer_segment", timer.Name, map[string]interface{}{"from": from, "to": to}, func() error {
+		return Timers.AddSegment(context.Background(), timer.ID, from, to)
+	})
+}
+
+// Adjust corrects the time the timer ran by d, which may be negative
+func (timer *Timer) Adjust(d time.Duration) error {
+	return timer.DryRun.Do("db", "adjust_timer", timer.Name, map[string]interface{}{"by": d.String()}, func() error {
+		return Timers.Adjust(context.Background(), timer.ID, d)
+	})
+}
+
+// Nudge records the user was asked whether they forgot the timer running
+func (timer *Timer) Nudge(at time.Time) error {
+	return timer.DryRun.Do("db", "nudge_timer", timer.Name, map[string]interface{}{"at": at}, func() error {
+		return Timers.Nudge(context.Background(), timer.ID, at)
+	})
+}
+
+// Delete discards the timer along with its segments
+func (timer *Timer) Delete() error {
+	return timer.DryRun.Do("db", "delete_timer", timer.Name, nil, func() error {
+		return Timers.Delete(context.Background(), timer.ID)
+	})
+}
+
+// Claims returns the time entries the timer's time was logged as, oldest
+// first
+func (timer *Timer) Claims() ([]TimerClaim, error) {
+	return Timers.Claims(context.Background(), timer.ID)
+}
+
+// AddClaim records a time entry created from the timer
+func (timer *Timer) AddClaim(c TimerClaim) error {
+	c.TimerID = timer.ID
+	return timer.DryRun.Do("db", "claim_timer", timer.Name, c, func() error {
+		return Timers.AddClaim(context.Background(), c)
+	})
+}
+
+// AddNote appends a line to the notes of the timer
+func (timer *Timer) AddNote(note string) error {
+	return timer.DryRun.Do("db", "add_timer_note", timer.Name, note, func() error {
+		return Timers.AddNote(context.Background(), timer.ID, note)
+	})
+}
+
+// CreateTimer creates a new timer running since at, or only records it on
+// rec when it's active
+func CreateTimer(team, user, name string, at time.Time, rec *dryrun.Recorder) error {
+	return CreateStoryTimer(team, user, name, "", "", at, rec)
+}
+
+// CreateStoryTimer creates a new timer running since at for work on
+// a Pivotal story and the Mavenlink story it's tagged with, or only records
+// it on rec when it's active
+func CreateStoryTimer(team, user, name, pivotalStory, mavenlinkStory string, at time.Time, rec *dryrun.Recorder) error {
+	timer := Timer{
+		TeamID: TeamKey(team), User: user, Name: name, CreatedAt: &at,
+		PivotalStory: pivotalStory, MavenlinkStory: mavenlinkStory,
+	}
+	details := map[string]interface{}{"at": at}
+	if pivotalStory != "" {
+		details["pivotal_story"] = pivotalStory
+		details["mavenlink_story"] = mavenlinkStory
 	}
+	return rec.Do("db", "create_timer", name, details, func() error {
+		return Timers.Create(context.Background(), timer)
+	})
+}
+
+func GetTimer(id int) (*Timer, error) {
+	return Timers.Get(context.Background(), id)
+}
+
+func GetStartedTimerByName(team, user, name string) (*Timer, error) {
+	return Timers.GetStartedByName(context.Background(), TeamKey(team), user, name)
+}
+
+// GetStartedTimerByStory returns the unfinished timer of the user started
+// for the Pivotal story
+func GetStartedTimerByStory(team, user, pivotalStory string) (*Timer, error) {
+	return Timers.GetStartedByStory(context.Background(), TeamKey(team), user, pivotalStory)
+}
+
+func GetTimerByName(team, user, name string) (*Timer, error) {
+	return Timers.GetByName(context.Background(), TeamKey(team), user, name)
+}
+
+func GetRunningTimers(team, user string) ([]Timer, error) {
+	return Timers.Running(context.Background(), TeamKey(team), user)
+}
 
-	timer, err := setTimer(rows)
+// GetAllRunningTimers returns the running timers of every user and team
+func GetAllRunningTimers() ([]Timer, error) {
+	return Timers.AllRunning(context.Background())
+}
+
+// GetTimersBetween returns the timers of the user that ran at some point
+// between from and to, or the timers of the whole team if user is empty
+func GetTimersBetween(team, user string, from, to time.Time) ([]Timer, error) {
+	return Timers.Between(context.Background(), TeamKey(team), user, from, to)
+}
+
+// Stop finishes a running timer
+func (timer *Timer) Stop() error {
+	return timer.StopAt(time.Now())
+}
+
+// StopAt finishes a running timer at the given time, which is never before
+// its last segment started
+func (timer *Timer) StopAt(at time.Time) error {
+	return timer.DryRun.Do("db", "stop_timer", timer.Name, map[string]interface{}{"at": at}, func() error {
+		return Timers.Stop(context.Background(), *timer, at)
+	})
+}
+
+// Reload reloads the timer, returning a new instance that keeps recording
+// its writes if this one does
+func (timer *Timer) Reload() (*Timer, error) {
+	t, err := GetTimer(timer.ID)
+	if t != nil {
+		t.DryRun = timer.DryRun
+	}
+	return t, err
+}
+
+//---------- Postgres
+
+const timerColumns = `
+      "id", "team_id", "user", "name", "created_at", "finished_at",
+      "paused_at", "pause_reason", "adjustment", "notes", "pivotal_story",
+      "mavenlink_story", "nudged_at"`
+
+type pgTimers struct{}
+
+// Create adds the timer along with its first segment
+func (pgTimers) Create(ctx context.Context, t Timer) error {
+	return withTx(ctx, func(tx *sql.Tx) error {
+		var id int
+		err := tx.QueryRowContext(ctx, `
+      INSERT INTO timers
+      ("team_id", "user", "name", "created_at", "pivotal_story", "mavenlink_story")
+      VALUES
+      ($1, $2, $3, $4, $5, $6)
+      RETURNING "id"`, t.TeamID, t.User, t.Name, t.CreatedAt.UTC(),
+			t.PivotalStory, t.MavenlinkStory).Scan(&id)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+      INSERT INTO timer_segments ("timer_id", "started_at")
+      SELECT "id", "created_at" FROM timers WHERE "id" = $1`, id)
+		return err
+	})
+}
+
+func (pgTimers) Get(ctx context.Context, id int) (*Timer, error) {
+	con, err := handle()
 	if err != nil {
 		return nil, err
 	}
 
-	return timer, nil
+	return setTimer(con.QueryRowContext(ctx, `
+    SELECT`+timerColumns+`
+    FROM "timers"
+    WHERE "id" = $1`, id))
 }
 
-func GetStartedTimerByName(user, name string) (*Timer, error) {
-	con, err := connect()
+func (pgTimers) GetStartedByName(ctx context.Context, team, user, name string) (*Timer, error) {
+	con, err := handle()
 	if err != nil {
 		return nil, err
 	}
-	defer con.Close()
 
-	rows, err := con.Query(`
-    SELECT
-      "id", "user", "name", "created_at", "finished_at"
+	return setTimer(con.QueryRowContext(ctx, `
+    SELECT`+timerColumns+`
     FROM "timers"
-    WHERE "finished_at" IS NULL AND "user" = $1 AND "name" = $2`, user, name)
+    WHERE "finished_at" IS NULL AND "team_id" = $1 AND "user" = $2
+          AND "name" = $3
+    ORDER BY "created_at" DESC
+    LIMIT 1`, team, user, name))
+}
+
+func (pgTimers) GetStartedByStory(ctx context.Context, team, user, pivotalStory string) (*Timer, error) {
+	con, err := handle()
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	if !rows.Next() {
-		return nil, nil
-	}
+	return setTimer(con.QueryRowContext(ctx, `
+    SELECT`+timerColumns+`
+    FROM "timers"
+    WHERE "finished_at" IS NULL AND "team_id" = $1 AND "user" = $2
+          AND "pivotal_story" = $3
+    ORDER BY "created_at" DESC
+    LIMIT 1`, team, user, pivotalStory))
+}
 
-	timer, err := setTimer(rows)
+func (pgTimers) GetByName(ctx context.Context, team, user, name string) (*Timer, error) {
+	con, err := handle()
 	if err != nil {
 		return nil, err
 	}
 
-	return timer, nil
+	return setTimer(con.QueryRowContext(ctx, `
+    SELECT`+timerColumns+`
+    FROM "timers"
+    WHERE "team_id" = $1 AND "user" = $2 AND "name" = $3
+    ORDER BY "finished_at" IS NULL DESC, "created_at" DESC
+    LIMIT 1`, team, user, name))
+}
+
+func (p pgTimers) Running(ctx context.Context, team, user string) ([]Timer, error) {
+	return p.find(ctx, `
+    SELECT`+timerColumns+`
+    FROM "timers"
+    WHERE "team_id" = $1 AND "user" = $2 AND "finished_at" IS NULL`, team, user)
+}
+
+func (p pgTimers) AllRunning(ctx context.Context) ([]Timer, error) {
+	return p.find(ctx, `
+    SELECT`+timerColumns+`
+    FROM "timers"
+    WHERE "finished_at" IS NULL
+    ORDER BY "id"`)
+}
+
+func (p pgTimers) Between(ctx context.Context, team, user string, from, to time.Time) ([]Timer, error) {
+	return p.find(ctx, `
+    SELECT`+timerColumns+`
+    FROM "timers"
+    WHERE "team_id" = $1 AND ($2 = '' OR "user" = $2) AND "created_at" < $4
+          AND ("finished_at" IS NULL OR "finished_at" >= $3)
+    ORDER BY "created_at", "id"`, team, user, from.UTC(), to.UTC())
+}
+
+func (p pgTimers) Every(ctx context.Context) ([]Timer, error) {
+	return p.find(ctx, `
+    SELECT`+timerColumns+`
+    FROM "timers"
+    ORDER BY "id"`)
 }
 
-func GetTimerByName(user, name string) (*Timer, error) {
-	con, err := connect()
+func (pgTimers) find(ctx context.Context, query string, args ...interface{}) ([]Timer, error) {
+	con, err := handle()
 	if err != nil {
 		return nil, err
 	}
-	defer con.Close()
 
-	rows, err := con.Query(`
-    SELECT
-      "id", "user", "name", "created_at", "finished_at"
-    FROM "timers"
-    WHERE "user" = $1 AND "name" = $2`, user, name)
+	rows, err := con.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	if !rows.Next() {
-		return nil, nil
-	}
+	timers := []Timer{}
+	for rows.Next() {
+		timer, err := setTimer(rows)
+		if err != nil {
+			return nil, err
+		}
 
-	timer, err := setTimer(rows)
-	if err != nil {
-		return nil, err
+		timers = append(timers, *timer)
 	}
 
-	return timer, nil
+	return timers, rows.Err()
+}
+
+// Stop finishes the timer at at, closing its open segment
+func (pgTimers) Stop(ctx context.Context, timer Timer, at time.Time) error {
+	return withTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+      UPDATE "timer_segments"
+      SET "ended_at" = GREATEST("started_at", $2)
+      WHERE "ended_at" IS NULL AND "timer_id" = $1`, timer.ID, at.UTC())
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+      UPDATE "timers"
+      SET "finished_at" = GREATEST("created_at", $2), "paused_at" = NULL,
+          "pause_reason" = ''
+      WHERE "id" = $1 AND "finished_at" IS NULL`, timer.ID, at.UTC())
+		return err
+	})
 }
 
-func GetRunningTimers(user string) ([]Timer, error) {
-	con, err := connect()
+func (pgTimers) Segments(ctx context.Context, timerID int) ([]TimerSegment, error) {
+	con, err := handle()
 	if err != nil {
 		return nil, err
 	}
-	defer con.Close()
 
-	rows, err := con.Query(`
-    SELECT
-      "id", "user", "name", "created_at", "finished_at"
-    FROM "timers"
-    WHERE "user" = $1 AND "finished_at" IS NULL`, user)
+	rows, err := con.QueryContext(ctx, `
+    SELECT "id", "timer_id", "started_at", "ended_at"
+    FROM "timer_segments"
+    WHERE "timer_id" = $1
+    ORDER BY "started_at", "id"`, timerID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	timers := []Timer{}
+	segments := []TimerSegment{}
 	for rows.Next() {
-		timer, err := setTimer(rows)
+		seg, err := setTimerSegment(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		timers = append(timers, *timer)
+		segments = append(segments, *seg)
 	}
 
-	return timers, nil
+	return segments, rows.Err()
 }
 
-// Stop finishes a running timer
-func (timer *Timer) Stop() error {
-	con, err := connect()
-	if err != nil {
+// Pause closes the open segment at at. Timers already paused or finished
+// are left as they are
+func (pgTimers) Pause(ctx context.Context, timerID int, at time.Time, reason string) error {
+	return withTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `
+      UPDATE "timers"
+      SET "paused_at" = $2, "pause_reason" = $3
+      WHERE "id" = $1 AND "paused_at" IS NULL AND "finished_at" IS NULL`,
+			timerID, at.UTC(), reason)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil || n < 1 {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+      UPDATE "timer_segments"
+      SET "ended_at" = GREATEST("started_at", $2)
+      WHERE "timer_id" = $1 AND "ended_at" IS NULL`, timerID, at.UTC())
 		return err
-	}
-	defer con.Close()
+	})
+}
 
-	fmt.Printf(" ** STOP: %+v\n", timer)
+// Resume opens a new segment at at for a paused timer
+func (pgTimers) Resume(ctx context.Context, timerID int, at time.Time) error {
+	return withTx(ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, `
+      UPDATE "timers"
+      SET "paused_at" = NULL, "pause_reason" = ''
+      WHERE "id" = $1 AND "paused_at" IS NOT NULL AND "finished_at" IS NULL`,
+			timerID)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil || n < 1 {
+			return err
+		}
 
-	_, err = con.Query(`
-    UPDATE "timers"
-    SET "finished_at" = CURRENT_TIMESTAMP
-    WHERE "user"=$1 AND "name"=$2 AND "finished_at" IS NULL
-  `, timer.User, timer.Name)
-	return err
+		_, err = tx.ExecContext(ctx, `
+      INSERT INTO "timer_segments" ("timer_id", "started_at")
+      VALUES ($1, $2)`, timerID, at.UTC())
+		return err
+	})
 }
 
-// Reload reloads the timer, returning a new instance
-func (timer *Timer) Reload() (*Timer, error) {
-	return GetTimer(timer.ID)
+func (pgTimers) AddSegment(ctx context.Context, timerID int, from, to time.Time) error {
+	return pgExec(ctx, `
+    INSERT INTO "timer_segments" ("timer_id", "started_at", "ended_at")
+    VALUES ($1, $2, $3)`, timerID, from.UTC(), to.UTC())
 }
 
-func setTimer(rows *sql.Rows) (*Timer, error) {
-	var finishedAt pq.NullTime
-	var createdAt pq.NullTime
+func (pgTimers) Adjust(ctx context.Context, timerID int, d time.Duration) error {
+	return pgExec(ctx, `
+    UPDATE "timers" SET "adjustment" = "adjustment" + $2
+    WHERE "id" = $1`, timerID, int64(d/time.Second))
+}
 
-	timer := Timer{}
+func (pgTimers) Nudge(ctx context.Context, timerID int, at time.Time) error {
+	return pgExec(ctx, `
+    UPDATE "timers" SET "nudged_at" = $2
+    WHERE "id" = $1`, timerID, at.UTC())
+}
+
+// Delete removes the timer, its segments going along with it
+func (pgTimers) Delete(ctx context.Context, timerID int) error {
+	return pgExec(ctx, `DELETE FROM "timers" WHERE "id" = $1`, timerID)
+}
 
-	err := rows.Scan(&timer.ID, &timer.User, &timer.Name, &createdAt, &finishedAt)
+func (pgTimers) Claims(ctx context.Context, timerID int) ([]TimerClaim, error) {
+	con, err := handle()
 	if err != nil {
 		return nil, err
 	}
 
-	val, err := createdAt.Value()
+	rows, err := con.QueryContext(ctx, `
+    SELECT "id", "timer_id", "pivotal_story", "mavenlink_story",
+           "time_entry_id", "minutes", "created_at"
+    FROM "timer_claims"
+    WHERE "timer_id" = $1
+    ORDER BY "id"`, timerID)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	if val != nil {
-		timer.CreatedAt = &createdAt.Time
+	claims := []TimerClaim{}
+	for rows.Next() {
+		c, err := setTimerClaim(rows)
+		if err != nil {
+			return nil, err
+		}
+		claims = append(claims, *c)
 	}
 
-	val, err = finishedAt.Value()
+	return claims, rows.Err()
+}
+
+func (pgTimers) AddClaim(ctx context.Context, c TimerClaim) error {
+	return pgExec(ctx, `
+    INSERT INTO "timer_claims"
+    ("timer_id", "pivotal_story", "mavenlink_story", "time_entry_id", "minutes")
+    VALUES ($1, $2, $3, $4, $5)`,
+		c.TimerID, c.PivotalStory, c.MavenlinkStory, c.TimeEntryID, c.Minutes)
+}
+
+func (pgTimers) AddNote(ctx context.Context, timerID int, note string) error {
+	return pgExec(ctx, `
+    UPDATE "timers"
+    SET "notes" = CASE WHEN "notes" = '' THEN $2 ELSE "notes" || E'\n' || $2 END
+    WHERE "id" = $1`, timerID, note)
+}
+
+// setTimer scans a timer, returning nil if a single row query had
+// no results
+func setTimer(row scanner) (*Timer, error) {
+	var finishedAt, createdAt, pausedAt, nudgedAt pq.NullTime
+
+	var adjustment int64
+	timer := Timer{}
+
+	err := row.Scan(&timer.ID, &timer.TeamID, &timer.User, &timer.Name,
+		&createdAt, &finishedAt, &pausedAt, &timer.PauseReason, &adjustment,
+		&timer.Notes, &timer.PivotalStory, &timer.MavenlinkStory, &nudgedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	if val != nil {
-		timer.FinishedAt = &finishedAt.Time
-	}
+	timer.CreatedAt = nullTime(createdAt)
+	timer.FinishedAt = nullTime(finishedAt)
+	timer.PausedAt = nullTime(pausedAt)
+	timer.NudgedAt = nullTime(nudgedAt)
+	timer.Adjustment = time.Duration(adjustment) * time.Second
 
 	return &timer, nil
 }
+
+func setTimerSegment(row scanner) (*TimerSegment, error) {
+	var startedAt, endedAt pq.NullTime
+
+	seg := TimerSegment{}
+	if err := row.Scan(&seg.ID, &seg.TimerID, &startedAt, &endedAt); err != nil {
+		return nil, err
+	}
+
+	seg.StartedAt = nullTime(startedAt)
+	seg.EndedAt = nullTime(endedAt)
+
+	return &seg, nil
+}
+
+func setTimerClaim(row scanner) (*TimerClaim, error) {
+	var createdAt pq.NullTime
+
+	c := TimerClaim{}
+	err := row.Scan(&c.ID, &c.TimerID, &c.PivotalStory, &c.MavenlinkStory,
+		&c.TimeEntryID, &c.Minutes, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	c.CreatedAt = nullTime(createdAt)
+
+	return &c, nil
+}
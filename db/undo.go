@@ -0,0 +1,163 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// UndoBatchGap is the maximum time between two changes for them to be
+// considered part of the same user action
+var UndoBatchGap = 30 * time.Second
+
+// UndoAction is the inverse of a change the bot made on an external system,
+// kept so the change can be rolled back
+type UndoAction struct {
+	ID          int
+	TeamID      string
+	User        string
+	System      string
+	Action      string
+	Target      string
+	Payload     string
+	Description string
+	CreatedAt   *time.Time
+}
+
+// Decode unmarshals the action's JSON payload into v
+func (u *UndoAction) Decode(v interface{}) error {
+	return json.Unmarshal([]byte(u.Payload), v)
+}
+
+// RecordUndo stores how to revert a change. Failures are only logged, since
+// they must not break the change itself
+func RecordUndo(team, user, system, action, target, desc string, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err == nil {
+		err = CreateUndoAction(UndoAction{
+			TeamID:      team,
+			User:        user,
+			System:      system,
+			Action:      action,
+			Target:      target,
+			Payload:     string(data),
+			Description: desc,
+		})
+	}
+	if err != nil {
+		log.Printf("Error recording undo for %s %s: %s", action, target, err)
+	}
+}
+
+func CreateUndoAction(u UndoAction) error {
+	u.TeamID = TeamKey(u.TeamID)
+	return Undo.Create(context.Background(), u)
+}
+
+// GetLastUndoBatch returns the inverse actions of the user's most recent
+// action, newest first, as long as it happened within window
+func GetLastUndoBatch(team, user string, window time.Duration) ([]UndoAction, error) {
+	pending, err := Undo.Pending(context.Background(), TeamKey(team), user, window)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := []UndoAction{}
+	for _, u := range pending {
+		if len(actions) > 0 {
+			last := actions[len(actions)-1]
+			if last.CreatedAt.Sub(*u.CreatedAt) > UndoBatchGap {
+				break
+			}
+		}
+
+		actions = append(actions, u)
+	}
+
+	return actions, nil
+}
+
+// MarkUndone flags the given actions as already reverted
+func MarkUndone(actions []UndoAction) error {
+	for _, u := range actions {
+		if err := Undo.MarkUndone(context.Background(), u.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//---------- Postgres
+
+type pgUndo struct{}
+
+func (pgUndo) Create(ctx context.Context, u UndoAction) error {
+	return pgExec(ctx, `
+    INSERT INTO undo_actions
+    ("team_id", "user", "system", "action", "target", "payload", "description")
+    VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		u.TeamID, u.User, u.System, u.Action, u.Target, u.Payload, u.Description)
+}
+
+func (pgUndo) Pending(ctx context.Context, team, user string, window time.Duration) ([]UndoAction, error) {
+	con, err := handle()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := con.QueryContext(ctx, `
+    SELECT
+      "id", "team_id", "user", "system", "action", "target", "payload",
+      "description", "created_at"
+    FROM undo_actions
+    WHERE "team_id" = $1 AND "user" = $2
+          AND "undone" = FALSE
+          AND "created_at" >= CURRENT_TIMESTAMP - $3 * INTERVAL '1 second'
+    ORDER BY "id" DESC`, team, user, int(window.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	actions := []UndoAction{}
+	for rows.Next() {
+		u, err := setUndoAction(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		actions = append(actions, *u)
+	}
+
+	return actions, rows.Err()
+}
+
+func (pgUndo) MarkUndone(ctx context.Context, id int) error {
+	return pgExec(ctx, `
+    UPDATE undo_actions SET "undone" = TRUE
+    WHERE "id" = $1`, id)
+}
+
+func setUndoAction(row scanner) (*UndoAction, error) {
+	var createdAt pq.NullTime
+
+	u := UndoAction{}
+	err := row.Scan(&u.ID, &u.TeamID, &u.User, &u.System, &u.Action, &u.Target,
+		&u.Payload, &u.Description, &createdAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if createdAt.Valid {
+		u.CreatedAt = &createdAt.Time
+	} else {
+		now := time.Now()
+		u.CreatedAt = &now
+	}
+
+	return &u, nil
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"strconv"
@@ -8,6 +9,7 @@ import (
 
 type User struct {
 	Id          *int
+	TeamID      string
 	Name        string
 	PivotalId   *int64
 	MavenlinkId *int64
@@ -17,17 +19,60 @@ func (u User) StrPivotalId() string {
 	return strconv.FormatInt(*u.PivotalId, 10)
 }
 
-func GetUsers() ([]User, error) {
-	con, err := connect()
+func GetUsers(team string) ([]User, error) {
+	return Users.All(context.Background(), TeamKey(team))
+}
+
+func GetUserByName(team string, name string) (*User, error) {
+	return GetUserBy(team, "name", name)
+}
+
+func GetUserBy(team string, field string, s string) (*User, error) {
+	return Users.GetBy(context.Background(), TeamKey(team), field, s)
+}
+
+func SaveUser(u User) error {
+	u.TeamID = TeamKey(u.TeamID)
+	return Users.Save(context.Background(), u)
+}
+
+func CreateUser(u User) error {
+	u.TeamID = TeamKey(u.TeamID)
+	return Users.Create(context.Background(), u)
+}
+
+func UpdateUser(u User) error {
+	return Users.Update(context.Background(), u)
+}
+
+//---------- Postgres
+
+const userColumns = `
+      "id", "team_id", "name", "pivotal_id", "mavenlink_id"`
+
+type pgUsers struct{}
+
+func (p pgUsers) All(ctx context.Context, team string) ([]User, error) {
+	return p.find(ctx, `
+    SELECT`+userColumns+`
+    FROM users
+    WHERE "team_id" = $1`, team)
+}
+
+func (p pgUsers) Every(ctx context.Context) ([]User, error) {
+	return p.find(ctx, `
+    SELECT`+userColumns+`
+    FROM users
+    ORDER BY "id"`)
+}
+
+func (pgUsers) find(ctx context.Context, query string, args ...interface{}) ([]User, error) {
+	con, err := handle()
 	if err != nil {
 		return nil, err
 	}
-	defer con.Close()
 
-	rows, err := con.Query(`
-    SELECT
-      "id", "name", "pivotal_id", "mavenlink_id"
-    FROM users`)
+	rows, err := con.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -43,73 +88,69 @@ func GetUsers() ([]User, error) {
 		users = append(users, *u)
 	}
 
-	return users, nil
+	return users, rows.Err()
 }
 
-func GetUserByName(name string) (*User, error) {
-	return GetUserBy("name", name)
-}
-
-func GetUserBy(field string, s string) (*User, error) {
-	con, err := connect()
+func (pgUsers) GetBy(ctx context.Context, team string, field string, s string) (*User, error) {
+	con, err := handle()
 	if err != nil {
 		return nil, err
 	}
-	defer con.Close()
 
-	rows, err := con.Query(`
-    SELECT
-      "id", "name", "pivotal_id", "mavenlink_id"
-    FROM users
-    WHERE "`+field+`" = $1`, s)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+	return getUserBy(ctx, con, team, field, s)
+}
 
-	if !rows.Next() {
-		return nil, nil
-	}
+func (pgUsers) Save(ctx context.Context, u User) error {
+	return withTx(ctx, func(tx *sql.Tx) error {
+		existingUser, err := getUserBy(ctx, tx, u.TeamID, "name", u.Name)
+		if err != nil {
+			return err
+		}
+		if existingUser == nil {
+			return createUser(ctx, tx, u)
+		}
 
-	return setUser(rows)
+		u.Id = existingUser.Id
+		return updateUser(ctx, tx, u)
+	})
 }
 
-func SaveUser(u User) error {
-	existingUser, err := GetUserByName(u.Name)
+func (pgUsers) Create(ctx context.Context, u User) error {
+	con, err := handle()
 	if err != nil {
 		return err
 	}
-	if existingUser == nil {
-		return CreateUser(u)
-	}
 
-	u.Id = existingUser.Id
-	return UpdateUser(u)
+	return createUser(ctx, con, u)
 }
 
-func CreateUser(u User) error {
-	con, err := connect()
+func (pgUsers) Update(ctx context.Context, u User) error {
+	con, err := handle()
 	if err != nil {
 		return err
 	}
-	defer con.Close()
 
-	_, err = con.Query(`
+	return updateUser(ctx, con, u)
+}
+
+func getUserBy(ctx context.Context, q querier, team string, field string, s string) (*User, error) {
+	return setUser(q.QueryRowContext(ctx, `
+    SELECT`+userColumns+`
+    FROM users
+    WHERE "team_id" = $1 AND "`+field+`" = $2`, team, s))
+}
+
+func createUser(ctx context.Context, q querier, u User) error {
+	_, err := q.ExecContext(ctx, `
     INSERT INTO users
-    (name, pivotal_id, mavenlink_id)
-    VALUES ($1, $2, $3)`,
-		u.Name, u.PivotalId, u.MavenlinkId)
+    (team_id, name, pivotal_id, mavenlink_id)
+    VALUES ($1, $2, $3, $4)`,
+		u.TeamID, u.Name, u.PivotalId, u.MavenlinkId)
 	return err
 }
 
-func UpdateUser(u User) error {
-	con, err := connect()
-	if err != nil {
-		return err
-	}
-	defer con.Close()
-
-	stmt, err := con.Prepare(`
+func updateUser(ctx context.Context, q querier, u User) error {
+	res, err := q.ExecContext(ctx, `
     UPDATE
       users
     SET
@@ -117,11 +158,7 @@ func UpdateUser(u User) error {
       "pivotal_id" = $2,
       "mavenlink_id" = $3
     WHERE
-      "id" = $4`)
-	if err != nil {
-		return err
-	}
-	res, err := stmt.Exec(u.Name, u.PivotalId, u.MavenlinkId, u.Id)
+      "id" = $4`, u.Name, u.PivotalId, u.MavenlinkId, u.Id)
 	if err != nil {
 		return err
 	}
@@ -132,12 +169,16 @@ func UpdateUser(u User) error {
 	return err
 }
 
-func setUser(rows *sql.Rows) (*User, error) {
+// setUser scans a user, returning nil if a single row query had no results
+func setUser(row scanner) (*User, error) {
 	var pivotalId sql.NullInt64
 	var mavenlinkId sql.NullInt64
 
 	u := User{}
-	err := rows.Scan(&u.Id, &u.Name, &pivotalId, &mavenlinkId)
+	err := row.Scan(&u.Id, &u.TeamID, &u.Name, &pivotalId, &mavenlinkId)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -1,45 +1,108 @@
 package db
 
-import "time"
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
 
 type Vacation struct {
 	ID          int
+	TeamID      string
 	User        string
 	StartDate   *time.Time
 	EndDate     *time.Time
 	Description string
 }
 
-func CreateVacation(user string, desc string, start *time.Time, end *time.Time) (*Vacation, error) {
-	db, err := GormConn()
-	if err != nil {
+func CreateVacation(team string, user string, desc string, start *time.Time, end *time.Time) (*Vacation, error) {
+	vacation := &Vacation{TeamID: TeamKey(team), User: user, Description: desc,
+		StartDate: start, EndDate: end}
+	if err := Vacations.Create(context.Background(), vacation); err != nil {
 		return nil, err
 	}
-	vacation := &Vacation{User: user, Description: desc, StartDate: start, EndDate: end}
-	db.Create(vacation)
 	return vacation, nil
 }
 
-func GetVacations() ([]Vacation, error) {
-	db, err := GormConn()
+func GetVacations(team string) ([]Vacation, error) {
+	return Vacations.EndingAfter(context.Background(), TeamKey(team), time.Now())
+}
+
+func GetCurrentVacations(team string) ([]Vacation, error) {
+	return Vacations.During(context.Background(), TeamKey(team), time.Now())
+}
+
+//---------- Postgres
+
+type pgVacations struct{}
+
+func (pgVacations) Create(ctx context.Context, v *Vacation) error {
+	con, err := handle()
+	if err != nil {
+		return err
+	}
+
+	return con.QueryRowContext(ctx, `
+    INSERT INTO vacations
+    ("team_id", "user", "description", "start_date", "end_date")
+    VALUES ($1, $2, $3, $4, $5)
+    RETURNING "id"`,
+		v.TeamID, v.User, v.Description, v.StartDate, v.EndDate).Scan(&v.ID)
+}
+
+func (p pgVacations) EndingAfter(ctx context.Context, team string, t time.Time) ([]Vacation, error) {
+	return p.find(ctx, `"team_id" = $1 AND "end_date" >= $2`, team, t)
+}
+
+func (p pgVacations) During(ctx context.Context, team string, t time.Time) ([]Vacation, error) {
+	return p.find(ctx, `"team_id" = $1 AND "start_date" <= $2 AND "end_date" >= $2`, team, t)
+}
+
+func (pgVacations) find(ctx context.Context, where string, args ...interface{}) ([]Vacation, error) {
+	con, err := handle()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := con.QueryContext(ctx, `
+    SELECT
+      "id", "team_id", "user", "description", "start_date", "end_date"
+    FROM vacations
+    WHERE `+where, args...)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	vacations := []Vacation{}
-	db.Where("end_date >= ?", time.Now()).Find(&vacations)
+	for rows.Next() {
+		v, err := setVacation(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		vacations = append(vacations, *v)
+	}
 
-	return vacations, nil
+	return vacations, rows.Err()
 }
 
-func GetCurrentVacations() ([]Vacation, error) {
-	db, err := GormConn()
+func setVacation(row scanner) (*Vacation, error) {
+	var user, desc sql.NullString
+	var start, end pq.NullTime
+
+	v := Vacation{}
+	err := row.Scan(&v.ID, &v.TeamID, &user, &desc, &start, &end)
 	if err != nil {
 		return nil, err
 	}
 
-	vacations := []Vacation{}
-	db.Where("start_date <= ? AND end_date >= ?", time.Now(), time.Now()).Find(&vacations)
+	v.User = user.String
+	v.Description = desc.String
+	v.StartDate = nullTime(start)
+	v.EndDate = nullTime(end)
 
-	return vacations, nil
+	return &v, nil
 }
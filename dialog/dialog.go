@@ -0,0 +1,159 @@
+package dialog
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gistia/slackbot/db"
+	"github.com/gistia/slackbot/robots"
+)
+
+// Timeout is how long a conversation waits for an answer before
+// it's discarded
+var Timeout = 30 * time.Minute
+
+// Answers maps each step name to the answer given by the user
+type Answers map[string]string
+
+// Step is a single question within a Dialog
+type Step struct {
+	Name   string
+	Prompt string
+	// Validate checks the answer, returning the value to be stored. A nil
+	// Validate accepts any non-empty answer
+	Validate func(p *robots.Payload, a Answers, s string) (string, error)
+}
+
+// Dialog is a sequence of questions that, once all answered, runs an action
+type Dialog struct {
+	Name  string
+	Steps []Step
+	Run   func(p *robots.Payload, a Answers) error
+}
+
+// Replier sends messages back to the user taking part in a conversation
+type Replier interface {
+	Send(p *robots.Payload, s string)
+}
+
+// Dialogs is the map of registered dialog names to dialogs
+var Dialogs = make(map[string]Dialog)
+
+// Register makes a dialog available to be started and resumed by name
+func Register(d Dialog) {
+	log.Printf("Registered dialog: %s", d.Name)
+	Dialogs[d.Name] = d
+}
+
+// Start begins a conversation for the payload's user and channel, replacing
+// any ongoing one, and asks the first question. Initial answers may be given
+// for steps that should be skipped
+func Start(p *robots.Payload, r Replier, name string, initial Answers) error {
+	if p.DryRun.Active() {
+		return errors.New("Dry runs can't ask questions, give all the arguments instead")
+	}
+
+	d, ok := Dialogs[name]
+	if !ok {
+		return errors.New("Unknown dialog " + name)
+	}
+
+	c := &db.Conversation{
+		TeamID:  p.TeamID,
+		User:    p.UserName,
+		Channel: p.ChannelID,
+		Dialog:  name,
+		Answers: map[string]string{},
+	}
+	for k, v := range initial {
+		c.Answers[k] = v
+	}
+
+	r.Send(p, "Answer the questions below. Say `back` to change the previous answer or `cancel` to give up.")
+	return advance(p, r, d, c)
+}
+
+// Handle feeds a message to the ongoing conversation for the payload's user
+// and channel. It returns false if there's no such conversation, meaning the
+// message wasn't consumed
+func Handle(p *robots.Payload, r Replier) (bool, error) {
+	c, err := db.GetConversation(p.TeamID, p.UserName, p.ChannelID)
+	if err != nil || c == nil {
+		return false, err
+	}
+
+	d, ok := Dialogs[c.Dialog]
+	if !ok || c.UpdatedAt == nil || time.Now().UTC().Sub(*c.UpdatedAt) > Timeout {
+		return false, db.DeleteConversation(c.TeamID, c.User, c.Channel)
+	}
+
+	text := strings.TrimSpace(p.Text)
+	switch strings.ToLower(text) {
+	case "cancel":
+		r.Send(p, "Ok, never mind.")
+		return true, db.DeleteConversation(c.TeamID, c.User, c.Channel)
+	case "back":
+		if c.Step > 0 {
+			c.Step--
+		}
+		delete(c.Answers, d.Steps[c.Step].Name)
+		return true, ask(p, r, d, c)
+	}
+
+	step := d.Steps[c.Step]
+	value := text
+	if step.Validate != nil {
+		value, err = step.Validate(p, c.Answers, text)
+	} else if value == "" {
+		err = errors.New("I need an answer to continue")
+	}
+	if err != nil {
+		r.Send(p, err.Error())
+		return true, ask(p, r, d, c)
+	}
+
+	c.Answers[step.Name] = value
+	c.Step++
+	return true, advance(p, r, d, c)
+}
+
+// advance skips already answered steps, asking the next question or running
+// the dialog's action once every step is answered
+func advance(p *robots.Payload, r Replier, d Dialog, c *db.Conversation) error {
+	for c.Step < len(d.Steps) {
+		if _, ok := c.Answers[d.Steps[c.Step].Name]; !ok {
+			return ask(p, r, d, c)
+		}
+		c.Step++
+	}
+
+	if err := db.DeleteConversation(c.TeamID, c.User, c.Channel); err != nil {
+		return err
+	}
+
+	return d.Run(p, Answers(c.Answers))
+}
+
+func ask(p *robots.Payload, r Replier, d Dialog, c *db.Conversation) error {
+	if err := db.SaveConversation(c); err != nil {
+		return err
+	}
+
+	step := d.Steps[c.Step]
+	r.Send(p, fmt.Sprintf("(%d/%d) %s", c.Step+1, len(d.Steps), step.Prompt))
+	return nil
+}
+
+// Confirm is a Validate function for yes/no questions that only accepts yes
+func Confirm(p *robots.Payload, a Answers, s string) (string, error) {
+	switch strings.ToLower(s) {
+	case "y", "yes":
+		return "yes", nil
+	case "n", "no":
+		return "", errors.New("Say `cancel` to give up or `back` to change your answers.")
+	}
+	return "", errors.New("Please answer `yes` or `no`.")
+}
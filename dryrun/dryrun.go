@@ -0,0 +1,114 @@
+package dryrun
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Flag is the option that, added to any command, makes it report the
+// changes it would make instead of making them
+const Flag = "--dry-run"
+
+// Change is a single write that would have been made to a system
+type Change struct {
+	System  string
+	Action  string
+	Target  string
+	Details string
+}
+
+// Recorder collects the writes of a command run with --dry-run. A nil
+// Recorder means the command runs for real
+type Recorder struct {
+	mu      sync.Mutex
+	changes []Change
+}
+
+func New() *Recorder {
+	return &Recorder{}
+}
+
+// Parse removes the dry-run flag from text, returning a Recorder if the
+// flag was present or nil otherwise
+func Parse(text string) (string, *Recorder) {
+	words := strings.Fields(text)
+	rest := []string{}
+	var rec *Recorder
+	for _, w := range words {
+		if w == Flag {
+			rec = New()
+			continue
+		}
+		rest = append(rest, w)
+	}
+
+	if rec == nil {
+		return text, nil
+	}
+	return strings.Join(rest, " "), rec
+}
+
+// Active tells if writes must be recorded instead of performed
+func (r *Recorder) Active() bool {
+	return r != nil
+}
+
+// Record adds a change, with details marshaled to JSON unless they
+// already are a string
+func (r *Recorder) Record(system, action, target string, details interface{}) {
+	s, ok := details.(string)
+	if !ok && details != nil {
+		data, err := json.Marshal(details)
+		if err != nil {
+			s = err.Error()
+		} else if string(data) != "null" {
+			s = string(data)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.changes = append(r.changes, Change{
+		System:  system,
+		Action:  action,
+		Target:  target,
+		Details: s,
+	})
+}
+
+// Do runs fn, or only records the change when the Recorder is active
+func (r *Recorder) Do(system, action, target string, details interface{}, fn func() error) error {
+	if !r.Active() {
+		return fn()
+	}
+
+	r.Record(system, action, target, details)
+	return nil
+}
+
+// Changes returns the changes recorded so far, in order
+func (r *Recorder) Changes() []Change {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Change{}, r.changes...)
+}
+
+// Report describes every recorded change
+func (r *Recorder) Report() string {
+	changes := r.Changes()
+	if len(changes) < 1 {
+		return "This was a dry run. No changes would have been made."
+	}
+
+	s := "This was a dry run, nothing was changed. The command would have made these changes:\n"
+	for i, c := range changes {
+		s += fmt.Sprintf("%d. %s `%s` %s", i+1, c.System, c.Action, c.Target)
+		if c.Details != "" {
+			s += " " + c.Details
+		}
+		s += "\n"
+	}
+	return s
+}
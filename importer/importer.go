@@ -1,12 +1,19 @@
 package importer
 
 import (
+	_ "github.com/gistia/slackbot/robots/admin"
+	_ "github.com/gistia/slackbot/robots/audit"
 	_ "github.com/gistia/slackbot/robots/github"
 	_ "github.com/gistia/slackbot/robots/mavenlink"
 	_ "github.com/gistia/slackbot/robots/pivotal"
 	_ "github.com/gistia/slackbot/robots/poker"
 	_ "github.com/gistia/slackbot/robots/project"
+	_ "github.com/gistia/slackbot/robots/remind"
 	_ "github.com/gistia/slackbot/robots/store"
+	_ "github.com/gistia/slackbot/robots/timer"
+	_ "github.com/gistia/slackbot/robots/timesheet"
+	_ "github.com/gistia/slackbot/robots/tz"
+	_ "github.com/gistia/slackbot/robots/undo"
 	_ "github.com/gistia/slackbot/robots/user"
 	_ "github.com/gistia/slackbot/robots/vacation"
 	_ "github.com/gistia/slackbot/robots/foodtrucks"
@@ -2,13 +2,20 @@
 touch $1
 > $1
 robots=(
+    "github.com/gistia/slackbot/robots/admin"
+    "github.com/gistia/slackbot/robots/audit"
     "github.com/gistia/slackbot/robots/github"
     "github.com/gistia/slackbot/robots/mavenlink"
     "github.com/gistia/slackbot/robots/ping"
     "github.com/gistia/slackbot/robots/pivotal"
     "github.com/gistia/slackbot/robots/poker"
     "github.com/gistia/slackbot/robots/project"
+    "github.com/gistia/slackbot/robots/remind"
     "github.com/gistia/slackbot/robots/store"
+    "github.com/gistia/slackbot/robots/timer"
+    "github.com/gistia/slackbot/robots/timesheet"
+    "github.com/gistia/slackbot/robots/tz"
+    "github.com/gistia/slackbot/robots/undo"
     "github.com/gistia/slackbot/robots/user"
     "github.com/gistia/slackbot/robots/vacation"
     "github.com/gistia/slackbot/robots/foodtrucks"
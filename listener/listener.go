@@ -0,0 +1,124 @@
+package listener
+
+import (
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gistia/slackbot/db"
+	"github.com/gistia/slackbot/robots"
+	"github.com/gistia/slackbot/utils"
+)
+
+// Listener reacts to ordinary channel messages, the ones that aren't direct
+// messages or mentions of the bot, that match its pattern
+type Listener struct {
+	Name    string
+	Pattern *regexp.Regexp
+	// Channels are the names of the channels the listener hears. It hears
+	// every channel when empty
+	Channels []string
+	// Cooldown is how long the listener stays quiet in a channel after it
+	// runs, so busy channels aren't flooded
+	Cooldown time.Duration
+	// Run is given every match of Pattern in the message, each with its
+	// submatches
+	Run func(p *robots.Payload, r Replier, matches [][]string) error
+}
+
+// Replier sends messages to the channel a message was heard in
+type Replier interface {
+	Send(p *robots.Payload, s string)
+}
+
+// Listeners are the registered listeners, in the order they run
+var Listeners []Listener
+
+var (
+	mu      sync.Mutex
+	lastRun = map[string]time.Time{}
+)
+
+// Register makes a listener hear channel messages
+func Register(l Listener) {
+	log.Printf("Registered listener: %s", l.Name)
+	Listeners = append(Listeners, l)
+}
+
+// Handle runs every listener that hears the payload's channel and matches
+// its text, unless it's cooling down there. The LISTENERS setting, most
+// useful scoped to a channel, can turn them all off with `none` or name the
+// only ones allowed, comma separated. It returns true if any listener ran
+func Handle(p *robots.Payload, r Replier) (bool, error) {
+	allowed, err := allowedListeners(p)
+	if err != nil {
+		return false, err
+	}
+
+	ran := false
+	for _, l := range Listeners {
+		if !allowed(l.Name) || !l.hears(p.ChannelName) {
+			continue
+		}
+
+		matches := l.Pattern.FindAllStringSubmatch(p.Text, -1)
+		if len(matches) < 1 || !l.ready(p.ChannelID, time.Now()) {
+			continue
+		}
+
+		ran = true
+		if e := l.Run(p, r, matches); e != nil {
+			log.Printf("Error in listener %s: %s\n", l.Name, e)
+			err = e
+		}
+	}
+
+	return ran, err
+}
+
+// allowedListeners returns whether the LISTENERS setting lets a listener
+// run for the payload
+func allowedListeners(p *robots.Payload) (func(string) bool, error) {
+	s, err := db.ResolveSetting(utils.SettingContext(p), "LISTENERS")
+	if err != nil || s == nil || strings.TrimSpace(s.Value) == "" {
+		return func(string) bool { return true }, err
+	}
+
+	names := map[string]bool{}
+	for _, name := range strings.Split(s.Value, ",") {
+		names[strings.TrimSpace(name)] = true
+	}
+	return func(name string) bool { return names[name] }, nil
+}
+
+func (l Listener) hears(channel string) bool {
+	if len(l.Channels) < 1 {
+		return true
+	}
+	for _, ch := range l.Channels {
+		if strings.TrimPrefix(ch, "#") == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// ready tells whether the listener's cooldown in the channel is over,
+// starting a new one if so
+func (l Listener) ready(channelID string, now time.Time) bool {
+	if l.Cooldown <= 0 {
+		return true
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	key := l.Name + "/" + channelID
+	if last, ok := lastRun[key]; ok && now.Sub(last) < l.Cooldown {
+		return false
+	}
+	lastRun[key] = now
+	return true
+}
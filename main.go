@@ -7,9 +7,12 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gistia/slackbot/db"
+	"github.com/gistia/slackbot/dryrun"
 	_ "github.com/gistia/slackbot/importer"
 	"github.com/gistia/slackbot/robots"
 	"github.com/gistia/slackbot/userbot"
@@ -21,6 +24,34 @@ import (
 )
 
 func main() {
+	if err := db.OpenStorage(); err != nil {
+		log.Fatal(err)
+	}
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			migrate(os.Args[2:])
+			return
+		case "keys":
+			keys(os.Args[2:])
+			return
+		case "export":
+			export(os.Args[2:])
+			return
+		case "import":
+			importArchive(os.Args[2:])
+			return
+		}
+	}
+
+	if err := db.CheckSchema(); err != nil {
+		log.Fatal(err)
+	}
+	if os.Getenv("DEFAULT_TEAM_ID") == "" {
+		log.Println("WARNING: DEFAULT_TEAM_ID isn't set, only requests without a team will see the data created before teams")
+	}
+
 	r := mux.NewRouter()
 	r.HandleFunc("/slack", slashCommandHandler)
 	r.HandleFunc("/slack_hook", hookHandler)
@@ -31,14 +62,148 @@ func main() {
 	pokerRouter.Methods("GET").Path("/poker").HandlerFunc(web.NewPokerStories)
 	pokerRouter.Methods("POST").Path("/poker").HandlerFunc(web.CreatePokerStories)
 	http.Handle("/poker", pokerRouter)
+	http.HandleFunc("/timesheet.csv", web.TimesheetCSV)
 
-	go db.SetupDB()
 	if os.Getenv("RUN_BOT") != "" {
 		go startBot()
 	}
 	startServer()
 }
 
+// migrate runs `slackbot migrate [up|down [steps]|status]`
+func migrate(args []string) {
+	cmd := "up"
+	if len(args) > 0 {
+		cmd = args[0]
+	}
+
+	var ms []db.Migration
+	var err error
+	switch cmd {
+	case "up":
+		ms, err = db.Migrate()
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		ms, err = db.Rollback(steps)
+	case "status":
+		version, err := db.SchemaVersion()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Schema version %d, latest is %d\n", version, db.LatestVersion())
+		return
+	default:
+		log.Fatal("Usage: slackbot migrate [up|down [steps]|status]")
+	}
+
+	for _, m := range ms {
+		fmt.Printf("%s %d - %s\n", cmd, m.Version, m.Name)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(ms) < 1 {
+		fmt.Println("Nothing to migrate")
+	}
+}
+
+// keys runs `slackbot keys [generate [id]|rotate]`
+func keys(args []string) {
+	cmd := ""
+	if len(args) > 0 {
+		cmd = args[0]
+	}
+
+	switch cmd {
+	case "generate":
+		id := time.Now().Format("20060102")
+		if len(args) > 1 {
+			id = args[1]
+		}
+		key, err := db.GenerateSettingsKey(id)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(key)
+	case "rotate":
+		n, err := db.RotateSettingsKey()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("Re-encrypted %d settings\n", n)
+	default:
+		log.Fatal("Usage: slackbot keys [generate [id]|rotate]")
+	}
+}
+
+// export runs `slackbot export [--no-secrets] [file]`, writing to stdout
+// when no file is given
+func export(args []string) {
+	if err := db.CheckSchema(); err != nil {
+		log.Fatal(err)
+	}
+
+	secrets := true
+	path := ""
+	for _, a := range args {
+		if a == "--no-secrets" {
+			secrets = false
+			continue
+		}
+		path = a
+	}
+
+	a, err := db.Export(secrets)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	out := os.Stdout
+	if path != "" {
+		if out, err = os.Create(path); err != nil {
+			log.Fatal(err)
+		}
+		defer out.Close()
+	}
+
+	if err := a.Write(out); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Exported %s", a.Summary())
+}
+
+// importArchive runs `slackbot import <file>`
+func importArchive(args []string) {
+	if len(args) < 1 {
+		log.Fatal("Usage: slackbot import <file>")
+	}
+	if err := db.CheckSchema(); err != nil {
+		log.Fatal(err)
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	a, err := db.ReadArchive(f)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := db.Import(a); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Imported %s", a.Summary())
+}
+
 func startNewRelic() {
 	key := os.Getenv("NEW_RELIC_LICENSE_KEY")
 	agent := gorelic.NewAgent()
@@ -75,16 +240,21 @@ func hookHandler(w http.ResponseWriter, r *http.Request) {
 	com := strings.TrimPrefix(command.Text, command.TriggerWord) // +" ")
 	c := strings.Split(com, " ")
 	command.Robot = c[0]
-	command.Text = strings.Join(c[1:], " ")
+	command.Text, command.DryRun = dryrun.Parse(strings.Join(c[1:], " "))
 
-	robots := getRobots(command.Robot)
-	if len(robots) == 0 {
+	rs := robots.Get(command.Robot)
+	if len(rs) == 0 {
 		msg := fmt.Sprintf("No robot for %s (%s)", command.Robot, command.Text)
 		jsonResp(w, msg)
 		return
 	}
+	if msg := robots.DryRunError(command.Robot, rs, command.DryRun); msg != "" {
+		jsonResp(w, msg)
+		return
+	}
+	db.RecordCommand(command.TeamID, command.UserName, command.ChannelName, command.Robot, command.Text)
 	resp := ""
-	for _, robot := range robots {
+	for _, robot := range rs {
 		resp += fmt.Sprintf("\n%s", robot.Run(&command.Payload))
 	}
 	w.WriteHeader(http.StatusOK)
@@ -109,18 +279,24 @@ func slashCommandHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	command.Robot = command.Command[1:]
+	command.Text, command.DryRun = dryrun.Parse(command.Text)
 
 	if token := os.Getenv(fmt.Sprintf("%s_SLACK_TOKEN", strings.ToUpper(command.Robot))); token != "" && token != command.Token {
 		log.Printf("[DEBUG] Ignoring request from unidentified source: %s - %s", command.Token, r.Host)
 		w.WriteHeader(http.StatusBadRequest)
 	}
-	robots := getRobots(command.Robot)
-	if len(robots) == 0 {
+	rs := robots.Get(command.Robot)
+	if len(rs) == 0 {
 		plainResp(w, "No robot for that command yet :(")
 		return
 	}
+	if msg := robots.DryRunError(command.Robot, rs, command.DryRun); msg != "" {
+		plainResp(w, msg)
+		return
+	}
+	db.RecordCommand(command.TeamID, command.UserName, command.ChannelName, command.Robot, command.Text)
 	resp := ""
-	for _, robot := range robots {
+	for _, robot := range rs {
 		resp += fmt.Sprintf("\n%s", robot.Run(&command.Payload))
 	}
 	plainResp(w, strings.TrimSpace(resp))
@@ -143,6 +319,7 @@ func mvnError(domain string, chanId string, user string, s string) {
 func callbackHandler(w http.ResponseWriter, r *http.Request) {
 	params := r.URL.Query()
 	domain := params.Get("domain")
+	team := params.Get("team")
 	user := params.Get("user")
 	chanId := params.Get("channel")
 
@@ -162,8 +339,8 @@ func callbackHandler(w http.ResponseWriter, r *http.Request) {
 	code := params.Get("code")
 
 	callback := os.Getenv("MAVENLINK_CALLBACK")
-	callback = fmt.Sprintf("%s?domain=%s&user=%s&channel=%s",
-		callback, domain, user, chanId)
+	callback = fmt.Sprintf("%s?domain=%s&team=%s&user=%s&channel=%s",
+		callback, domain, team, user, chanId)
 
 	rp := url.Values{}
 	rp.Set("client_id", os.Getenv("MAVENLINK_APP_ID"))
@@ -198,7 +375,7 @@ func callbackHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = db.SetSetting(user, "MAVENLINK_TOKEN", b.AccessToken)
+	err = db.SetSetting(team, user, "MAVENLINK_TOKEN", b.AccessToken)
 	if err != nil {
 		mvnError(domain, chanId, user, err.Error())
 		return
@@ -252,11 +429,3 @@ func startServer() {
 		log.Fatal("Server start error: ", err)
 	}
 }
-
-func getRobots(command string) []robots.Robot {
-	fmt.Println("Robots:", robots.Robots)
-	if r, ok := robots.Robots[command]; ok {
-		return r
-	}
-	return nil
-}
@@ -1,31 +1,62 @@
 package mavenlink
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/url"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gistia/slackbot/db"
+	"github.com/gistia/slackbot/dryrun"
 	"github.com/gistia/slackbot/utils"
 )
 
 type Mavenlink struct {
-	Token   string
-	Verbose bool
+	Token      string
+	Verbose    bool
+	Team       string
+	User       string
+	RecordUndo bool
+	DryRun     *dryrun.Recorder
 }
 
 func NewMavenlink(token string, verbose bool) *Mavenlink {
 	return &Mavenlink{Token: token, Verbose: verbose}
 }
 
-func NewFor(user string) (*Mavenlink, error) {
-	token, err := db.GetSetting(user, "MAVENLINK_TOKEN")
+func NewFor(team, user string) (*Mavenlink, error) {
+	token, err := db.GetSetting(team, user, "MAVENLINK_TOKEN")
 	if err != nil {
 		return nil, err
 	}
-	return NewMavenlink(token.Value, false), nil
+	if token == nil {
+		return nil, errors.New("No MAVENLINK_TOKEN set for @" + user)
+	}
+	mvn := NewMavenlink(token.Value, false)
+	mvn.Team = team
+	mvn.User = user
+	mvn.RecordUndo = true
+	return mvn, nil
+}
+
+// NewIn returns a client with the MAVENLINK_TOKEN that applies in c, the
+// user's own or the one set for the channel or team, for reading only
+func NewIn(c db.SettingContext) (*Mavenlink, error) {
+	token, err := db.ResolveSetting(c, "MAVENLINK_TOKEN")
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, errors.New("No MAVENLINK_TOKEN set for @" + c.User + " or the team")
+	}
+	mvn := NewMavenlink(token.Value, false)
+	mvn.Team = c.Team
+	mvn.User = c.User
+	return mvn, nil
 }
 
 //---------- Projects
@@ -42,6 +73,7 @@ func (mvn *Mavenlink) CreateProject(p Project) (*Project, error) {
 	}
 	projects := resp.ProjectList()
 	if len(projects) > 0 {
+		mvn.audit("create_project", "workspace "+projects[0].Id, params)
 		return &projects[0], nil
 	}
 	return nil, nil
@@ -95,7 +127,11 @@ func (mvn *Mavenlink) GetStory(id string) (*Story, error) {
 		return nil, err
 	}
 
-	return &r.StoryList()[0], err
+	stories := r.StoryList()
+	if len(stories) < 1 {
+		return nil, errors.New("Mavenlink story " + id + " not found")
+	}
+	return &stories[0], nil
 }
 
 func (mvn *Mavenlink) Stories(projectId string) ([]Story, error) {
@@ -157,6 +193,9 @@ func (mvn *Mavenlink) CreateStory(s Story) (*Story, error) {
 
 	stories := resp.StoryList()
 	if len(stories) > 0 {
+		mvn.audit("create_story", "story "+stories[0].Id, params)
+		mvn.recordUndo("delete_story", stories[0].Id,
+			fmt.Sprintf("delete Mavenlink story %s - %s", stories[0].Id, stories[0].Title), nil)
 		return &stories[0], nil
 	}
 
@@ -173,6 +212,7 @@ func (mvn *Mavenlink) UpdateStory(story Story) (*Story, error) {
 	if err != nil {
 		return nil, err
 	}
+	mvn.audit("update_story", "story "+story.Id, params)
 
 	stories := resp.StoryList()
 	if len(stories) > 0 {
@@ -183,11 +223,26 @@ func (mvn *Mavenlink) UpdateStory(story Story) (*Story, error) {
 }
 
 func (mvn *Mavenlink) SetStoryState(id, state string) (*Story, error) {
+	var prev *Story
+	if mvn.recordsUndo() {
+		var err error
+		prev, err = mvn.GetStory(id)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	params := map[string]string{"story[state]": state}
 	resp, err := mvn.put("stories/"+id, params)
 	if err != nil {
 		return nil, err
 	}
+	mvn.audit("update_story", "story "+id, params)
+	if prev != nil && prev.State != "" && prev.State != state {
+		mvn.recordUndo("set_story_state", id,
+			fmt.Sprintf("set Mavenlink story %s - %s back to %s", id, prev.Title, prev.State),
+			map[string]string{"state": prev.State})
+	}
 
 	stories := resp.StoryList()
 	if len(stories) > 0 {
@@ -197,15 +252,38 @@ func (mvn *Mavenlink) SetStoryState(id, state string) (*Story, error) {
 	return nil, nil
 }
 
+// TimeEntryOptions are the optional details of a time entry. The zero value
+// logs the time as performed today, without notes and with the billable
+// status Mavenlink gives by default
+type TimeEntryOptions struct {
+	Date     time.Time
+	Notes    string
+	Billable *bool
+}
+
 func (mvn *Mavenlink) AddTimeEntry(s *Story, minutes int) (*TimeEntry, error) {
-	date := time.Now().Format("2006-01-02")
+	return mvn.AddTimeEntryWith(s, minutes, TimeEntryOptions{})
+}
+
+// AddTimeEntryWith logs minutes to the story with the given details
+func (mvn *Mavenlink) AddTimeEntryWith(s *Story, minutes int, opts TimeEntryOptions) (*TimeEntry, error) {
+	date := opts.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
 	minStr := strconv.Itoa(minutes)
 	params := map[string]string{
-		"time_entry[date_performed]":  date,
+		"time_entry[date_performed]":  date.Format("2006-01-02"),
 		"time_entry[time_in_minutes]": minStr,
 		"time_entry[story_id]":        s.Id,
 		"time_entry[workspace_id]":    s.WorkspaceId,
 	}
+	if opts.Notes != "" {
+		params["time_entry[notes]"] = opts.Notes
+	}
+	if opts.Billable != nil {
+		params["time_entry[billable]"] = strconv.FormatBool(*opts.Billable)
+	}
 	resp, err := mvn.post("time_entries", params)
 	if err != nil {
 		return nil, err
@@ -213,12 +291,32 @@ func (mvn *Mavenlink) AddTimeEntry(s *Story, minutes int) (*TimeEntry, error) {
 
 	entries := resp.TimeEntryList()
 	if len(entries) > 0 {
+		mvn.audit("add_time_entry", "time entry "+entries[0].ID, params)
+		mvn.recordUndo("delete_time_entry", entries[0].ID,
+			fmt.Sprintf("delete %d minutes logged to Mavenlink story %s - %s",
+				minutes, s.Id, s.Title), nil)
 		return &entries[0], nil
 	}
 
 	return nil, nil
 }
 
+func (mvn *Mavenlink) DeleteTimeEntry(id string) error {
+	if err := mvn.delete("time_entries/" + id); err != nil {
+		return err
+	}
+	mvn.audit("delete_time_entry", "time entry "+id, nil)
+	return nil
+}
+
+func (mvn *Mavenlink) DeleteStory(id string) error {
+	if err := mvn.delete("stories/" + id); err != nil {
+		return err
+	}
+	mvn.audit("delete_story", "story "+id, nil)
+	return nil
+}
+
 //---------- Users
 
 type UsersByName []User
@@ -266,6 +364,31 @@ func (mvn *Mavenlink) GetUsers() ([]User, error) {
 
 //---------- Internals
 
+func (mvn *Mavenlink) audit(action string, target string, params map[string]string) {
+	if mvn.DryRun.Active() {
+		return
+	}
+	details, err := json.Marshal(params)
+	if err != nil {
+		details = []byte(err.Error())
+	}
+	db.RecordChange(mvn.Team, mvn.User, "mavenlink", action, target, string(details))
+}
+
+// recordsUndo tells if changes must be recorded for undo, which isn't the
+// case when reverting a change or on dry runs
+func (mvn *Mavenlink) recordsUndo() bool {
+	return mvn.RecordUndo && !mvn.DryRun.Active()
+}
+
+// recordUndo stores how to revert a change, unless the client is itself
+// reverting one
+func (mvn *Mavenlink) recordUndo(action, target, desc string, payload interface{}) {
+	if mvn.recordsUndo() {
+		db.RecordUndo(mvn.Team, mvn.User, "mavenlink", action, target, desc, payload)
+	}
+}
+
 func (mvn *Mavenlink) makeUrl(uri string) string {
 	return fmt.Sprintf("https://api.mavenlink.com/api/v1/%s.json", uri)
 }
@@ -322,6 +445,10 @@ func (mvn *Mavenlink) performRequest(method string, uri string, params map[strin
 		postParams.Add(k, v)
 	}
 
+	if mvn.DryRun.Active() {
+		return mvn.dryRun(method, uri, params), nil
+	}
+
 	mvnUrl := mvn.makeUrl(uri)
 	fmt.Printf("[mvn] Performing request %s to %s with %+v\n", method, mvnUrl, postParams)
 	json, err := mvn.request(method, mvnUrl, postParams)
@@ -340,3 +467,80 @@ func (mvn *Mavenlink) post(uri string, params map[string]string) (*Response, err
 func (mvn *Mavenlink) put(uri string, params map[string]string) (*Response, error) {
 	return mvn.performRequest("PUT", uri, params)
 }
+
+// delete removes a resource. Mavenlink replies with an empty body on
+// success, so only error responses are parsed
+func (mvn *Mavenlink) delete(uri string) error {
+	if mvn.DryRun.Active() {
+		mvn.dryRun("DELETE", uri, nil)
+		return nil
+	}
+
+	mvnUrl := mvn.makeUrl(uri)
+	fmt.Printf("[mvn] Performing request DELETE to %s\n", mvnUrl)
+	json, err := mvn.request("DELETE", mvnUrl, nil)
+	if err != nil {
+		return err
+	}
+
+	if len(strings.TrimSpace(string(json))) < 1 {
+		return nil
+	}
+
+	_, err = NewFromJson(json)
+	return err
+}
+
+// dryRun records the request instead of sending it, replying with the
+// entity built from its params so callers can carry on
+func (mvn *Mavenlink) dryRun(method, uri string, params map[string]string) *Response {
+	action := map[string]string{
+		"POST":   "create",
+		"PUT":    "update",
+		"DELETE": "delete",
+	}[method]
+	parts := strings.SplitN(uri, "/", 2)
+	id := "new"
+	if len(parts) > 1 {
+		id = parts[1]
+	}
+	entity := map[string]string{
+		"workspaces":   "project",
+		"stories":      "story",
+		"time_entries": "time_entry",
+	}[parts[0]]
+	mvn.DryRun.Record("mavenlink", action+"_"+entity, uri, params)
+
+	resp := &Response{}
+	switch parts[0] {
+	case "workspaces":
+		resp.Projects = map[string]Project{id: {
+			Id:          id,
+			Title:       params["workspace[title]"],
+			Description: params["workspace[description]"],
+		}}
+	case "stories":
+		resp.Stories = map[string]Story{id: {
+			Id:          id,
+			Title:       params["story[title]"],
+			Description: params["story[description]"],
+			ParentId:    params["story[parent_id]"],
+			WorkspaceId: params["story[workspace_id]"],
+			StoryType:   params["story[story_type]"],
+			State:       params["story[state]"],
+		}}
+	case "time_entries":
+		minutes, _ := strconv.Atoi(params["time_entry[time_in_minutes]"])
+		billable, _ := strconv.ParseBool(params["time_entry[billable]"])
+		resp.TimeEntries = map[string]TimeEntry{id: {
+			ID:            id,
+			DatePerformed: params["time_entry[date_performed]"],
+			TimeInMinutes: minutes,
+			Notes:         params["time_entry[notes]"],
+			Billable:      billable,
+			StoryID:       params["time_entry[story_id]"],
+			WorkspaceID:   params["time_entry[workspace_id]"],
+		}}
+	}
+	return resp
+}
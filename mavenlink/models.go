@@ -31,7 +31,7 @@ type TimeEntry struct {
 	ID            string `json:"id"`
 	DatePerformed string `json:"date_performed"`
 	TimeInMinutes int    `json:"time_in_minutes"`
-	Notes         string `json:"id"`
+	Notes         string `json:"notes"`
 	Billable      bool   `json:"billable"`
 	StoryID       string `json:"story_id"`
 	WorkspaceID   string `json:"workspace_id"`
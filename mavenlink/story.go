@@ -1,6 +1,9 @@
 package mavenlink
 
-import "fmt"
+import (
+	"fmt"
+	"regexp"
+)
 
 type Story struct {
 	Id                          string   `json:"id,omitempty"`
@@ -30,6 +33,22 @@ func (mvn *Mavenlink) GetAssignees(s Story) (*Story, error) {
 	return &s, nil
 }
 
+// GetPivotalId returns the id of the Pivotal story tagged in the
+// description like [pvt:123], empty if there's none
+func (s *Story) GetPivotalId() string {
+	m := regexp.MustCompile(`\[pvt:(\d+)\]`).FindStringSubmatch(s.Description)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// Hours returns the billable hours logged to the story and the hours it
+// was estimated to take
+func (s *Story) Hours() (float64, float64) {
+	return float64(s.LoggedBillableTimeInMinutes) / 60, float64(s.TimeEstimateInMinutes) / 60
+}
+
 func (s *Story) URL() string {
 	return fmt.Sprintf(
 		"https://app.mavenlink.com/workspaces/%s/#tracker/%s",
@@ -0,0 +1,193 @@
+package pivotal
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// DefaultPageSize is how many items are asked for in each page of a
+// listing, unless PIVOTAL_PAGE_SIZE says otherwise
+const DefaultPageSize = 100
+
+// Pagination tells where a page is in the whole listing, as reported in
+// the response envelope
+type Pagination struct {
+	Total    int `json:"total"`
+	Limit    int `json:"limit"`
+	Offset   int `json:"offset"`
+	Returned int `json:"returned"`
+}
+
+// envelope wraps responses requested with envelope=true, which carries the
+// pagination of listings along with the data
+type envelope struct {
+	Status     string          `json:"http_status"`
+	Data       json.RawMessage `json:"data"`
+	Pagination *Pagination     `json:"pagination"`
+}
+
+// pageSize returns PIVOTAL_PAGE_SIZE, or DefaultPageSize if unset or invalid
+func pageSize() int {
+	if n, err := strconv.Atoi(os.Getenv("PIVOTAL_PAGE_SIZE")); err == nil && n > 0 {
+		return n
+	}
+	return DefaultPageSize
+}
+
+// maxPages returns PIVOTAL_MAX_PAGES, 0 meaning all pages are read
+func maxPages() int {
+	if n, err := strconv.Atoi(os.Getenv("PIVOTAL_MAX_PAGES")); err == nil && n > 0 {
+		return n
+	}
+	return 0
+}
+
+// Pages reads a listing a page at a time:
+//
+//	pages := pvt.Pages(req)
+//	for pages.Next() {
+//		use(pages.Page().Projects)
+//	}
+//	if err := pages.Err(); err != nil {
+//		...
+//	}
+//
+// Listings Pivotal doesn't paginate come back as a single page. The ones it
+// does are read in pages of the limit the request set, or of the limit the
+// first page came back with.
+type Pages struct {
+	req      Request
+	max      int
+	read     int
+	offset   int
+	done     bool
+	page     *Response
+	err      error
+	lastPage *Pagination
+}
+
+// Pages returns the pages of the listing req asks for, up to MaxPages
+func (pvt *Pivotal) Pages(req Request) *Pages {
+	req.Token = pvt.Token
+	req.Paged = true
+	return &Pages{req: req, max: pvt.MaxPages}
+}
+
+// Next fetches the next page, returning false when there are no more or
+// the request failed
+func (pg *Pages) Next() bool {
+	if pg.done || pg.err != nil {
+		return false
+	}
+	if pg.max > 0 && pg.read >= pg.max {
+		pg.done = true
+		if pg.lastPage != nil && pg.offset < pg.lastPage.Total {
+			log.Printf("pivotal: stopped reading %s after %d pages, %d of %d items read\n",
+				pg.req.Uri, pg.read, pg.offset, pg.lastPage.Total)
+		}
+		return false
+	}
+
+	req := pg.req
+	req.Offset = pg.offset
+	if req.Limit < 1 && pg.lastPage != nil {
+		req.Limit = pg.lastPage.Limit
+	}
+	r, err := req.Send()
+	if err != nil {
+		pg.err = err
+		return false
+	}
+
+	pg.read++
+	pg.page = r
+	pg.lastPage = r.Pagination
+	if r.Pagination == nil || r.Pagination.Returned < 1 {
+		pg.done = true
+		return true
+	}
+
+	pg.offset = r.Pagination.Offset + r.Pagination.Returned
+	if pg.offset >= r.Pagination.Total {
+		pg.done = true
+	}
+	return true
+}
+
+// Page returns the page fetched by the last call to Next
+func (pg *Pages) Page() *Response {
+	return pg.page
+}
+
+// Err returns the error that stopped reading the pages, if any
+func (pg *Pages) Err() error {
+	return pg.err
+}
+
+// StoryIterator streams the stories of a listing one at a time, fetching
+// pages as they're needed
+type StoryIterator struct {
+	pages   *Pages
+	stories []Story
+	story   Story
+}
+
+// IterStories iterates over the stories of a project that match filters,
+// which may be nil, PageSize stories at a time
+func (pvt *Pivotal) IterStories(project string, filters map[string]string) *StoryIterator {
+	return &StoryIterator{pages: pvt.Pages(Request{
+		Type:    "stories",
+		Method:  "GET",
+		Uri:     "projects/" + project + "/stories",
+		Filters: filters,
+		Limit:   pvt.PageSize,
+	})}
+}
+
+// Next moves to the next story, returning false at the end of the listing
+// or when a page couldn't be read
+func (it *StoryIterator) Next() bool {
+	for len(it.stories) < 1 {
+		if !it.pages.Next() {
+			return false
+		}
+		it.stories = it.pages.Page().Stories
+	}
+
+	it.story, it.stories = it.stories[0], it.stories[1:]
+	return true
+}
+
+// Story returns the current story
+func (it *StoryIterator) Story() Story {
+	return it.story
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *StoryIterator) Err() error {
+	return it.pages.Err()
+}
+
+// unwrap returns the data of an enveloped response along with its
+// pagination, or the error Pivotal replied with
+func unwrap(payload []byte) ([]byte, *Pagination, error) {
+	var env envelope
+	if err := json.Unmarshal(payload, &env); err != nil {
+		return nil, nil, err
+	}
+	if env.Data == nil {
+		return nil, nil, errors.New("Unexpected response from Pivotal: " + string(payload))
+	}
+	if !strings.HasPrefix(env.Status, "2") {
+		var e Error
+		if err := json.Unmarshal(env.Data, &e); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, e.err()
+	}
+	return env.Data, env.Pagination, nil
+}
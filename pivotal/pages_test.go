@@ -0,0 +1,123 @@
+package pivotal
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// serveFixtures points the client at a server that replies to every
+// request with the fixture pick returns for it
+func serveFixtures(t *testing.T, pick func(r *http.Request) string) func() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadFile(filepath.Join("fixtures", pick(r)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(b)
+	}))
+
+	base := BaseURL
+	BaseURL = srv.URL
+	return func() {
+		BaseURL = base
+		srv.Close()
+	}
+}
+
+// storyPages serves the three pages of stories by the offset asked for
+func storyPages(t *testing.T, requests *int) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		*requests++
+		switch r.URL.Query().Get("offset") {
+		case "", "0":
+			return "stories_page1.json"
+		case "25":
+			return "stories_page2.json"
+		case "50":
+			return "stories_page3.json"
+		}
+		t.Fatalf("unexpected offset in %s", r.URL)
+		return ""
+	}
+}
+
+func TestFilteredStoriesReadsEveryPage(t *testing.T) {
+	requests := 0
+	defer serveFixtures(t, storyPages(t, &requests))()
+
+	pvt := NewPivotal("token", false)
+	pvt.PageSize = 25
+	stories, err := pvt.FilteredStories("1", map[string]string{"state": "started"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stories) != 59 {
+		t.Errorf("expected 59 stories, got %d", len(stories))
+	}
+	if requests != 3 {
+		t.Errorf("expected 3 requests, got %d", requests)
+	}
+
+	seen := map[int64]bool{}
+	for _, s := range stories {
+		if seen[s.Id] {
+			t.Errorf("story %d read twice", s.Id)
+		}
+		seen[s.Id] = true
+	}
+}
+
+func TestMaxPagesTruncates(t *testing.T) {
+	requests := 0
+	defer serveFixtures(t, storyPages(t, &requests))()
+
+	os.Setenv("PIVOTAL_MAX_PAGES", "2")
+	defer os.Unsetenv("PIVOTAL_MAX_PAGES")
+
+	pvt := NewPivotal("token", false)
+	pvt.PageSize = 25
+	stories, err := pvt.FilteredStories("1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stories) != 50 {
+		t.Errorf("expected 50 stories, got %d", len(stories))
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+}
+
+func TestUnpagedListingIsOnePage(t *testing.T) {
+	requests := 0
+	defer serveFixtures(t, func(r *http.Request) string {
+		requests++
+		return "projects_envelope.json"
+	})()
+
+	projects, err := NewPivotal("token", false).Projects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 7 {
+		t.Errorf("expected 7 projects, got %d", len(projects))
+	}
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+}
+
+func TestEnvelopeErrors(t *testing.T) {
+	defer serveFixtures(t, func(r *http.Request) string {
+		return "error_envelope.json"
+	})()
+
+	stories, err := NewPivotal("token", false).FilteredStories("1", nil)
+	if err == nil {
+		t.Fatalf("expected an error, got %d stories", len(stories))
+	}
+}
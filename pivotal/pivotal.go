@@ -11,15 +11,25 @@ import (
 	"strings"
 
 	"github.com/gistia/slackbot/db"
+	"github.com/gistia/slackbot/dryrun"
 	"github.com/gistia/slackbot/utils"
 )
 
 type Pivotal struct {
-	Token   string
-	Verbose bool
+	Token      string
+	Verbose    bool
+	Team       string
+	User       string
+	RecordUndo bool
+	DryRun     *dryrun.Recorder
+	// PageSize is how many items each page of a listing has and MaxPages
+	// how many pages are read at most, 0 for all of them
+	PageSize int
+	MaxPages int
 }
 
 type Request struct {
+	DryRun               *dryrun.Recorder
 	Type                 string
 	Method               string
 	Uri                  string
@@ -30,6 +40,13 @@ type Request struct {
 	Project              *Project
 	ProjectMembership    *ProjectMembership
 	NewProjectMembership *NewProjectMembership
+	// Fields are sent as they are, empty values included
+	Fields map[string]interface{}
+	// Paged requests ask for the response envelope, reading Limit items
+	// starting at Offset
+	Paged  bool
+	Limit  int
+	Offset int
 }
 
 type Response struct {
@@ -40,6 +57,7 @@ type Response struct {
 	ProjectMembership  ProjectMembership   `json:"project_membership"`
 	Story              Story               `json:"story"`
 	Error              Error               `json:"error"`
+	Pagination         *Pagination         `json:"-"`
 }
 
 type Project struct {
@@ -56,7 +74,7 @@ type Story struct {
 	Kind        string  `json:"kind,omitempty"`
 	Name        string  `json:"name,omitempty"`
 	Description string  `json:"description,omitempty"`
-	Estimate    int     `json:"estimate,omitempty"`
+	Estimate    *int    `json:"estimate,omitempty"`
 	State       string  `json:"current_state,omitempty"`
 	Url         string  `json:"url,omitempty"`
 	Type        string  `json:"story_type,omitempty"`
@@ -97,29 +115,60 @@ type Error struct {
 	GeneralProject string `json:"general_problem"`
 }
 
+// BaseURL is where the Pivotal Tracker API is reached
+var BaseURL = "https://www.pivotaltracker.com/services/v5"
+
 func NewPivotal(token string, verbose bool) *Pivotal {
-	return &Pivotal{Token: token, Verbose: verbose}
+	return &Pivotal{
+		Token:    token,
+		Verbose:  verbose,
+		PageSize: pageSize(),
+		MaxPages: maxPages(),
+	}
+}
+
+func NewFor(team, user string) (*Pivotal, error) {
+	token, err := db.GetSetting(team, user, "PIVOTAL_TOKEN")
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, errors.New("No PIVOTAL_TOKEN set for @" + user)
+	}
+	pvt := NewPivotal(token.Value, false)
+	pvt.Team = team
+	pvt.User = user
+	pvt.RecordUndo = true
+	return pvt, nil
 }
 
-func NewFor(user string) (*Pivotal, error) {
-	token, err := db.GetSetting(user, "PIVOTAL_TOKEN")
+// NewIn returns a client with the PIVOTAL_TOKEN that applies in c, the
+// user's own or the one set for the channel or team, for reading only
+func NewIn(c db.SettingContext) (*Pivotal, error) {
+	token, err := db.ResolveSetting(c, "PIVOTAL_TOKEN")
 	if err != nil {
 		return nil, err
 	}
-	return NewPivotal(token.Value, false), nil
+	if token == nil {
+		return nil, errors.New("No PIVOTAL_TOKEN set for @" + c.User + " or the team")
+	}
+	pvt := NewPivotal(token.Value, false)
+	pvt.Team = c.Team
+	pvt.User = c.User
+	return pvt, nil
 }
 
 //---------- Projects
 
 func (pvt *Pivotal) Projects() ([]Project, error) {
-	req := Request{Token: pvt.Token, Type: "projects", Method: "GET"}
+	pages := pvt.Pages(Request{Type: "projects", Method: "GET"})
 
-	r, err := req.Send()
-	if err != nil {
-		return nil, err
+	projects := []Project{}
+	for pages.Next() {
+		projects = append(projects, pages.Page().Projects...)
 	}
 
-	return r.Projects, nil
+	return projects, pages.Err()
 }
 
 func (pvt *Pivotal) GetProject(id string) (*Project, error) {
@@ -142,6 +191,7 @@ func (pvt *Pivotal) GetProject(id string) (*Project, error) {
 func (pvt *Pivotal) CreateProject(project Project) (*Project, error) {
 	fmt.Println("Project", project)
 	req := Request{
+		DryRun:  pvt.DryRun,
 		Token:   pvt.Token,
 		Type:    "project",
 		Method:  "POST",
@@ -154,11 +204,13 @@ func (pvt *Pivotal) CreateProject(project Project) (*Project, error) {
 		return nil, err
 	}
 	fmt.Println("Project:", r.Project)
+	pvt.audit("create_project", fmt.Sprintf("project %d", r.Project.Id), project)
 	return &r.Project, nil
 }
 
 func (pvt *Pivotal) UpdateProject(project Project) (*Project, error) {
 	req := Request{
+		DryRun:  pvt.DryRun,
 		Token:   pvt.Token,
 		Type:    "project",
 		Method:  "PUT",
@@ -170,6 +222,7 @@ func (pvt *Pivotal) UpdateProject(project Project) (*Project, error) {
 	if err != nil {
 		return nil, err
 	}
+	pvt.audit("update_project", fmt.Sprintf("project %d", project.Id), project)
 	return &r.Project, nil
 }
 
@@ -193,54 +246,28 @@ func (pvt *Pivotal) GetStory(id string) (*Story, error) {
 }
 
 func (pvt *Pivotal) Stories(p string) ([]Story, error) {
-	uri := fmt.Sprintf("projects/%s/stories", p)
-	req := Request{Token: pvt.Token, Type: "stories", Method: "GET", Uri: uri}
-
-	r, err := req.Send()
-	if err != nil {
-		return nil, err
-	}
-
-	return r.Stories, nil
+	return pvt.FilteredStories(p, nil)
 }
 
+// FilteredStories returns all the stories of the project that match the
+// filters, reading every page of them. Use IterStories to go through large
+// projects without holding all their stories
 func (pvt *Pivotal) FilteredStories(p string, filters map[string]string) ([]Story, error) {
-	uri := fmt.Sprintf("projects/%s/stories", p)
-	req := Request{
-		Token:   pvt.Token,
-		Type:    "stories",
-		Method:  "GET",
-		Uri:     uri,
-		Filters: filters,
-	}
+	it := pvt.IterStories(p, filters)
 
-	r, err := req.Send()
-	if err != nil {
-		return nil, err
+	stories := []Story{}
+	for it.Next() {
+		stories = append(stories, it.Story())
 	}
 
-	return r.Stories, nil
+	return stories, it.Err()
 }
 
 func (pvt *Pivotal) GetUnassignedStories(pid string) ([]Story, error) {
-	uri := fmt.Sprintf("projects/%s/stories", pid)
-	req := Request{
-		Token:  pvt.Token,
-		Type:   "stories",
-		Method: "GET",
-		Uri:    uri,
-		Filters: map[string]string{
-			"owned_by": `""`,
-			"type":     "feature,bug,chore",
-		},
-	}
-
-	r, err := req.Send()
-	if err != nil {
-		return nil, err
-	}
-
-	return r.Stories, nil
+	return pvt.FilteredStories(pid, map[string]string{
+		"owned_by": `""`,
+		"type":     "feature,bug,chore",
+	})
 }
 
 func (pvt *Pivotal) SetStoryState(id string, state string) (*Story, error) {
@@ -270,12 +297,22 @@ func (pvt *Pivotal) EstimateStory(id string, estimate int) (*Story, error) {
 		return nil, err
 	}
 
-	story := Story{Id: nid, Estimate: estimate}
+	story := Story{Id: nid, Estimate: &estimate}
 	return pvt.UpdateStory(story)
 }
 
 func (pvt *Pivotal) UpdateStory(story Story) (*Story, error) {
+	var prev *Story
+	if pvt.recordsUndo() {
+		var err error
+		prev, err = pvt.GetStory(story.GetStringId())
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	req := Request{
+		DryRun: pvt.DryRun,
 		Token:  pvt.Token,
 		Type:   "story",
 		Method: "PUT",
@@ -289,11 +326,98 @@ func (pvt *Pivotal) UpdateStory(story Story) (*Story, error) {
 	if err != nil {
 		return nil, err
 	}
+	pvt.audit("update_story", fmt.Sprintf("story %d", story.Id), story)
+	if prev != nil {
+		pvt.recordStoryUndo(story, *prev)
+	}
+	return &r.Story, nil
+}
+
+// recordStoryUndo saves the previous values of the fields changed
+// by an update, so they can be restored with RestoreStory. Empty values
+// are kept, so a first estimate, owner or description can be undone too
+func (pvt *Pivotal) recordStoryUndo(changes Story, prev Story) {
+	undo := map[string]interface{}{}
+	desc := []string{}
+	if changes.Name != "" {
+		undo["name"] = prev.Name
+		desc = append(desc, "name")
+	}
+	if changes.Description != "" {
+		undo["description"] = prev.Description
+		desc = append(desc, "description")
+	}
+	if changes.State != "" {
+		undo["current_state"] = prev.State
+		desc = append(desc, "state to "+prev.State)
+	}
+	if changes.Estimate != nil {
+		undo["estimate"] = prev.Estimate
+		if prev.Estimate == nil {
+			desc = append(desc, "estimate to none")
+		} else {
+			desc = append(desc, fmt.Sprintf("estimate to %d", *prev.Estimate))
+		}
+	}
+	if changes.OwnerIds != nil {
+		owners := prev.OwnerIds
+		if owners == nil {
+			owners = []int64{}
+		}
+		undo["owner_ids"] = owners
+		desc = append(desc, "owners")
+	}
+
+	if len(desc) < 1 {
+		return
+	}
+
+	pvt.recordUndo("update_story", prev.GetStringId(),
+		fmt.Sprintf("restore Pivotal story %d - %s %s",
+			prev.Id, prev.Name, strings.Join(desc, ", ")), undo)
+}
+
+// RestoreStory sets the story fields to the given values, which unlike
+// UpdateStory's can be empty to clear a field. It's how undo reverts
+// updates, so it records no undo itself
+func (pvt *Pivotal) RestoreStory(id string, fields map[string]interface{}) (*Story, error) {
+	req := Request{
+		DryRun: pvt.DryRun,
+		Token:  pvt.Token,
+		Type:   "story",
+		Method: "PUT",
+		Uri:    fmt.Sprintf("stories/%s", id),
+		Fields: fields,
+	}
+
+	r, err := req.Send()
+	if err != nil {
+		return nil, err
+	}
+	pvt.audit("update_story", "story "+id, fields)
 	return &r.Story, nil
 }
 
+func (pvt *Pivotal) DeleteStory(id string) error {
+	req := Request{
+		DryRun: pvt.DryRun,
+		Token:  pvt.Token,
+		Type:   "story",
+		Method: "DELETE",
+		Uri:    fmt.Sprintf("stories/%s", id),
+	}
+
+	_, err := req.Send()
+	if err != nil {
+		return err
+	}
+	pvt.audit("delete_story", "story "+id, nil)
+	return nil
+}
+
 func (pvt *Pivotal) CreateStory(story Story) (*Story, error) {
 	req := Request{
+		DryRun: pvt.DryRun,
 		Token:  pvt.Token,
 		Type:   "story",
 		Method: "POST",
@@ -305,12 +429,28 @@ func (pvt *Pivotal) CreateStory(story Story) (*Story, error) {
 	if err != nil {
 		return nil, err
 	}
+	pvt.audit("create_story", fmt.Sprintf("story %d", r.Story.Id), story)
+	pvt.recordUndo("delete_story", r.Story.GetStringId(),
+		fmt.Sprintf("delete Pivotal story %d - %s", r.Story.Id, r.Story.Name), nil)
 	return &r.Story, nil
 }
 
+// GetMavenlinkId returns the id of the Mavenlink story tagged in the
+// description like [mvn:123], empty if there's none
 func (s *Story) GetMavenlinkId() string {
-	reg := regexp.MustCompile(`\[mvn:(\d+)\]`)
-	return reg.ReplaceAllString(s.Description, "${1}")
+	m := regexp.MustCompile(`\[mvn:(\d+)\]`).FindStringSubmatch(s.Description)
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// URL returns the link to the story, which isn't always in the response
+func (s *Story) URL() string {
+	if s.Url != "" {
+		return s.Url
+	}
+	return fmt.Sprintf("https://www.pivotaltracker.com/story/show/%d", s.Id)
 }
 
 func (s *Story) GetStringId() string {
@@ -321,6 +461,7 @@ func (s *Story) GetStringId() string {
 
 func (pvt *Pivotal) CreateProjectMembership(projectId string, personId int64, role string) (*ProjectMembership, error) {
 	req := Request{
+		DryRun:               pvt.DryRun,
 		Token:                pvt.Token,
 		Type:                 "project_membership",
 		Method:               "POST",
@@ -332,30 +473,54 @@ func (pvt *Pivotal) CreateProjectMembership(projectId string, personId int64, ro
 	if err != nil {
 		return nil, err
 	}
+	pvt.audit("create_membership", "project "+projectId, req.NewProjectMembership)
 	return &r.ProjectMembership, nil
 }
 
 func (pvt *Pivotal) GetProjectMemberships(projectId string) ([]ProjectMembership, error) {
-	uri := fmt.Sprintf("projects/%s/memberships", projectId)
-	req := Request{
-		Token:  pvt.Token,
+	pages := pvt.Pages(Request{
 		Type:   "project_memberships",
 		Method: "GET",
-		Uri:    uri,
-	}
+		Uri:    fmt.Sprintf("projects/%s/memberships", projectId),
+	})
 
-	r, err := req.Send()
-	if err != nil {
-		return nil, err
+	memberships := []ProjectMembership{}
+	for pages.Next() {
+		memberships = append(memberships, pages.Page().ProjectMemberships...)
 	}
 
-	return r.ProjectMemberships, nil
+	return memberships, pages.Err()
 }
 
 //---------- Internals
 
+// recordsUndo tells if changes must be recorded for undo, which isn't the
+// case when reverting a change or on dry runs
+func (pvt *Pivotal) recordsUndo() bool {
+	return pvt.RecordUndo && !pvt.DryRun.Active()
+}
+
+// recordUndo stores how to revert a change, unless the client is itself
+// reverting one
+func (pvt *Pivotal) recordUndo(action, target, desc string, payload interface{}) {
+	if pvt.recordsUndo() {
+		db.RecordUndo(pvt.Team, pvt.User, "pivotal", action, target, desc, payload)
+	}
+}
+
+func (pvt *Pivotal) audit(action string, target string, src interface{}) {
+	if pvt.DryRun.Active() {
+		return
+	}
+	details, err := json.Marshal(src)
+	if err != nil {
+		details = []byte(err.Error())
+	}
+	db.RecordChange(pvt.Team, pvt.User, "pivotal", action, target, string(details))
+}
+
 func (r *Request) request(method string, uri string, data url.Values) ([]byte, error) {
-	url := fmt.Sprintf("https://www.pivotaltracker.com/services/v5/%s", uri)
+	url := BaseURL + "/" + uri
 
 	fmt.Println("URL:", url)
 
@@ -364,17 +529,26 @@ func (r *Request) request(method string, uri string, data url.Values) ([]byte, e
 }
 
 func (r *Request) appendFilters(uri string) string {
+	query := url.Values{}
 	if r.Filters != nil {
-		uri += "?filter="
 		filters := ""
 		for k := range r.Filters {
 			v := r.Filters[k]
 			filters += k + ":" + v + " "
 		}
-
-		uri += url.QueryEscape(filters)
+		query.Set("filter", filters)
+	}
+	if r.Paged {
+		query.Set("envelope", "true")
+		if r.Limit > 0 {
+			query.Set("limit", strconv.Itoa(r.Limit))
+			query.Set("offset", strconv.Itoa(r.Offset))
+		}
 	}
 
+	if len(query) > 0 {
+		uri += "?" + query.Encode()
+	}
 	return uri
 }
 
@@ -398,13 +572,20 @@ func (r *Request) Send() (*Response, error) {
 	if r.Project != nil {
 		src = r.Project
 	}
+	if r.Fields != nil {
+		src = r.Fields
+	}
+
+	if r.DryRun.Active() && r.Method != "GET" {
+		return r.dryRun(src), nil
+	}
 
 	if src != nil {
 		data, err := json.Marshal(src)
 		if err != nil {
 			return nil, err
 		}
-		reqUrl := fmt.Sprintf("https://www.pivotaltracker.com/services/v5/%s", uri)
+		reqUrl := BaseURL + "/" + uri
 		reqUrl = r.appendFilters(reqUrl)
 		headers := map[string]string{
 			"X-TrackerToken": r.Token,
@@ -428,7 +609,18 @@ func (r *Request) Send() (*Response, error) {
 	}
 
 	fmt.Println("Payload:", string(payload))
+	var pagination *Pagination
+	if r.Paged {
+		payload, pagination, err = unwrap(payload)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	wrapped := string(payload)
+	if strings.TrimSpace(wrapped) == "" {
+		return &Response{}, nil
+	}
 
 	if strings.Contains(wrapped, "\"kind\":\"error\"") {
 		wrapped = fmt.Sprintf("{\"error\":%s}", wrapped)
@@ -436,15 +628,7 @@ func (r *Request) Send() (*Response, error) {
 		if err != nil {
 			return nil, err
 		}
-		pvtError := resp.Error
-		msg := fmt.Sprintf("%s - %s\n", pvtError.Code, pvtError.Error)
-		if pvtError.GeneralProject != "" {
-			msg += fmt.Sprintf("Details: %s\n", pvtError.GeneralProject)
-		}
-		if pvtError.PossibleFix != "" {
-			msg += fmt.Sprintf("Possible fix: %s\n", pvtError.PossibleFix)
-		}
-		return nil, errors.New(msg)
+		return nil, resp.Error.err()
 	}
 
 	if wrapped != "" {
@@ -452,7 +636,23 @@ func (r *Request) Send() (*Response, error) {
 	}
 	fmt.Println("Wrapped:", string(wrapped))
 	resp, err := NewFromJson([]byte(wrapped))
-	return resp, err
+	if err != nil {
+		return nil, err
+	}
+	resp.Pagination = pagination
+	return resp, nil
+}
+
+// err turns the error Pivotal replied with into an error
+func (e Error) err() error {
+	msg := fmt.Sprintf("%s - %s\n", e.Code, e.Error)
+	if e.GeneralProject != "" {
+		msg += fmt.Sprintf("Details: %s\n", e.GeneralProject)
+	}
+	if e.PossibleFix != "" {
+		msg += fmt.Sprintf("Possible fix: %s\n", e.PossibleFix)
+	}
+	return errors.New(msg)
 }
 
 func NewFromJson(jsonData []byte) (*Response, error) {
@@ -470,3 +670,27 @@ func NewFromJson(jsonData []byte) (*Response, error) {
 
 	return b, err
 }
+
+// dryRun records the request instead of sending it, replying with the
+// entity that was sent so callers can carry on
+func (r *Request) dryRun(src interface{}) *Response {
+	action := map[string]string{
+		"POST":   "create",
+		"PUT":    "update",
+		"DELETE": "delete",
+	}[r.Method]
+	r.DryRun.Record("pivotal", action+"_"+r.Type, r.Uri, src)
+
+	resp := &Response{}
+	if r.Story != nil {
+		resp.Story = *r.Story
+	}
+	if r.Project != nil {
+		resp.Project = *r.Project
+	}
+	if r.NewProjectMembership != nil {
+		resp.ProjectMembership.Person.Id = r.NewProjectMembership.PersonId
+		resp.ProjectMembership.Role = r.NewProjectMembership.Role
+	}
+	return resp
+}
@@ -0,0 +1,73 @@
+package admin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gistia/slackbot/db"
+	"github.com/gistia/slackbot/robots"
+	"github.com/gistia/slackbot/utils"
+	"github.com/nlopes/slack"
+)
+
+type bot struct {
+	handler utils.SlackHandler
+}
+
+func init() {
+	handler := utils.NewSlackHandler("Admin", ":key:")
+	s := &bot{handler: handler}
+	robots.RegisterRobot("admin", s)
+}
+
+func (r bot) Run(p *robots.Payload) string {
+	go r.DeferredAction(p)
+	return ""
+}
+
+func (r bot) DeferredAction(p *robots.Payload) {
+	if !utils.IsAdmin(p.TeamID, p.UserID) {
+		r.handler.Send(p, "Only admins can use this command.")
+		return
+	}
+
+	ch := utils.NewCmdHandler(p, r.handler, "admin")
+	ch.Handle("export", r.export)
+	ch.Process(p.Text)
+}
+
+// export uploads an archive of the bot data to the channel. Since it's
+// posted to Slack, secret settings are only included with --secrets
+func (r bot) export(p *robots.Payload, cmd utils.Command) error {
+	a, err := db.Export(cmd.Flag("secrets"))
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	if err := a.Write(&b); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("slackbot-%s.json", a.CreatedAt.Format("20060102-150405"))
+	_, err = utils.SlackAPI().UploadFile(slack.FileUploadParameters{
+		Content:  b.String(),
+		Filetype: "json",
+		Filename: name,
+		Title:    "Slackbot export " + a.CreatedAt.Format(time.RFC822),
+		Channels: []string{p.ChannelID},
+	})
+	if err != nil {
+		return errors.New("Error uploading the export: " + err.Error())
+	}
+
+	r.handler.Send(p, fmt.Sprintf("Exported %s.", a.Summary()))
+	return nil
+}
+
+func (r bot) Description() (description string) {
+	return "Admin bot\n\tUsage: !admin export [--secrets]\n" +
+		"\tRestricted to the users in ADMIN_USERS\n"
+}
@@ -0,0 +1,132 @@
+package audit
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gistia/slackbot/db"
+	"github.com/gistia/slackbot/robots"
+	"github.com/gistia/slackbot/utils"
+)
+
+type bot struct {
+	handler utils.SlackHandler
+}
+
+func init() {
+	handler := utils.NewSlackHandler("Audit", ":mag:")
+	s := &bot{handler: handler}
+	robots.RegisterRobot("audit", s)
+}
+
+func (r bot) Run(p *robots.Payload) string {
+	go r.DeferredAction(p)
+	return ""
+}
+
+func (r bot) DeferredAction(p *robots.Payload) {
+	text := p.Text
+	if strings.Contains(utils.NewCommand(text).Command, ":") {
+		text = "list " + text
+	}
+
+	ch := utils.NewCmdHandler(p, r.handler, "audit")
+	ch.Handle("list", r.list)
+	ch.HandleDefault(r.list)
+	ch.Process(text)
+}
+
+func (r bot) list(p *robots.Payload, cmd utils.Command) error {
+	user, err := auditedUser(p, cmd)
+	if err != nil {
+		return err
+	}
+
+	filter := db.AuditFilter{
+		TeamID: p.TeamID,
+		User:   user,
+		Robot:  cmd.Param("robot"),
+	}
+
+	if from := cmd.Param("from"); from != "" {
+		t, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			return err
+		}
+		filter.From = &t
+	}
+
+	if to := cmd.Param("to"); to != "" {
+		t, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			return err
+		}
+		// includes the whole day
+		t = t.AddDate(0, 0, 1)
+		filter.To = &t
+	}
+
+	if limit := cmd.Param("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return err
+		}
+		filter.Limit = n
+	}
+
+	events, err := db.GetAuditEvents(filter)
+	if err != nil {
+		return err
+	}
+
+	if len(events) < 1 {
+		r.handler.Send(p, "No audit events found")
+		return nil
+	}
+
+	s := "Audit events, most recent first:\n"
+	for _, e := range events {
+		when := ""
+		if e.CreatedAt != nil {
+			when = e.CreatedAt.Format("2006-01-02 15:04")
+		}
+
+		if e.Kind == "command" {
+			s += fmt.Sprintf("%s *%s* ran `%s %s`", when, e.User, e.Robot, e.Details)
+			if e.Channel != "" {
+				s += " in #" + e.Channel
+			}
+			s += "\n"
+			continue
+		}
+
+		s += fmt.Sprintf("%s *%s* %s `%s` on %s %s\n",
+			when, e.User, e.Robot, e.Action, e.Target, e.Details)
+	}
+
+	r.handler.Send(p, s)
+	return nil
+}
+
+// auditedUser returns whose events to list, the user running the command
+// unless an admin asks for another user's, or for everyone's with user:all
+func auditedUser(p *robots.Payload, cmd utils.Command) (string, error) {
+	user := strings.TrimPrefix(cmd.Param("user"), "@")
+	if user == "" || user == p.UserName {
+		return p.UserName, nil
+	}
+	if !utils.IsAdmin(p.TeamID, p.UserID) {
+		return "", errors.New("Only admins can see the events of other users")
+	}
+	if user == "all" {
+		return "", nil
+	}
+	return user, nil
+}
+
+func (r bot) Description() (description string) {
+	return "Audit bot\n\tUsage: !audit [user:<name>|all] [robot:<name>] [from:<yyyy-mm-dd>] [to:<yyyy-mm-dd>]\n"
+}
@@ -3,10 +3,14 @@ package github
 import (
 	"errors"
 	"fmt"
+	"log"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gistia/slackbot/db"
+	"github.com/gistia/slackbot/listener"
 	"github.com/gistia/slackbot/robots"
 	"github.com/gistia/slackbot/utils"
 	"github.com/google/go-github/github"
@@ -22,6 +26,19 @@ func init() {
 	s := &bot{handler: handler}
 	robots.RegisterRobot("github", s)
 	robots.RegisterRobot("gh", s)
+
+	listener.Register(listener.Listener{
+		Name:     "github.refs",
+		Pattern:  regexp.MustCompile(`(?:^|\s)#(\d+)\b`),
+		Cooldown: time.Minute,
+		Run:      s.references,
+	})
+	listener.Register(listener.Listener{
+		Name:     "github.links",
+		Pattern:  pullLinks,
+		Cooldown: 10 * time.Second,
+		Run:      s.unfurl,
+	})
 }
 
 func (r bot) Run(p *robots.Payload) string {
@@ -41,7 +58,7 @@ func (r bot) DeferredAction(p *robots.Payload) {
 }
 
 func (r bot) auth(p *robots.Payload, cmd utils.Command) error {
-	s, err := db.GetSetting(p.UserName, "GITHUB_TOKEN")
+	s, err := db.GetSetting(p.TeamID, p.UserName, "GITHUB_TOKEN")
 	if err != nil {
 		return err
 	}
@@ -71,7 +88,7 @@ func (r bot) teamInfo(p *robots.Payload, cmd utils.Command) error {
 		return errors.New("Missing team name. Use `!github teaminfo <team>`")
 	}
 
-	client, err := r.getClient(p.UserName)
+	client, err := r.getClient(p)
 	if err != nil {
 		return err
 	}
@@ -102,10 +119,16 @@ func (r bot) teamInfo(p *robots.Payload, cmd utils.Command) error {
 }
 
 func (r bot) teams(p *robots.Payload, cmd utils.Command) error {
-	org := cmd.Arg(0)
+	org, err := utils.ArgOrSetting(p, cmd, 0, "GITHUB_ORG")
+	if err != nil {
+		return err
+	}
+	if org == "" {
+		return errors.New("Missing organization. Use `!gh teams <org>` or set GITHUB_ORG with `!store set GITHUB_ORG=<org> --team`")
+	}
 	opt := &github.ListOptions{}
 
-	client, err := r.getClient(p.UserName)
+	client, err := r.getClient(p)
 	if err != nil {
 		return err
 	}
@@ -134,7 +157,7 @@ func (r bot) addToTeam(p *robots.Payload, cmd utils.Command) error {
 		return errors.New("Missing team name. Use `!github addtoteam <user> <team>`")
 	}
 	// role := "member"
-	client, err := r.getClient(p.UserName)
+	client, err := r.getClient(p)
 	if err != nil {
 		return err
 	}
@@ -147,6 +170,7 @@ func (r bot) addToTeam(p *robots.Payload, cmd utils.Command) error {
 	if err != nil {
 		return err
 	}
+	db.RecordChange(p.TeamID, p.UserName, "github", "add_team_member", "team "+team, user)
 
 	r.handler.Send(p, "User *"+user+"* added to team.")
 	return nil
@@ -161,7 +185,7 @@ func (r bot) pullRequests(p *robots.Payload, cmd utils.Command) error {
 	parts := strings.Split(repo, "/")
 	owner := parts[0]
 	name := parts[1]
-	client, err := r.getClient(p.UserName)
+	client, err := r.getClient(p)
 	if err != nil {
 		return err
 	}
@@ -193,20 +217,88 @@ func (r bot) pullRequests(p *robots.Payload, cmd utils.Command) error {
 	return nil
 }
 
-func (r bot) getClient(user string) (*github.Client, error) {
-	token, err := db.GetSetting(user, "GITHUB_TOKEN")
+// references links the issues and pull requests mentioned like #123 in
+// channels with a GITHUB_REPO setting, using the token of who mentioned them
+// or the team's
+func (r bot) references(p *robots.Payload, rep listener.Replier, matches [][]string) error {
+	repo, err := db.ResolveSetting(utils.SettingContext(p), "GITHUB_REPO")
+	if err != nil || repo == nil {
+		return err
+	}
+	parts := strings.Split(repo.Value, "/")
+	if len(parts) != 2 {
+		return errors.New("Invalid GITHUB_REPO *" + repo.Value + "*, use `owner/name`")
+	}
+
+	client, err := r.getClientIn(utils.SettingContext(p))
+	if err != nil {
+		// not everyone talking in the channel has a token
+		return nil
+	}
+
+	atts := []robots.Attachment{}
+	seen := map[int]bool{}
+	for _, m := range matches {
+		number, err := strconv.Atoi(m[1])
+		if err != nil || seen[number] {
+			continue
+		}
+		seen[number] = true
+
+		issue, _, err := client.Issues.Get(parts[0], parts[1], number)
+		if err != nil {
+			log.Printf("Error getting %s#%d: %s\n", repo.Value, number, err)
+			continue
+		}
+
+		kind := "Issue"
+		if issue.PullRequestLinks != nil {
+			kind = "Pull request"
+		}
+		atts = append(atts, utils.FmtAttachment(
+			fmt.Sprintf("%s #%d - %s", kind, *issue.Number, *issue.Title),
+			fmt.Sprintf("#%d %s", *issue.Number, *issue.Title),
+			*issue.HTMLURL,
+			fmt.Sprintf("%s %s by %s", kind, *issue.State, *issue.User.Login)))
+	}
+
+	if len(atts) > 0 {
+		r.handler.SendWithAttachments(p, "", atts)
+	}
+	return nil
+}
+
+func (r bot) getClient(p *robots.Payload) (*github.Client, error) {
+	token, err := db.GetSetting(p.TeamID, p.UserName, "GITHUB_TOKEN")
 	if err != nil {
 		return nil, err
 	}
 	if token == nil {
 		return nil, errors.New("Missing `GITHUB_TOKEN`. Run `!gh auth` for more info.")
 	}
+	return newClient(token.Value), nil
+}
+
+// getClientIn returns a client with the GITHUB_TOKEN that applies in c, the
+// user's own or the one set for the channel or team
+func (r bot) getClientIn(c db.SettingContext) (*github.Client, error) {
+	token, err := db.ResolveSetting(c, "GITHUB_TOKEN")
+	if err != nil {
+		return nil, err
+	}
+	if token == nil {
+		return nil, errors.New("Missing `GITHUB_TOKEN`. Run `!gh auth` for more info.")
+	}
+	return newClient(token.Value), nil
+}
+
+func newClient(token string) *github.Client {
 	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: token.Value},
+		&oauth2.Token{AccessToken: token},
 	)
 	tc := oauth2.NewClient(oauth2.NoContext, ts)
 
-	return github.NewClient(tc), nil
+	return github.NewClient(tc)
 }
 
 func (r bot) Description() (description string) {
@@ -0,0 +1,59 @@
+package github
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+
+	"github.com/gistia/slackbot/listener"
+	"github.com/gistia/slackbot/robots"
+	"github.com/gistia/slackbot/utils"
+)
+
+// pullLinks matches links to pull requests, capturing the owner, the
+// repository and the number
+var pullLinks = regexp.MustCompile(`github\.com/([\w.-]+)/([\w.-]+)/pull/(\d+)`)
+
+// unfurl replies to links to pull requests with a card for each, read with
+// the token of who posted them or the team's
+func (r bot) unfurl(p *robots.Payload, rep listener.Replier, matches [][]string) error {
+	client, err := r.getClientIn(utils.SettingContext(p))
+	if err != nil {
+		// nobody to read the pull requests as
+		return nil
+	}
+
+	atts := []robots.Attachment{}
+	seen := map[string]bool{}
+	for _, m := range matches {
+		if seen[m[0]] {
+			continue
+		}
+		seen[m[0]] = true
+
+		number, _ := strconv.Atoi(m[3])
+		pr, _, err := client.PullRequests.Get(m[1], m[2], number)
+		if err != nil {
+			log.Printf("Error getting %s/%s#%d: %s\n", m[1], m[2], number, err)
+			continue
+		}
+
+		state := *pr.State
+		if pr.Merged != nil && *pr.Merged {
+			state = "merged"
+		}
+		text := fmt.Sprintf("%s by %s", state, *pr.User.Login)
+		if pr.Commits != nil && pr.Additions != nil && pr.Deletions != nil {
+			text += fmt.Sprintf(", %d commits, +%d -%d", *pr.Commits, *pr.Additions, *pr.Deletions)
+		}
+
+		title := fmt.Sprintf("%s/%s#%d %s", m[1], m[2], *pr.Number, *pr.Title)
+		atts = append(atts, utils.FmtAttachment(title, title, *pr.HTMLURL, text))
+	}
+
+	if len(atts) > 0 {
+		r.handler.SendWithAttachments(p, "", atts)
+	}
+	return nil
+}
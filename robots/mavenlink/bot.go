@@ -1,13 +1,13 @@
 package robots
 
 import (
-	"errors"
 	"fmt"
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
-	"github.com/gistia/slackbot/db"
+	"github.com/gistia/slackbot/listener"
 	"github.com/gistia/slackbot/mavenlink"
 	"github.com/gistia/slackbot/robots"
 	"github.com/gistia/slackbot/utils"
@@ -21,6 +21,13 @@ func init() {
 	handler := utils.NewSlackHandler("Mavenlink", ":chart_with_upwards_trend:")
 	s := &bot{handler: handler}
 	robots.RegisterRobot("mvn", s)
+
+	listener.Register(listener.Listener{
+		Name:     "mavenlink.links",
+		Pattern:  storyLinks,
+		Cooldown: 10 * time.Second,
+		Run:      s.unfurl,
+	})
 }
 
 func (r bot) Run(p *robots.Payload) (slashCommandImmediateReturn string) {
@@ -38,7 +45,7 @@ func (r bot) DeferredAction(p *robots.Payload) {
 }
 
 func (r bot) users(p *robots.Payload, cmd utils.Command) error {
-	mvn, err := conn(p.UserName)
+	mvn, err := conn(p)
 	if err != nil {
 		return err
 	}
@@ -63,7 +70,7 @@ func (r bot) sendProjects(payload *robots.Payload, cmd utils.Command) error {
 
 	term := cmd.Arg(0)
 
-	mvn, err := conn(payload.UserName)
+	mvn, err := conn(payload)
 	if err != nil {
 		return err
 	}
@@ -75,7 +82,7 @@ func (r bot) sendProjects(payload *robots.Payload, cmd utils.Command) error {
 		ps, err = mvn.SearchProject(term)
 	} else {
 		s += ":\n"
-		fmt.Println("Retrieving projects...\n")
+		fmt.Println("Retrieving projects...")
 		ps, err = mvn.Projects()
 	}
 
@@ -88,9 +95,12 @@ func (r bot) sendProjects(payload *robots.Payload, cmd utils.Command) error {
 }
 
 func (r bot) sendStories(payload *robots.Payload, cmd utils.Command) error {
-	term := cmd.Arg(0)
+	term, err := utils.ArgOrSetting(payload, cmd, 0, "MAVENLINK_WORKSPACE")
+	if err != nil {
+		return err
+	}
 	parent := cmd.Param("parent")
-	mvn, err := conn(payload.UserName)
+	mvn, err := conn(payload)
 	if err != nil {
 		return err
 	}
@@ -147,7 +157,7 @@ func (r bot) sendStories(payload *robots.Payload, cmd utils.Command) error {
 }
 
 func (r bot) getProject(payload *robots.Payload, term string) ([]mavenlink.Project, error) {
-	mvn, err := conn(payload.UserName)
+	mvn, err := conn(payload)
 	if err != nil {
 		return nil, err
 	}
@@ -196,8 +206,8 @@ func (r bot) sendAuth(p *robots.Payload, cmd utils.Command) error {
 	params.Add("response_type", "code")
 	params.Add("client_id", appId)
 	params.Add("redirect_uri",
-		fmt.Sprintf("%s?domain=%s&user=%s&channel=%s",
-			callback, p.TeamDomain, p.UserName, p.ChannelID))
+		fmt.Sprintf("%s?domain=%s&team=%s&user=%s&channel=%s",
+			callback, p.TeamDomain, p.TeamID, p.UserName, p.ChannelID))
 
 	link.RawQuery = params.Encode()
 
@@ -243,14 +253,6 @@ func (r bot) storyTable(payload *robots.Payload, stories []mavenlink.Story) {
 	}
 }
 
-func conn(user string) (*mavenlink.Mavenlink, error) {
-	token, err := db.GetSetting(user, "MAVENLINK_TOKEN")
-	if err != nil {
-		return nil, err
-	}
-	if token == nil {
-		return nil, errors.New("No MAVENLINK_TOKEN set for @" + user)
-	}
-	con := mavenlink.NewMavenlink(token.Value, false)
-	return con, nil
+func conn(p *robots.Payload) (*mavenlink.Mavenlink, error) {
+	return mavenlink.NewFor(p.TeamID, p.UserName)
 }
@@ -0,0 +1,93 @@
+package robots
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/gistia/slackbot/listener"
+	"github.com/gistia/slackbot/mavenlink"
+	"github.com/gistia/slackbot/pivotal"
+	"github.com/gistia/slackbot/robots"
+	"github.com/gistia/slackbot/utils"
+)
+
+// storyLinks matches links to Mavenlink stories, capturing their id
+var storyLinks = regexp.MustCompile(`mavenlink\.com/\S*#tracker/(\d+)`)
+
+// unfurl replies to links to Mavenlink stories with a card for each, read
+// with the token of who posted them or the team's
+func (r bot) unfurl(p *robots.Payload, rep listener.Replier, matches [][]string) error {
+	c := utils.SettingContext(p)
+	mvn, err := mavenlink.NewIn(c)
+	if err != nil {
+		// nobody to read the stories as
+		return nil
+	}
+	pvt, err := pivotal.NewIn(c)
+	if err != nil {
+		pvt = nil
+	}
+
+	atts := []robots.Attachment{}
+	seen := map[string]bool{}
+	for _, m := range matches {
+		if seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+
+		story, err := mvn.GetStory(m[1])
+		if err != nil {
+			log.Printf("Error getting Mavenlink story %s: %s\n", m[1], err)
+			continue
+		}
+		atts = append(atts, storyCard(mvn, pvt, story))
+	}
+
+	if len(atts) > 0 {
+		r.handler.SendWithAttachments(p, "", atts)
+	}
+	return nil
+}
+
+// storyCard describes the story with its state, hours, assignees and the
+// Pivotal story it's tagged with, when pvt can read it
+func storyCard(mvn *mavenlink.Mavenlink, pvt *pivotal.Pivotal, s *mavenlink.Story) robots.Attachment {
+	logged, estimated := s.Hours()
+	text := fmt.Sprintf("%.1fh of %.1fh logged", logged, estimated)
+	if s.State != "" {
+		text = s.State + ", " + text
+	}
+
+	if len(s.Assignees) > 0 {
+		if withUsers, err := mvn.GetAssignees(*s); err == nil {
+			names := []string{}
+			for _, u := range withUsers.Users {
+				names = append(names, u.Name)
+			}
+			text += "\nAssignees: " + strings.Join(names, ", ")
+		} else {
+			log.Printf("Error getting assignees of Mavenlink story %s: %s\n", s.Id, err)
+		}
+	}
+
+	if id := s.GetPivotalId(); id != "" && pvt != nil {
+		ps, err := pvt.GetStory(id)
+		if err == nil {
+			text += fmt.Sprintf("\nPivotal: <%s|#%d %s>", ps.URL(), ps.Id, ps.Name)
+			if ps.State != "" {
+				text += " " + ps.State
+			}
+			if ps.Estimate != nil {
+				text += fmt.Sprintf(", %d points", *ps.Estimate)
+			}
+		} else {
+			log.Printf("Error getting Pivotal story %s: %s\n", id, err)
+		}
+	}
+
+	title := s.Id + " " + s.Title
+	return utils.FmtAttachment(title, title, s.URL(), text)
+}
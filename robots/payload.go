@@ -8,6 +8,8 @@ import (
 	"net/url"
 	"os"
 	"strings"
+
+	"github.com/gistia/slackbot/dryrun"
 )
 
 type SlashCommand struct {
@@ -28,6 +30,9 @@ type Payload struct {
 	TriggerWord string  `schema:"trigger_word,omitempty"`
 	ServiceID   string  `schema:"service_id,omitempty"`
 	Robot       string
+	// DryRun records writes instead of performing them when the command
+	// was run with --dry-run, and is nil otherwise
+	DryRun *dryrun.Recorder `schema:"-"`
 }
 
 type OutgoingWebHook struct {
@@ -1,15 +1,19 @@
 package robots
 
 import (
-	"errors"
 	"fmt"
+	"time"
 
 	"github.com/gistia/slackbot/db"
+	"github.com/gistia/slackbot/listener"
 	"github.com/gistia/slackbot/pivotal"
 	"github.com/gistia/slackbot/robots"
 	"github.com/gistia/slackbot/utils"
 )
 
+// storiesPerMessage is how many stories `pvt stories` lists in each message
+const storiesPerMessage = 50
+
 type bot struct {
 	handler utils.SlackHandler
 }
@@ -18,6 +22,13 @@ func init() {
 	handler := utils.NewSlackHandler("Pivotal", ":triangular_ruler:")
 	s := &bot{handler: handler}
 	robots.RegisterRobot("pvt", s)
+
+	listener.Register(listener.Listener{
+		Name:     "pivotal.links",
+		Pattern:  storyLinks,
+		Cooldown: 10 * time.Second,
+		Run:      s.unfurl,
+	})
 }
 
 func (r bot) Run(p *robots.Payload) string {
@@ -40,13 +51,16 @@ func (r bot) DeferredAction(p *robots.Payload) {
 }
 
 func (r bot) users(p *robots.Payload, cmd utils.Command) error {
-	projectId := cmd.Arg(0)
+	projectId, err := utils.ArgOrSetting(p, cmd, 0, "PIVOTAL_PROJECT")
+	if err != nil {
+		return err
+	}
 	if projectId == "" {
-		r.handler.Send(p, "Missing project id. Use !pvt users <project-id>")
+		r.handler.Send(p, "Missing project id. Use !pvt users <project-id> or set PIVOTAL_PROJECT")
 		return nil
 	}
 
-	pvt, err := conn(p.UserName)
+	pvt, err := conn(p)
 	if err != nil {
 		return err
 	}
@@ -81,7 +95,7 @@ func (r bot) sendProjects(payload *robots.Payload, cmd utils.Command) error {
 
 	term := cmd.Arg(0)
 
-	pvt, err := conn(payload.UserName)
+	pvt, err := conn(payload)
 	if err != nil {
 		return err
 	}
@@ -93,7 +107,7 @@ func (r bot) sendProjects(payload *robots.Payload, cmd utils.Command) error {
 		// ps, err = pvt.SearchProject(term)
 	} else {
 		s += ":\n"
-		fmt.Println("Retrieving projects...\n")
+		fmt.Println("Retrieving projects...")
 		ps, err = pvt.Projects()
 	}
 
@@ -108,39 +122,62 @@ func (r bot) sendProjects(payload *robots.Payload, cmd utils.Command) error {
 }
 
 func (r bot) sendStories(p *robots.Payload, cmd utils.Command) error {
-	project := cmd.Arg(0)
-	pvt, err := conn(p.UserName)
+	project, err := utils.ArgOrSetting(p, cmd, 0, "PIVOTAL_PROJECT")
 	if err != nil {
 		return err
 	}
+	if project == "" {
+		r.handler.Send(p, "Missing project id. Use !pvt stories <project-id> or set PIVOTAL_PROJECT")
+		return nil
+	}
 
-	stories, err := pvt.Stories(project)
+	pvt, err := conn(p)
 	if err != nil {
 		return err
 	}
 
+	// large projects are sent as they're read, a message every so often
 	str := ""
-	for _, s := range stories {
+	n := 0
+	it := pvt.IterStories(project, nil)
+	for it.Next() {
+		s := it.Story()
 		str += fmt.Sprintf("%d - %s\n", s.Id, s.Name)
+		n++
+		if n%storiesPerMessage == 0 {
+			r.handler.Send(p, str)
+			str = ""
+		}
+	}
+	if err := it.Err(); err != nil {
+		return err
 	}
 
-	r.handler.Send(p, str)
+	if n < 1 {
+		str = "No stories in project " + project
+	}
+	if str != "" {
+		r.handler.Send(p, str)
+	}
 	return nil
 }
 
 func (r bot) sendMyStories(p *robots.Payload, cmd utils.Command) error {
-	args, err := cmd.ParseArgs("pvt-project-id")
+	project, err := utils.ArgOrSetting(p, cmd, 0, "PIVOTAL_PROJECT")
 	if err != nil {
 		return err
 	}
+	if project == "" {
+		_, err := cmd.ParseArgs("pvt-project-id")
+		return err
+	}
 
-	project := args[0]
-	pvt, err := conn(p.UserName)
+	pvt, err := conn(p)
 	if err != nil {
 		return err
 	}
 
-	user, err := db.GetUserByName(p.UserName)
+	user, err := db.GetUserByName(p.TeamID, p.UserName)
 	if err != nil {
 		return err
 	}
@@ -175,7 +212,7 @@ func (r bot) sendMyStories(p *robots.Payload, cmd utils.Command) error {
 func (r bot) setStoryState(p *robots.Payload, cmd utils.Command) error {
 	state := cmd.Command
 	id := cmd.Arg(0)
-	pvt, err := conn(p.UserName)
+	pvt, err := conn(p)
 	if err != nil {
 		return err
 	}
@@ -188,11 +225,15 @@ func (r bot) setStoryState(p *robots.Payload, cmd utils.Command) error {
 
 	r.handler.Send(p, fmt.Sprintf("Story %s - %s %s successfully",
 		id, story.Name, state))
-	return nil
+
+	if state == "finished" {
+		_, err = utils.ClaimStoryTimer(p, id)
+	}
+	return err
 }
 
 func (r bot) sendAuth(p *robots.Payload, cmd utils.Command) error {
-	s, err := db.GetSetting(p.UserName, "PIVOTAL_TOKEN")
+	s, err := db.GetSetting(p.TeamID, p.UserName, "PIVOTAL_TOKEN")
 	if err != nil {
 		return err
 	}
@@ -211,16 +252,8 @@ func (r bot) sendAuth(p *robots.Payload, cmd utils.Command) error {
 	return nil
 }
 
-func conn(user string) (*pivotal.Pivotal, error) {
-	token, err := db.GetSetting(user, "PIVOTAL_TOKEN")
-	if err != nil {
-		return nil, err
-	}
-	if token == nil {
-		return nil, errors.New("No PIVOTAL_TOKEN set for @" + user)
-	}
-	con := pivotal.NewPivotal(token.Value, false)
-	return con, nil
+func conn(p *robots.Payload) (*pivotal.Pivotal, error) {
+	return pivotal.NewFor(p.TeamID, p.UserName)
 }
 
 func projectTable(ps []pivotal.Project) string {
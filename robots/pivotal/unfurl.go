@@ -0,0 +1,110 @@
+package robots
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gistia/slackbot/listener"
+	"github.com/gistia/slackbot/mavenlink"
+	"github.com/gistia/slackbot/pivotal"
+	"github.com/gistia/slackbot/robots"
+	"github.com/gistia/slackbot/utils"
+)
+
+// storyLinks matches links to Pivotal stories, capturing their id
+var storyLinks = regexp.MustCompile(`pivotaltracker\.com/(?:story/show|(?:n/)?projects/\d+/stories)/(\d+)`)
+
+// unfurl replies to links to Pivotal stories with a card for each, read
+// with the token of who posted them or the team's
+func (r bot) unfurl(p *robots.Payload, rep listener.Replier, matches [][]string) error {
+	c := utils.SettingContext(p)
+	pvt, err := pivotal.NewIn(c)
+	if err != nil {
+		// nobody to read the stories as
+		return nil
+	}
+	mvn, err := mavenlink.NewIn(c)
+	if err != nil {
+		mvn = nil
+	}
+
+	atts := []robots.Attachment{}
+	seen := map[string]bool{}
+	for _, m := range matches {
+		if seen[m[1]] {
+			continue
+		}
+		seen[m[1]] = true
+
+		story, err := pvt.GetStory(m[1])
+		if err != nil {
+			log.Printf("Error getting Pivotal story %s: %s\n", m[1], err)
+			continue
+		}
+		atts = append(atts, storyCard(pvt, mvn, story))
+	}
+
+	if len(atts) > 0 {
+		r.handler.SendWithAttachments(p, "", atts)
+	}
+	return nil
+}
+
+// storyCard describes the story with its state, estimate, owners and the
+// Mavenlink story it's tagged with, when mvn can read it
+func storyCard(pvt *pivotal.Pivotal, mvn *mavenlink.Mavenlink, s *pivotal.Story) robots.Attachment {
+	details := []string{}
+	for _, d := range []string{s.Type, s.State} {
+		if d != "" {
+			details = append(details, d)
+		}
+	}
+	if s.Estimate != nil {
+		details = append(details, fmt.Sprintf("%d points", *s.Estimate))
+	}
+	text := strings.Join(details, ", ")
+	if owners := ownerNames(pvt, s); owners != "" {
+		text += "\nOwners: " + owners
+	}
+
+	if id := s.GetMavenlinkId(); id != "" && mvn != nil {
+		ms, err := mvn.GetStory(id)
+		if err == nil {
+			logged, estimated := ms.Hours()
+			text += fmt.Sprintf("\nMavenlink: <%s|%s> %.1fh of %.1fh logged",
+				ms.URL(), ms.Title, logged, estimated)
+		} else {
+			log.Printf("Error getting Mavenlink story %s: %s\n", id, err)
+		}
+	}
+
+	title := fmt.Sprintf("#%d %s", s.Id, s.Name)
+	return utils.FmtAttachment(title, title, s.URL(), strings.TrimPrefix(text, "\n"))
+}
+
+// ownerNames returns the names of the owners of the story, as members of
+// its project
+func ownerNames(pvt *pivotal.Pivotal, s *pivotal.Story) string {
+	if len(s.OwnerIds) < 1 {
+		return ""
+	}
+
+	members, err := pvt.GetProjectMemberships(strconv.FormatInt(s.ProjectId, 10))
+	if err != nil {
+		log.Printf("Error getting members of Pivotal project %d: %s\n", s.ProjectId, err)
+		return ""
+	}
+
+	names := []string{}
+	for _, id := range s.OwnerIds {
+		for _, m := range members {
+			if m.Person.Id == id {
+				names = append(names, m.Person.Name)
+			}
+		}
+	}
+	return strings.Join(names, ", ")
+}
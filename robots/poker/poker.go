@@ -47,7 +47,7 @@ func (r bot) DeferredAction(p *robots.Payload) {
 }
 
 func (r bot) addStories(p *robots.Payload, cmd utils.Command) error {
-	session, err := db.GetCurrentSession(p.ChannelName)
+	session, err := db.GetCurrentSession(p.TeamID, p.ChannelName)
 	if err != nil {
 		return err
 	}
@@ -57,14 +57,14 @@ func (r bot) addStories(p *robots.Payload, cmd utils.Command) error {
 	}
 
 	appUrl := os.Getenv("APP_URL")
-	url := appUrl + "poker?channel=" + p.ChannelName + "&channel_id=" + p.ChannelID
+	url := appUrl + "poker?team=" + p.TeamID + "&channel=" + p.ChannelName + "&channel_id=" + p.ChannelID
 	a := utils.FmtAttachment("", "Click here to add stories", url, "Add stories in back to this session by following the link")
 	r.handler.SendWithAttachments(p, "", []robots.Attachment{a})
 	return nil
 }
 
 func (r bot) status(p *robots.Payload, cmd utils.Command) error {
-	session, err := db.GetCurrentSession(p.ChannelName)
+	session, err := db.GetCurrentSession(p.TeamID, p.ChannelName)
 	if err != nil {
 		return err
 	}
@@ -119,7 +119,7 @@ func (r bot) startSession(p *robots.Payload, cmd utils.Command) error {
 
 	users := cmd.Param("users")
 
-	err := db.StartPokerSession(p.ChannelName, title, users)
+	err := db.StartPokerSession(p.TeamID, p.ChannelName, title, users)
 	if err != nil {
 		return err
 	}
@@ -129,7 +129,7 @@ func (r bot) startSession(p *robots.Payload, cmd utils.Command) error {
 }
 
 func (r bot) nextStory(p *robots.Payload, cmd utils.Command) error {
-	session, err := db.GetCurrentSession(p.ChannelName)
+	session, err := db.GetCurrentSession(p.TeamID, p.ChannelName)
 	if err != nil {
 		return err
 	}
@@ -155,7 +155,7 @@ func (r bot) nextStory(p *robots.Payload, cmd utils.Command) error {
 func (r bot) startStory(p *robots.Payload, cmd utils.Command) error {
 	title := cmd.StrFrom(0)
 
-	session, err := db.GetCurrentSession(p.ChannelName)
+	session, err := db.GetCurrentSession(p.TeamID, p.ChannelName)
 	if err != nil {
 		return err
 	}
@@ -190,7 +190,7 @@ func (r bot) vote(p *robots.Payload, cmd utils.Command) error {
 	}
 	vote := args[0]
 
-	session, err := db.GetCurrentSession(p.ChannelName)
+	session, err := db.GetCurrentSession(p.TeamID, p.ChannelName)
 	if err != nil {
 		return err
 	}
@@ -238,7 +238,7 @@ func (r bot) vote(p *robots.Payload, cmd utils.Command) error {
 }
 
 func (r bot) revealVotes(p *robots.Payload, cmd utils.Command) error {
-	session, err := db.GetCurrentSession(p.ChannelName)
+	session, err := db.GetCurrentSession(p.TeamID, p.ChannelName)
 	if err != nil {
 		return err
 	}
@@ -277,7 +277,7 @@ func (r bot) setEstimation(p *robots.Payload, cmd utils.Command) error {
 	}
 	estimation := args[0]
 
-	session, err := db.GetCurrentSession(p.ChannelName)
+	session, err := db.GetCurrentSession(p.TeamID, p.ChannelName)
 	if err != nil {
 		return err
 	}
@@ -314,7 +314,7 @@ func (r bot) setEstimation(p *robots.Payload, cmd utils.Command) error {
 }
 
 func (r bot) endSession(p *robots.Payload, cmd utils.Command) error {
-	session, err := db.GetCurrentSession(p.ChannelName)
+	session, err := db.GetCurrentSession(p.TeamID, p.ChannelName)
 	if err != nil {
 		return err
 	}
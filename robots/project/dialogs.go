@@ -0,0 +1,102 @@
+package robots
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gistia/slackbot/db"
+	"github.com/gistia/slackbot/dialog"
+	"github.com/gistia/slackbot/robots"
+)
+
+func (r bot) registerDialogs() {
+	dialog.Register(dialog.Dialog{
+		Name: "project.create",
+		Steps: []dialog.Step{
+			{
+				Name:     "alias",
+				Prompt:   "What's the alias for the new project? It's the short name used in commands like `!project stories <alias>`.",
+				Validate: validateAlias,
+			},
+			{
+				Name:   "name",
+				Prompt: "What's the full name of the project?",
+			},
+			{
+				Name:     "confirm",
+				Prompt:   "Should I create the project on Pivotal and Mavenlink now? (yes/no)",
+				Validate: dialog.Confirm,
+			},
+		},
+		Run: func(p *robots.Payload, a dialog.Answers) error {
+			return r.createProject(p, a["alias"], a["name"])
+		},
+	})
+
+	dialog.Register(dialog.Dialog{
+		Name: "project.addstory",
+		Steps: []dialog.Step{
+			{
+				Name:     "project",
+				Prompt:   "Which project should the story be added to?",
+				Validate: validateProject,
+			},
+			{
+				Name:     "type",
+				Prompt:   "What type of story is it? (feature, bug or chore)",
+				Validate: validateStoryType,
+			},
+			{
+				Name:   "name",
+				Prompt: "What's the title of the story?",
+			},
+			{
+				Name:     "confirm",
+				Prompt:   "Should I add the story to Pivotal and Mavenlink now? (yes/no)",
+				Validate: dialog.Confirm,
+			},
+		},
+		Run: func(p *robots.Payload, a dialog.Answers) error {
+			pr, err := getProject(p.TeamID, a["project"])
+			if err != nil {
+				return err
+			}
+			return r.createStory(p, pr, a["type"], a["name"])
+		},
+	})
+}
+
+func validateAlias(p *robots.Payload, a dialog.Answers, s string) (string, error) {
+	if s == "" || strings.Contains(s, " ") {
+		return "", errors.New("The alias must be a single word.")
+	}
+
+	pr, err := db.GetProjectByName(p.TeamID, s)
+	if err != nil {
+		return "", err
+	}
+	if pr != nil {
+		return "", errors.New("Project *" + s + "* already exists.")
+	}
+
+	return s, nil
+}
+
+func validateProject(p *robots.Payload, a dialog.Answers, s string) (string, error) {
+	pr, err := getProject(p.TeamID, s)
+	if err != nil {
+		return "", err
+	}
+
+	return pr.Name, nil
+}
+
+func validateStoryType(p *robots.Payload, a dialog.Answers, s string) (string, error) {
+	s = strings.ToLower(s)
+	switch s {
+	case "feature", "bug", "chore":
+		return s, nil
+	}
+
+	return "", errors.New("The story type must be `feature`, `bug` or `chore`.")
+}
@@ -6,14 +6,19 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gistia/slackbot/db"
+	"github.com/gistia/slackbot/dialog"
 	"github.com/gistia/slackbot/mavenlink"
 	"github.com/gistia/slackbot/pivotal"
 	"github.com/gistia/slackbot/robots"
 	"github.com/gistia/slackbot/utils"
 )
 
+// reactionHint follows story cards, which the userbot watches for reactions
+const reactionHint = "React with :eyes: to take a story, :hammer: to start it or :white_check_mark: to finish it."
+
 type bot struct {
 	handler utils.SlackHandler
 }
@@ -23,6 +28,7 @@ func init() {
 	s := &bot{handler: handler}
 	robots.RegisterRobot("project", s)
 	robots.RegisterRobot("pr", s)
+	s.registerDialogs()
 }
 
 func (r bot) Run(p *robots.Payload) string {
@@ -30,6 +36,11 @@ func (r bot) Run(p *robots.Payload) string {
 	return ""
 }
 
+// SupportsDryRun tells every project command honors --dry-run
+func (r bot) SupportsDryRun() bool {
+	return true
+}
+
 func (r bot) DeferredAction(p *robots.Payload) {
 	ch := utils.NewCmdHandler(p, r.handler, "project")
 	ch.Handle("list", r.list)
@@ -41,6 +52,7 @@ func (r bot) DeferredAction(p *robots.Payload) {
 	ch.Handle("setchannel", r.setChannel)
 	ch.Handle("addstory", r.addStory)
 	ch.Handle("start", r.startTask)
+	ch.Handle("finish", r.finishTask)
 	ch.Handle("create", r.create)
 	ch.Handle("rename", r.rename)
 	ch.Handle("members", r.members)
@@ -65,7 +77,7 @@ func (r bot) addTime(p *robots.Payload, cmd utils.Command) error {
 		return err
 	}
 
-	mvn, err := mavenlink.NewFor(p.UserName)
+	mvn, err := mavenlinkFor(p)
 	if err != nil {
 		return err
 	}
@@ -94,7 +106,7 @@ func (r bot) estimate(p *robots.Payload, cmd utils.Command) error {
 
 	pvtId, estimate := args[0], args[1]
 
-	pvt, err := pivotal.NewFor(p.UserName)
+	pvt, err := pivotalFor(p)
 	if err != nil {
 		return err
 	}
@@ -128,9 +140,9 @@ func (r bot) myStories(p *robots.Payload, cmd utils.Command) error {
 	var err error
 
 	if name == "" {
-		pr, err = db.GetProjectByChannel(p.ChannelName)
+		pr, err = db.GetProjectByChannel(p.TeamID, p.ChannelName)
 	} else {
-		pr, err = db.GetProjectByName(name)
+		pr, err = db.GetProjectByName(p.TeamID, name)
 	}
 
 	if err != nil {
@@ -142,12 +154,12 @@ func (r bot) myStories(p *robots.Payload, cmd utils.Command) error {
 		return nil
 	}
 
-	pvt, err := pivotal.NewFor(p.UserName)
+	pvt, err := pivotalFor(p)
 	if err != nil {
 		return err
 	}
 
-	user, err := db.GetUserByName(p.UserName)
+	user, err := db.GetUserByName(p.TeamID, p.UserName)
 	if err != nil {
 		return err
 	}
@@ -166,16 +178,17 @@ func (r bot) myStories(p *robots.Payload, cmd utils.Command) error {
 		return nil
 	}
 
-	str := "Current stories in project *" + pr.Name + "* for *" + p.UserName + "*:\n"
-	atts := []robots.Attachment{}
+	r.handler.Send(p, "Current stories in project *"+pr.Name+"* for *"+p.UserName+"*:")
 	for _, s := range stories {
 		fallback := fmt.Sprintf("%d - %s - %s\n", s.Id, s.Name, s.State)
 		title := fmt.Sprintf("%d - %s\n", s.Id, s.Name)
 		a := utils.FmtAttachment(fallback, title, s.Url, s.State)
-		atts = append(atts, a)
+		if err := r.sendStoryCard(p, a, s.GetStringId(), s.GetMavenlinkId()); err != nil {
+			return err
+		}
 	}
 
-	r.handler.SendWithAttachments(p, str, atts)
+	r.handler.Send(p, reactionHint)
 	return nil
 }
 
@@ -186,30 +199,36 @@ func (r bot) unassigned(p *robots.Payload, cmd utils.Command) error {
 	}
 
 	name := res[0]
-	pr, err := getProject(name)
+	pr, err := getProject(p.TeamID, name)
 	if err != nil {
 		return err
 	}
 
-	pvt, err := pivotal.NewFor(p.UserName)
+	pvt, err := pivotalFor(p)
 	if err != nil {
 		return err
 	}
 
-	msg := "Unassigned stories for *" + name + "*:\n"
-
 	stories, err := pvt.GetUnassignedStories(pr.StrPivotalId())
+	if err != nil {
+		return err
+	}
 
 	if len(stories) < 1 {
 		r.handler.Send(p, "No unassigned stories for *"+name+"*")
 		return nil
 	}
 
+	r.handler.Send(p, "Unassigned stories for *"+name+"*:")
 	for _, s := range stories {
-		msg += fmt.Sprintf("%d - %s\n", s.Id, s.Name)
+		title := fmt.Sprintf("%d - %s", s.Id, s.Name)
+		a := utils.FmtAttachment(title, title, s.Url, "")
+		if err := r.sendStoryCard(p, a, s.GetStringId(), s.GetMavenlinkId()); err != nil {
+			return err
+		}
 	}
 
-	r.handler.Send(p, msg)
+	r.handler.Send(p, reactionHint)
 	return nil
 }
 
@@ -221,25 +240,28 @@ func (r bot) assign(p *robots.Payload, cmd utils.Command) error {
 
 	storyId, username := res[0], res[1]
 
-	pvt, err := pivotal.NewFor(p.UserName)
+	pvt, err := pivotalFor(p)
 	if err != nil {
 		return err
 	}
 
-	user, err := db.GetUserByName(username)
+	user, err := db.GetUserByName(p.TeamID, username)
 	if err != nil {
 		return err
 	}
 	if user == nil {
 		return errors.New("User *" + username + "* not found")
 	}
+	if user.PivotalId == nil {
+		return errors.New("User *" + username + "* has no Pivotal id")
+	}
 
 	story, err := pvt.AssignStory(storyId, *user.PivotalId)
 	if err != nil {
 		return err
 	}
 
-	s := "Story successfully updated:\n"
+	s := "Story assigned to *" + username + "*:\n"
 	r.handler.SendWithAttachments(p, s, []robots.Attachment{
 		utils.FmtAttachment("", story.Name, story.Url, ""),
 	})
@@ -261,12 +283,12 @@ func (r bot) addmember(p *robots.Payload, cmd utils.Command) error {
 		return err
 	}
 
-	pr, err := getProject(name)
+	pr, err := getProject(p.TeamID, name)
 	if err != nil {
 		return err
 	}
 
-	pvt, err := pivotal.NewFor(p.UserName)
+	pvt, err := pivotalFor(p)
 	if err != nil {
 		return err
 	}
@@ -276,7 +298,7 @@ func (r bot) addmember(p *robots.Payload, cmd utils.Command) error {
 		return nil
 	}
 
-	user, err := db.GetUserByName(username)
+	user, err := db.GetUserByName(p.TeamID, username)
 	if pr == nil {
 		r.handler.Send(p, "Project *"+name+"* not found")
 		return nil
@@ -302,12 +324,12 @@ func (r bot) members(p *robots.Payload, cmd utils.Command) error {
 			"Missing project name. Use `!project addtask <project> <task-name>`")
 		return err
 	}
-	pr, err := getProject(name)
+	pr, err := getProject(p.TeamID, name)
 	if err != nil {
 		return err
 	}
 
-	pvt, err := pivotal.NewFor(p.UserName)
+	pvt, err := pivotalFor(p)
 	if err != nil {
 		return err
 	}
@@ -339,7 +361,7 @@ func (r bot) rename(p *robots.Payload, cmd utils.Command) error {
 		return nil
 	}
 
-	pr, err := db.GetProjectByName(old)
+	pr, err := db.GetProjectByName(p.TeamID, old)
 	if err != nil {
 		return err
 	}
@@ -350,6 +372,7 @@ func (r bot) rename(p *robots.Payload, cmd utils.Command) error {
 	}
 
 	pr.Name = new
+	pr.DryRun = p.DryRun
 	err = db.UpdateProject(*pr)
 	if err != nil {
 		return err
@@ -361,20 +384,24 @@ func (r bot) rename(p *robots.Payload, cmd utils.Command) error {
 
 func (r bot) create(p *robots.Payload, cmd utils.Command) error {
 	alias := cmd.Arg(0)
-	if alias == "" {
-		r.handler.Send(p, "Missing project alias. Usage: `!project createproject <alias> <long-name>`")
-		return nil
-	}
 	name := cmd.StrFrom(1)
-	if name == "" {
-		r.handler.Send(p, "Missing project name. Usage: `!project createproject <alias> <long-name>`")
-		return nil
+	if alias == "" || name == "" {
+		initial := dialog.Answers{}
+		if alias != "" {
+			initial["alias"] = alias
+		}
+		return dialog.Start(p, r.handler, "project.create", initial)
 	}
-	mvn, err := mavenlink.NewFor(p.UserName)
+
+	return r.createProject(p, alias, name)
+}
+
+func (r bot) createProject(p *robots.Payload, alias string, name string) error {
+	mvn, err := mavenlinkFor(p)
 	if err != nil {
 		return err
 	}
-	pvt, err := pivotal.NewFor(p.UserName)
+	pvt, err := pivotalFor(p)
 	if err != nil {
 		return err
 	}
@@ -388,7 +415,7 @@ func (r bot) create(p *robots.Payload, cmd utils.Command) error {
 	}
 	mvnProject := mavenlink.Project{
 		Title:       name,
-		Description: fmt.Sprintf("[pvt:%s]", pvtNewProject.Id),
+		Description: fmt.Sprintf("[pvt:%d]", pvtNewProject.Id),
 		CreatorRole: "maven",
 	}
 	mvnNewProject, err := mvn.CreateProject(mvnProject)
@@ -404,75 +431,159 @@ func (r bot) create(p *robots.Payload, cmd utils.Command) error {
 		return err
 	}
 
-	err = r.makeLink(p, alias, mvnNewProject.Id, strconv.FormatInt(pvtNewProject.Id, 10))
-	if err != nil {
-		return err
+	if p.DryRun.Active() {
+		// the projects weren't really created, so makeLink can't load them
+		p.DryRun.Record("db", "create_project", alias, db.Project{
+			TeamID:    p.TeamID,
+			Name:      alias,
+			CreatedBy: p.UserName,
+		})
+	} else {
+		err = r.makeLink(p, alias, mvnNewProject.Id, strconv.FormatInt(pvtNewProject.Id, 10))
+		if err != nil {
+			return err
+		}
 	}
 
 	r.handler.Send(p, "Project *"+name+"* created on Pivotal and Mavenlink.")
 	return nil
 }
 
+// startTask starts the Pivotal story and the Mavenlink story it's tagged
+// with, along with a timer for the work on them
 func (r bot) startTask(p *robots.Payload, cmd utils.Command) error {
 	// pr, err := getProject(cmd.Arg(0))
 	// if err != nil {
 	// 	return err
 	// }
-	mvn, err := mavenlink.NewFor(p.UserName)
+	storyId := cmd.Arg(0)
+	if storyId == "" {
+		// stories, err := mvn.ChildStories(pr.MvnSprintStoryId)
+		// if err != nil {
+		// 	return err
+		// }
+
+		// r.handler.Send(p, "Click the story you want to start on *"+pr.Name+"*:")
+		// url := os.Getenv("APP_URL")
+		// atts := mavenlink.CustomFormatStories(stories, url+"selection/startTask/")
+		// for _, a := range atts {
+		// 	r.handler.SendWithAttachments(p, "", []robots.Attachment{a})
+		// }
+		return nil
+	}
+
+	pvtStory, mvnId, err := r.setTaskState(p, storyId, "started", "started")
 	if err != nil {
 		return err
 	}
-	pvt, err := pivotal.NewFor(p.UserName)
+
+	msg := "Story *" + pvtStory.Name + "* started"
+	started, err := r.startTaskTimer(p, pvtStory.GetStringId(), mvnId)
 	if err != nil {
 		return err
 	}
+	if started {
+		msg += fmt.Sprintf(", with timer *%s* running. Finishing the story stops it and claims its time.",
+			pvtStory.GetStringId())
+	}
+
+	r.handler.Send(p, msg)
+	return nil
+}
+
+// finishTask finishes the Pivotal story and completes the Mavenlink story
+// it's tagged with, claiming the time of the timer started for it
+func (r bot) finishTask(p *robots.Payload, cmd utils.Command) error {
+	storyId := cmd.Arg(0)
+	if storyId == "" {
+		return errors.New("Missing story. Use `!project finish <pivotal-id>`")
+	}
 
-	if storyId := cmd.Arg(0); storyId != "" {
-		pvtStory, err := pvt.GetStory(storyId)
+	pvtStory, _, err := r.setTaskState(p, storyId, "finished", "completed")
+	if err != nil {
+		return err
+	}
+
+	r.handler.Send(p, "Story *"+pvtStory.Name+"* finished")
+	_, err = utils.ClaimStoryTimer(p, pvtStory.GetStringId())
+	return err
+}
+
+// setTaskState moves the Pivotal story to pvtState and the Mavenlink story
+// it's tagged with, if any, to mvnState. It returns the Pivotal story and
+// the id of the Mavenlink one
+func (r bot) setTaskState(p *robots.Payload, storyId string, pvtState string, mvnState string) (*pivotal.Story, string, error) {
+	mvn, err := mavenlinkFor(p)
+	if err != nil {
+		return nil, "", err
+	}
+	pvt, err := pivotalFor(p)
+	if err != nil {
+		return nil, "", err
+	}
+
+	pvtStory, err := pvt.GetStory(storyId)
+	if err != nil {
+		return nil, "", err
+	}
+
+	_, err = pvt.SetStoryState(pvtStory.GetStringId(), pvtState)
+	if err != nil {
+		return nil, "", err
+	}
+
+	mvnId := pvtStory.GetMavenlinkId()
+	if mvnId != "" {
+		mvnStory, err := mvn.GetStory(mvnId)
 		if err != nil {
-			return err
+			return nil, "", err
 		}
 
-		pvtStory, err = pvt.SetStoryState(pvtStory.GetStringId(), "started")
+		_, err = mvn.SetStoryState(mvnStory.Id, mvnState)
 		if err != nil {
-			return err
+			return nil, "", err
 		}
+	}
 
-		if mvnId := pvtStory.GetMavenlinkId(); mvnId != "" {
-			mvnStory, err := mvn.GetStory(mvnId)
-			if err != nil {
-				return err
-			}
+	return pvtStory, mvnId, nil
+}
 
-			fmt.Printf(" ** Got story: %+v\n", mvnStory)
+// startTaskTimer starts a timer named after the story, unless the user
+// already has one running for it. It returns false when no timer was started
+func (r bot) startTaskTimer(p *robots.Payload, pvtId string, mvnId string) (bool, error) {
+	timer, err := db.GetStartedTimerByStory(p.TeamID, p.UserName, pvtId)
+	if err != nil || timer != nil {
+		return false, err
+	}
+	timer, err = db.GetStartedTimerByName(p.TeamID, p.UserName, pvtId)
+	if err != nil || timer != nil {
+		return false, err
+	}
 
-			mvnStory.State = "started"
-			mvnStory, err = mvn.SetStoryState(mvnStory.Id, "started")
-			if err != nil {
-				return err
-			}
-		}
+	err = db.CreateStoryTimer(p.TeamID, p.UserName, pvtId, pvtId, mvnId, time.Now(), p.DryRun)
+	return err == nil, err
+}
 
-		r.handler.Send(p, "Story *"+pvtStory.Name+"* started")
-		return nil
+func pivotalFor(p *robots.Payload) (*pivotal.Pivotal, error) {
+	pvt, err := pivotal.NewFor(p.TeamID, p.UserName)
+	if err != nil {
+		return nil, err
 	}
+	pvt.DryRun = p.DryRun
+	return pvt, nil
+}
 
-	// stories, err := mvn.ChildStories(pr.MvnSprintStoryId)
-	// if err != nil {
-	// 	return err
-	// }
-
-	// r.handler.Send(p, "Click the story you want to start on *"+pr.Name+"*:")
-	// url := os.Getenv("APP_URL")
-	// atts := mavenlink.CustomFormatStories(stories, url+"selection/startTask/")
-	// for _, a := range atts {
-	// 	r.handler.SendWithAttachments(p, "", []robots.Attachment{a})
-	// }
-	return nil
+func mavenlinkFor(p *robots.Payload) (*mavenlink.Mavenlink, error) {
+	mvn, err := mavenlink.NewFor(p.TeamID, p.UserName)
+	if err != nil {
+		return nil, err
+	}
+	mvn.DryRun = p.DryRun
+	return mvn, nil
 }
 
-func getProject(name string) (*db.Project, error) {
-	pr, err := db.GetProjectByName(name)
+func getProject(team string, name string) (*db.Project, error) {
+	pr, err := db.GetProjectByName(team, name)
 	if err != nil {
 		return nil, err
 	}
@@ -486,33 +597,37 @@ func getProject(name string) (*db.Project, error) {
 
 func (r bot) addStory(p *robots.Payload, cmd utils.Command) error {
 	name := cmd.Arg(0)
-	if name == "" {
-		err := errors.New(
-			"Missing project name. Use `!project addtask <project> [type:<story-type>] <task-name>`")
-		return err
+	storyType := cmd.Param("type")
+	storyName := strings.Join(cmd.ArgsFrom(1), " ")
+	if name == "" || storyName == "" {
+		initial := dialog.Answers{}
+		if name != "" {
+			initial["project"] = name
+		}
+		if storyType != "" {
+			initial["type"] = storyType
+		}
+		return dialog.Start(p, r.handler, "project.addstory", initial)
 	}
 
-	storyType := cmd.Param("type")
 	if storyType == "" {
 		storyType = "feature"
 	}
 
-	storyName := strings.Join(cmd.ArgsFrom(1), " ")
-	if storyName == "" {
-		err := errors.New(
-			"Missing story name. Use `!project addtask <project> <task-name>`")
-		return err
-	}
-
-	pr, err := getProject(name)
+	pr, err := getProject(p.TeamID, name)
 	if err != nil {
 		return err
 	}
-	mvn, err := mavenlink.NewFor(p.UserName)
+
+	return r.createStory(p, pr, storyType, storyName)
+}
+
+func (r bot) createStory(p *robots.Payload, pr *db.Project, storyType string, storyName string) error {
+	mvn, err := mavenlinkFor(p)
 	if err != nil {
 		return err
 	}
-	pvt, err := pivotal.NewFor(p.UserName)
+	pvt, err := pivotalFor(p)
 	if err != nil {
 		return err
 	}
@@ -570,12 +685,12 @@ func (r bot) addSprint(p *robots.Payload, cmd utils.Command) error {
 		return nil
 	}
 
-	ps, err := db.GetProjectByName(name)
+	ps, err := db.GetProjectByName(p.TeamID, name)
 	if err != nil {
 		return err
 	}
 
-	mvn, err := mavenlink.NewFor(p.UserName)
+	mvn, err := mavenlinkFor(p)
 	if err != nil {
 		return err
 	}
@@ -618,6 +733,7 @@ func (r bot) addSprint(p *robots.Payload, cmd utils.Command) error {
 	fmt.Printf("%+v\n", ns)
 
 	ps.MvnSprintStoryId = ns.Id
+	ps.DryRun = p.DryRun
 	err = db.UpdateProject(*ps)
 	if err != nil {
 		return err
@@ -635,12 +751,13 @@ func (r bot) setChannel(p *robots.Payload, cmd utils.Command) error {
 		return nil
 	}
 
-	ps, err := db.GetProjectByName(name)
+	ps, err := db.GetProjectByName(p.TeamID, name)
 	if err != nil {
 		return err
 	}
 
 	ps.Channel = p.ChannelName
+	ps.DryRun = p.DryRun
 	if err := db.UpdateProject(*ps); err != nil {
 		return err
 	}
@@ -655,7 +772,7 @@ func (r bot) stories(p *robots.Payload, cmd utils.Command) error {
 	var ps *db.Project
 	var err error
 	if name != "" {
-		ps, err = db.GetProjectByName(name)
+		ps, err = db.GetProjectByName(p.TeamID, name)
 		if err != nil {
 			return err
 		}
@@ -666,7 +783,7 @@ func (r bot) stories(p *robots.Payload, cmd utils.Command) error {
 	}
 
 	if ps == nil {
-		ps, err = db.GetProjectByChannel(p.ChannelName)
+		ps, err = db.GetProjectByChannel(p.TeamID, p.ChannelName)
 		if err != nil {
 			return err
 		}
@@ -676,7 +793,7 @@ func (r bot) stories(p *robots.Payload, cmd utils.Command) error {
 		}
 	}
 
-	mvn, err := mavenlink.NewFor(p.UserName)
+	mvn, err := mavenlinkFor(p)
 	if err != nil {
 		return err
 	}
@@ -693,8 +810,10 @@ func (r bot) stories(p *robots.Payload, cmd utils.Command) error {
 
 	r.handler.Send(p, "Mavenlink stories for *"+ps.Name+"*, sprint *"+sprint.Title+"*:")
 	atts := mavenlink.FormatStories(stories)
-	for _, a := range atts {
-		r.handler.SendWithAttachments(p, "", []robots.Attachment{a})
+	for i, a := range atts {
+		if err := r.sendStoryCard(p, a, stories[i].GetPivotalId(), stories[i].Id); err != nil {
+			return err
+		}
 	}
 
 	var totalEstimated int64
@@ -717,10 +836,28 @@ func (r bot) stories(p *robots.Payload, cmd utils.Command) error {
 	if s != "" {
 		r.handler.Send(p, s)
 	}
+	r.handler.Send(p, reactionHint)
 
 	return nil
 }
 
+// sendStoryCard posts the card for a story, remembering which story the
+// message is about so reacting to it can update the story
+func (r bot) sendStoryCard(p *robots.Payload, a robots.Attachment, pvtId string, mvnId string) error {
+	ts, err := r.handler.SendCard(p, a)
+	if err != nil || ts == "" {
+		return err
+	}
+
+	return db.CreateStoryCard(db.StoryCard{
+		TeamID:         p.TeamID,
+		Channel:        p.ChannelID,
+		Timestamp:      ts,
+		PivotalStory:   pvtId,
+		MavenlinkStory: mvnId,
+	})
+}
+
 func (r bot) setSprint(p *robots.Payload, cmd utils.Command) error {
 	name := cmd.Arg(0)
 	if name == "" {
@@ -734,12 +871,12 @@ func (r bot) setSprint(p *robots.Payload, cmd utils.Command) error {
 		return nil
 	}
 
-	ps, err := db.GetProjectByName(name)
+	ps, err := db.GetProjectByName(p.TeamID, name)
 	if err != nil {
 		return err
 	}
 
-	mvn, err := mavenlink.NewFor(p.UserName)
+	mvn, err := mavenlinkFor(p)
 	if err != nil {
 		return err
 	}
@@ -754,8 +891,8 @@ func (r bot) setSprint(p *robots.Payload, cmd utils.Command) error {
 		return nil
 	}
 
-	fmt.Println("Got story", mvnStory.Id)
 	ps.MvnSprintStoryId = mvnStory.Id
+	ps.DryRun = p.DryRun
 	if err := db.UpdateProject(*ps); err != nil {
 		return err
 	}
@@ -765,7 +902,7 @@ func (r bot) setSprint(p *robots.Payload, cmd utils.Command) error {
 }
 
 func (r bot) list(p *robots.Payload, cmd utils.Command) error {
-	ps, err := db.GetProjects()
+	ps, err := db.GetProjects(p.TeamID)
 	if err != nil {
 		return err
 	}
@@ -792,7 +929,7 @@ func (r bot) list(p *robots.Payload, cmd utils.Command) error {
 
 		sprintInfo := ""
 		if pr.MvnSprintStoryId != "" {
-			mvn, err := mavenlink.NewFor(p.UserName)
+			mvn, err := mavenlinkFor(p)
 			if err != nil {
 				return err
 			}
@@ -846,7 +983,7 @@ func (r bot) link(p *robots.Payload, cmd utils.Command) error {
 }
 
 func (r bot) getMvnProject(p *robots.Payload, id string) (*mavenlink.Project, error) {
-	mvn, err := mavenlink.NewFor(p.UserName)
+	mvn, err := mavenlinkFor(p)
 	if err != nil {
 		return nil, err
 	}
@@ -854,7 +991,7 @@ func (r bot) getMvnProject(p *robots.Payload, id string) (*mavenlink.Project, er
 }
 
 func (r bot) getPvtProject(p *robots.Payload, id string) (*pivotal.Project, error) {
-	pvt, err := pivotal.NewFor(p.UserName)
+	pvt, err := pivotalFor(p)
 	if err != nil {
 		return nil, err
 	}
@@ -862,7 +999,7 @@ func (r bot) getPvtProject(p *robots.Payload, id string) (*pivotal.Project, erro
 }
 
 func (r bot) makeLink(p *robots.Payload, name string, mvnId string, pvtId string) error {
-	prj, err := db.GetProjectByName(name)
+	prj, err := db.GetProjectByName(p.TeamID, name)
 	if err != nil {
 		return err
 	}
@@ -891,13 +1028,14 @@ func (r bot) makeLink(p *robots.Payload, name string, mvnId string, pvtId string
 	pvtInt := pvtProject.Id
 
 	project := db.Project{
+		TeamID:      p.TeamID,
 		Name:        name,
 		MavenlinkId: mvnInt,
 		PivotalId:   pvtInt,
 		CreatedBy:   p.UserName,
+		DryRun:      p.DryRun,
 	}
-	err = db.CreateProject(project)
-	if err != nil {
+	if err := db.CreateProject(project); err != nil {
 		return err
 	}
 
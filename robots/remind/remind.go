@@ -0,0 +1,185 @@
+package remind
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gistia/slackbot/db"
+	"github.com/gistia/slackbot/robots"
+	"github.com/gistia/slackbot/utils"
+)
+
+type bot struct {
+	handler utils.SlackHandler
+}
+
+func init() {
+	handler := utils.NewSlackHandler("Remind", ":alarm_clock:")
+	s := &bot{handler: handler}
+	robots.RegisterRobot("remind", s)
+}
+
+func (r bot) Run(p *robots.Payload) string {
+	go r.DeferredAction(p)
+	return ""
+}
+
+func (r bot) DeferredAction(p *robots.Payload) {
+	if isTarget(utils.NewCommand(p.Text).Command) {
+		if err := r.create(p); err != nil {
+			r.handler.SendError(p, err)
+		}
+		return
+	}
+
+	ch := utils.NewCmdHandler(p, r.handler, "remind")
+	ch.Handle("list", r.list)
+	ch.HandleMany([]string{"del", "delete", "remove", "cancel"}, r.remove)
+	ch.HandleDefault(r.list)
+	ch.Process(p.Text)
+}
+
+func isTarget(s string) bool {
+	return s == "me" || strings.HasPrefix(s, "@") || strings.HasPrefix(s, "#")
+}
+
+func (r bot) create(p *robots.Payload) error {
+	usage := "Use `!remind <me|@user|#channel> <message> <when>`, like `!remind #dev to update estimates every friday at 4pm`"
+
+	parts := strings.SplitN(strings.TrimSpace(p.Text), " ", 2)
+	if len(parts) < 2 {
+		return errors.New("Missing message. " + usage)
+	}
+
+	target := parts[0]
+	if target == "me" {
+		target = "@" + p.UserName
+	}
+	if len(target) < 2 {
+		return errors.New("Missing user or channel. " + usage)
+	}
+	if err := utils.CheckTarget(target); err != nil {
+		if err == utils.ErrUnknownTarget {
+			return errors.New("*" + target + "* isn't a user or channel I can remind")
+		}
+		return err
+	}
+
+	loc := utils.UserLocation(p.UserID)
+	text, schedule, err := utils.ParseSchedule(parts[1], time.Now().In(loc))
+	if err != nil {
+		return err
+	}
+
+	msg := strings.TrimSpace(text)
+	msg = strings.TrimSpace(strings.TrimPrefix(msg, "to "))
+	if msg == "" {
+		return errors.New("Missing message. " + usage)
+	}
+
+	next := schedule.Next.UTC()
+	reminder := db.Reminder{
+		TeamID:     p.TeamID,
+		User:       p.UserName,
+		Target:     target,
+		Message:    msg,
+		Recurrence: schedule.Recurrence,
+		Timezone:   loc.String(),
+		NextRunAt:  &next,
+	}
+	if err := db.CreateReminder(reminder); err != nil {
+		return err
+	}
+
+	when := schedule.Next.Format("Mon, Jan _2 at 3:04pm MST")
+	if schedule.Recurrence != "" {
+		when = "every " + describeRecurrence(schedule.Recurrence) +
+			", starting " + when
+	}
+
+	who := target
+	if target == "@"+p.UserName {
+		who = "you"
+	}
+
+	r.handler.Send(p, fmt.Sprintf("I will remind %s to *%s* %s", who, msg, when))
+	return nil
+}
+
+func (r bot) list(p *robots.Payload, cmd utils.Command) error {
+	reminders, err := db.GetReminders(p.TeamID, p.UserName)
+	if err != nil {
+		return err
+	}
+
+	if len(reminders) < 1 {
+		r.handler.Send(p, "You have no pending reminders")
+		return nil
+	}
+
+	s := "Your pending reminders:\n"
+	for _, rm := range reminders {
+		s += fmt.Sprintf("%d - *%s* for %s %s\n", rm.ID, rm.Message, rm.Target, when(rm))
+	}
+
+	r.handler.Send(p, s)
+	return nil
+}
+
+func (r bot) remove(p *robots.Payload, cmd utils.Command) error {
+	args, err := cmd.ParseArgs("reminder-id")
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return err
+	}
+
+	reminder, err := db.GetReminder(id)
+	if err != nil {
+		return err
+	}
+	if reminder == nil || reminder.TeamID != db.TeamKey(p.TeamID) ||
+		reminder.User != p.UserName || reminder.Done {
+		return errors.New("You have no pending reminder with id *" + args[0] + "*")
+	}
+
+	if err := reminder.Cancel(); err != nil {
+		return err
+	}
+
+	r.handler.Send(p, "Reminder *"+reminder.Message+"* cancelled")
+	return nil
+}
+
+func when(rm db.Reminder) string {
+	loc := rm.Location()
+	next := rm.NextRunAt
+	if rm.SnoozedUntil != nil && (next == nil || rm.SnoozedUntil.Before(*next)) {
+		next = rm.SnoozedUntil
+	}
+
+	s := ""
+	if rm.IsRecurring() {
+		s = "every " + describeRecurrence(rm.Recurrence) + ", "
+	}
+	if next == nil {
+		return s + "already delivered"
+	}
+
+	return s + "next on " + next.In(loc).Format("Mon, Jan _2 at 3:04pm MST")
+}
+
+func describeRecurrence(rec string) string {
+	parts := strings.Split(rec, " ")
+	return strings.Title(parts[0]) + " at " + parts[len(parts)-1]
+}
+
+func (r bot) Description() (description string) {
+	return "Remind bot\n\tUsage: !remind <me|@user|#channel> <message> <when>\n"
+}
@@ -1,6 +1,11 @@
 package robots
 
-import "log"
+import (
+	"fmt"
+	"log"
+
+	"github.com/gistia/slackbot/dryrun"
+)
 
 // Robot describes the necessary methods to be registered as a slack bot
 type Robot interface {
@@ -8,6 +13,32 @@ type Robot interface {
 	Description() (description string)
 }
 
+// DryRunner is implemented by robots whose writes go through the payload's
+// DryRun recorder, so they can be safely run with --dry-run
+type DryRunner interface {
+	SupportsDryRun() bool
+}
+
+// SupportsDryRun tells if r can run commands with --dry-run
+func SupportsDryRun(r Robot) bool {
+	dr, ok := r.(DryRunner)
+	return ok && dr.SupportsDryRun()
+}
+
+// DryRunError returns an error message if the command was run with
+// --dry-run but one of its robots would make real changes anyway
+func DryRunError(name string, rs []Robot, rec *dryrun.Recorder) string {
+	if !rec.Active() {
+		return ""
+	}
+	for _, r := range rs {
+		if !SupportsDryRun(r) {
+			return fmt.Sprintf("%s doesn't support %s", name, dryrun.Flag)
+		}
+	}
+	return ""
+}
+
 // Robots is the map of registered command to robot
 var Robots = make(map[string][]Robot)
 
@@ -16,3 +47,8 @@ func RegisterRobot(command string, r Robot) {
 	log.Printf("Registered: %s", command)
 	Robots[command] = append(Robots[command], r)
 }
+
+// Get returns the robots registered for command
+func Get(command string) []Robot {
+	return Robots[command]
+}
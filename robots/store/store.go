@@ -1,6 +1,7 @@
 package robots
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -29,28 +30,56 @@ func (r bot) DeferredAction(p *robots.Payload) {
 	ch.HandleDefault(r.list)
 	ch.Handle("list", r.list)
 	ch.Handle("set", r.set)
+	ch.Handle("explain", r.explain)
 	ch.HandleMany([]string{"rem", "del", "remove", "delete"}, r.remove)
 	ch.Process(p.Text)
 }
 
+// scope returns the scope and owner within the team the command applies
+// to, given by the --channel and --team flags and defaulting to the user.
+// Team settings are owned by the team itself, so their owner is empty
+func scope(p *robots.Payload, cmd utils.Command) (string, string, string) {
+	if cmd.Flag("team") {
+		return db.ScopeTeam, "", "the team"
+	}
+	if cmd.Flag("channel") {
+		return db.ScopeChannel, p.ChannelName, "#" + p.ChannelName
+	}
+	return db.ScopeUser, p.UserName, "@" + p.UserName
+}
+
+// canChange returns an error unless the user may change the settings of
+// the scope, which for the team and channels, whose settings apply to
+// everyone there, is only admins
+func canChange(p *robots.Payload, sc string) error {
+	if sc != db.ScopeUser && !utils.IsAdmin(p.TeamID, p.UserID) {
+		return errors.New("Only admins can change " + sc + " settings")
+	}
+	return nil
+}
+
 func (r bot) remove(p *robots.Payload, cmd utils.Command) error {
 	name := cmd.Arg(0)
 	if name == "" {
-		r.handler.Send(p, "Use /store remove PARAM.\n")
+		r.handler.Send(p, "Use /store remove PARAM [--channel|--team].\n")
 		return nil
 	}
 
-	ok, err := db.RemoveSetting(p.UserName, name)
+	sc, owner, desc := scope(p, cmd)
+	if err := canChange(p, sc); err != nil {
+		return err
+	}
+	ok, err := db.RemoveScopedSetting(p.TeamID, sc, owner, name)
 	if err != nil {
 		return err
 	}
 
 	if ok {
-		r.handler.Send(p, fmt.Sprintf("Successfully removed %s\n", name))
+		r.handler.Send(p, fmt.Sprintf("Successfully removed %s for %s\n", name, desc))
 		return nil
 	}
 
-	r.handler.Send(p, fmt.Sprintf("Setting %s not found\n", name))
+	r.handler.Send(p, fmt.Sprintf("Setting %s not found for %s\n", name, desc))
 	return nil
 }
 
@@ -58,35 +87,90 @@ func (r bot) set(p *robots.Payload, cmd utils.Command) error {
 	s := cmd.Arg(0)
 	parts := strings.Split(s, "=")
 	if len(parts) < 2 {
-		r.handler.Send(p, "Malformed setting. Use /store set PARAM=value.\n")
+		r.handler.Send(p, "Malformed setting. Use /store set PARAM=value [--channel|--team].\n")
 		return nil
 	}
 
 	name := strings.TrimSpace(parts[0])
 	value := strings.TrimSpace(parts[1])
-	err := db.SetSetting(p.UserName, name, value)
+	sc, owner, desc := scope(p, cmd)
+	if err := canChange(p, sc); err != nil {
+		return err
+	}
+	err := db.SetScopedSetting(p.TeamID, sc, owner, name, value)
+	if err != nil {
+		return err
+	}
+
+	r.handler.Send(p, fmt.Sprintf("Successfully set %s for %s\n", name, desc))
+	return nil
+}
+
+func (r bot) explain(p *robots.Payload, cmd utils.Command) error {
+	name := cmd.Arg(0)
+	if name == "" {
+		r.handler.Send(p, "Use /store explain PARAM.\n")
+		return nil
+	}
+
+	settings, err := db.ExplainSetting(utils.SettingContext(p), name)
 	if err != nil {
 		return err
 	}
 
-	r.handler.Send(p, fmt.Sprintf("Successfully set %s\n", name))
+	if len(settings) < 1 {
+		r.handler.Send(p, fmt.Sprintf("%s isn't set for you, #%s or the team\n",
+			name, p.ChannelName))
+		return nil
+	}
+
+	res := ""
+	for i, s := range settings {
+		value := s.Value
+		if db.IsSecretSetting(s.Name) {
+			value = "[secret]"
+		}
+
+		if i == 0 {
+			res += fmt.Sprintf("%s is `%s`, set %s\n", name, value, scopeDesc(s))
+			continue
+		}
+		res += fmt.Sprintf("It overrides `%s`, set %s\n", value, scopeDesc(s))
+	}
+
+	r.handler.Send(p, res)
 	return nil
 }
 
+func scopeDesc(s db.Setting) string {
+	switch s.Scope {
+	case db.ScopeTeam:
+		return "for the team"
+	case db.ScopeChannel:
+		return "for #" + s.User
+	}
+	return "by @" + s.User
+}
+
 func (r bot) list(p *robots.Payload, cmd utils.Command) error {
-	settings, err := db.GetSettings(p.UserName)
+	sc, owner, desc := scope(p, cmd)
+	settings, err := db.GetScopedSettings(p.TeamID, sc, owner)
 	if err != nil {
 		return err
 	}
 
 	if len(settings) < 1 {
-		s := fmt.Sprintf("No settings for @%s\n", p.UserName)
+		s := fmt.Sprintf("No settings for %s\n", desc)
 		r.handler.Send(p, s)
 		return nil
 	}
 
-	res := "You have the following settings configured:\n"
+	res := fmt.Sprintf("Settings configured for %s:\n", desc)
 	for _, s := range settings {
+		if db.IsSecretSetting(s.Name) {
+			res += fmt.Sprintf("%s (secret)\n", s.Name)
+			continue
+		}
 		res += fmt.Sprintf("%s\n", s.Name)
 	}
 
@@ -95,5 +179,7 @@ func (r bot) list(p *robots.Payload, cmd utils.Command) error {
 }
 
 func (r bot) Description() (description string) {
-	return "Store bot\n\tUsage: !store <command>\n"
+	return "Store bot\n\tUsage: !store <command>\n" +
+		"\tSettings apply to you unless --channel or --team is given. " +
+		"Yours override the channel's, which override the team's.\n"
 }
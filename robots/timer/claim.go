@@ -0,0 +1,389 @@
+package timer
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gistia/slackbot/db"
+	"github.com/gistia/slackbot/mavenlink"
+	"github.com/gistia/slackbot/pivotal"
+	"github.com/gistia/slackbot/robots"
+	"github.com/gistia/slackbot/utils"
+)
+
+const claimUsage = "Use `!timer claim <name> [<story>[=<share>] ...] [notes] " +
+	"[--billable|--non-billable] [--force]`, where a story is a Pivotal id or `mvn:<id>` " +
+	"and a share is like `60%` or `1h30m`"
+
+// claimTarget is a story the time of a timer is claimed to, with the share
+// given for it, a percentage or a duration, and the minutes it gets
+type claimTarget struct {
+	spec      string
+	pivotalID string
+	mvnID     string
+	percent   int
+	duration  int
+	minutes   int
+	story     *mavenlink.Story
+}
+
+// claim stops the timer and logs its time to the Mavenlink stories given,
+// directly or linked to Pivotal stories, split by the shares given, or to
+// the story the timer was started for. The
+// entries are dated the day the timer ran the most and carry the notes
+// given, or the timer's own notes. Stories the timer was already claimed
+// to are left out and only the minutes not claimed yet are split, so a
+// claim that failed halfway can be run again but no time is logged twice
+func (r bot) claim(p *robots.Payload, cmd utils.Command) error {
+	name, rest := rawArgs(p)
+	if name == "" {
+		return errors.New("Missing timer name. " + claimUsage)
+	}
+	targets, notes, err := parseClaimTargets(rest)
+	if err != nil {
+		return err
+	}
+	if cmd.Flag("billable") && cmd.Flag("non-billable") {
+		return errors.New("A time entry can't be both `--billable` and `--non-billable`")
+	}
+
+	timer, err := db.GetTimerByName(p.TeamID, p.UserName, name)
+	if err != nil {
+		return err
+	}
+	if timer == nil {
+		return errors.New("You have no timer with name *" + name + "*")
+	}
+	timer.DryRun = p.DryRun
+
+	if len(targets) < 1 {
+		targets, err = linkedTarget(timer)
+		if err != nil {
+			return err
+		}
+	}
+
+	claims, err := timer.Claims()
+	if err != nil {
+		return err
+	}
+	pending := unclaimed(targets, claims)
+	if len(pending) < 1 || (len(claims) > 0 && timer.Minutes() <= claimedMinutes(claims)) {
+		return fmt.Errorf("Timer *%s* was already claimed to %s", timer.Name, describeClaims(claims))
+	}
+
+	loc := utils.UserLocation(p.UserID)
+	if !cmd.Flag("force") {
+		reason, err := utils.ForgottenTimer(timer, utils.SettingContext(p), loc)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			return fmt.Errorf("Timer *%s* looks like it was left running: %s. "+
+				"Fix it with `stop %s at <time>` or `adjust %s -<duration>`, "+
+				"or claim it anyway with `--force`", timer.Name, reason, timer.Name, timer.Name)
+		}
+	}
+
+	if err := splitMinutes(timer.Minutes(), claims, pending); err != nil {
+		return err
+	}
+
+	mvn, err := r.resolveTargets(p, pending)
+	if err != nil {
+		return err
+	}
+
+	if !timer.IsFinished() {
+		if err := timer.Stop(); err != nil {
+			return err
+		}
+		r.handler.Send(p, "Timer *"+timer.Name+"* stopped.")
+		if stopped, err := timer.Reload(); err == nil && stopped != nil {
+			timer = stopped
+		}
+
+		// the timer ran a little longer while the stories were resolved
+		if err := splitMinutes(timer.Minutes(), claims, pending); err != nil {
+			return err
+		}
+	}
+
+	opts, err := entryOptions(timer, cmd, notes, loc)
+	if err != nil {
+		return err
+	}
+
+	s := ""
+	if len(pending) < len(targets) {
+		s = fmt.Sprintf("Skipped what timer *%s* was already claimed to: %s.\n",
+			timer.Name, describeClaims(claims))
+	}
+	for _, t := range pending {
+		entry, err := mvn.AddTimeEntryWith(t.story, t.minutes, opts)
+		if err != nil {
+			return errors.New(s + "Error claiming to story " + t.story.Id + ": " + err.Error())
+		}
+
+		c := db.TimerClaim{
+			PivotalStory: t.pivotalID, MavenlinkStory: t.story.Id, Minutes: t.minutes,
+		}
+		if entry != nil {
+			c.TimeEntryID = entry.ID
+		}
+		if err := timer.AddClaim(c); err != nil {
+			return err
+		}
+
+		s += fmt.Sprintf("Added *%d* minutes to story *%s - %s*\n", t.minutes, t.story.Id, t.story.Title)
+	}
+
+	r.handler.Send(p, s+fmt.Sprintf("Performed on *%s*.", opts.Date.Format("Mon, Jan _2")))
+	return nil
+}
+
+// unclaimed returns the targets the timer wasn't claimed to yet, the same
+// story given by its Pivotal or Mavenlink id
+func unclaimed(targets []*claimTarget, claims []db.TimerClaim) []*claimTarget {
+	pending := []*claimTarget{}
+	for _, t := range targets {
+		claimed := false
+		for _, c := range claims {
+			if (t.pivotalID != "" && t.pivotalID == c.PivotalStory) ||
+				(t.mvnID != "" && t.mvnID == c.MavenlinkStory) {
+				claimed = true
+				break
+			}
+		}
+		if !claimed {
+			pending = append(pending, t)
+		}
+	}
+	return pending
+}
+
+// linkedTarget returns the story the timer was started for, to claim it to
+// when no stories are given
+func linkedTarget(timer *db.Timer) ([]*claimTarget, error) {
+	t := &claimTarget{pivotalID: timer.PivotalStory, mvnID: timer.MavenlinkStory}
+	switch {
+	case t.pivotalID != "":
+		t.spec = t.pivotalID
+	case t.mvnID != "":
+		t.spec = "mvn:" + t.mvnID
+	default:
+		return nil, errors.New("Missing story, timer *" + timer.Name + "* wasn't started for one. " + claimUsage)
+	}
+	return []*claimTarget{t}, nil
+}
+
+// parseClaimTargets reads the stories at the start of text, returning the
+// text after them as the notes of the time entries
+func parseClaimTargets(text string) ([]*claimTarget, string, error) {
+	fields := strings.Fields(text)
+	targets := []*claimTarget{}
+	for len(fields) > 0 {
+		t, ok, err := parseClaimTarget(fields[0])
+		if err != nil {
+			return nil, "", err
+		}
+		if !ok {
+			break
+		}
+		targets = append(targets, t)
+		fields = fields[1:]
+	}
+
+	return targets, strings.Join(fields, " "), nil
+}
+
+// parseClaimTarget reads a story like 12345, mvn:678, 12345=60% or
+// mvn:678=1h30m. It returns false for words that aren't stories
+func parseClaimTarget(spec string) (*claimTarget, bool, error) {
+	t := &claimTarget{spec: spec}
+	story, share := spec, ""
+	if i := strings.Index(spec, "="); i >= 0 {
+		story, share = spec[:i], spec[i+1:]
+	}
+
+	if strings.HasPrefix(story, "mvn:") {
+		t.mvnID = strings.TrimPrefix(story, "mvn:")
+	} else {
+		t.pivotalID = story
+	}
+	if _, err := strconv.Atoi(t.pivotalID + t.mvnID); err != nil {
+		return nil, false, nil
+	}
+
+	switch {
+	case share == "":
+	case strings.HasSuffix(share, "%"):
+		pct, err := strconv.Atoi(strings.TrimSuffix(share, "%"))
+		if err != nil || pct <= 0 || pct > 100 {
+			return nil, false, errors.New("Invalid share *" + spec + "*, percentages go from 1% to 100%")
+		}
+		t.percent = pct
+	default:
+		d, err := time.ParseDuration(share)
+		if err != nil || d < time.Minute {
+			return nil, false, errors.New("Invalid share *" + spec + "*. " + claimUsage)
+		}
+		t.duration = int(d.Minutes())
+	}
+
+	return t, true, nil
+}
+
+// claimedMinutes returns the minutes of the timer already logged by claims
+func claimedMinutes(claims []db.TimerClaim) int {
+	minutes := 0
+	for _, c := range claims {
+		minutes += c.Minutes
+	}
+	return minutes
+}
+
+// splitMinutes gives every target its part of the total minutes the timer
+// ran that weren't claimed yet. Percentages are of the total, and targets
+// without a share split evenly what's left
+func splitMinutes(total int, claims []db.TimerClaim, targets []*claimTarget) error {
+	left, even := total-claimedMinutes(claims), []*claimTarget{}
+	for _, t := range targets {
+		t.minutes = t.duration
+		if t.percent > 0 {
+			t.minutes = total * t.percent / 100
+		}
+		if t.duration == 0 && t.percent == 0 {
+			even = append(even, t)
+			continue
+		}
+		left -= t.minutes
+	}
+
+	if left < 0 {
+		if len(claims) > 0 {
+			return fmt.Errorf("The shares add up to more than the *%d* minutes left of the *%d* the timer ran, "+
+				"the rest was claimed to %s", total-claimedMinutes(claims), total, describeClaims(claims))
+		}
+		return fmt.Errorf("The shares add up to more than the *%d* minutes the timer ran", total)
+	}
+	for i, t := range even {
+		t.minutes = left / len(even)
+		if i < left%len(even) {
+			t.minutes++
+		}
+	}
+
+	for _, t := range targets {
+		if t.minutes < 1 {
+			return fmt.Errorf("Story *%s* would get no time out of the *%d* minutes the timer ran",
+				t.spec, total)
+		}
+	}
+	return nil
+}
+
+// resolveTargets finds the Mavenlink story of every target before any time
+// is logged, so a bad story doesn't leave the timer half claimed
+func (r bot) resolveTargets(p *robots.Payload, targets []*claimTarget) (*mavenlink.Mavenlink, error) {
+	mvn, err := mavenlink.NewFor(p.TeamID, p.UserName)
+	if err != nil {
+		return nil, err
+	}
+	mvn.DryRun = p.DryRun
+
+	var pvt *pivotal.Pivotal
+	for _, t := range targets {
+		if t.mvnID == "" {
+			if pvt == nil {
+				if pvt, err = pivotal.NewFor(p.TeamID, p.UserName); err != nil {
+					return nil, err
+				}
+				pvt.DryRun = p.DryRun
+			}
+
+			task, err := pvt.GetStory(t.pivotalID)
+			if err != nil {
+				return nil, err
+			}
+			t.mvnID = task.GetMavenlinkId()
+			if t.mvnID == "" {
+				return nil, errors.New("Can't claim to " + t.pivotalID +
+					" because the Pivotal story doesn't have a mavenlink tag like `[mvn:<id>]`")
+			}
+		}
+
+		t.story, err = mvn.GetStory(t.mvnID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return mvn, nil
+}
+
+// entryOptions returns the details of the time entries of a timer
+func entryOptions(timer *db.Timer, cmd utils.Command, notes string, loc *time.Location) (mavenlink.TimeEntryOptions, error) {
+	opts := mavenlink.TimeEntryOptions{Notes: notes}
+	if opts.Notes == "" {
+		opts.Notes = timer.Notes
+	}
+
+	switch {
+	case cmd.Flag("billable"):
+		billable := true
+		opts.Billable = &billable
+	case cmd.Flag("non-billable"):
+		billable := false
+		opts.Billable = &billable
+	}
+
+	var err error
+	opts.Date, err = workDate(timer, loc)
+	return opts, err
+}
+
+// workDate returns the day, in loc, the timer ran the most
+func workDate(timer *db.Timer, loc *time.Location) (time.Time, error) {
+	segments, err := timer.Segments()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	start, end := time.Now().In(loc), time.Now()
+	if timer.CreatedAt != nil {
+		start = timer.CreatedAt.In(loc)
+	}
+	if timer.FinishedAt != nil {
+		end = *timer.FinishedAt
+	}
+
+	best, most := start, time.Duration(-1)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	for ; day.Before(end); day = day.AddDate(0, 0, 1) {
+		if d := timer.ActiveIn(segments, day, day.AddDate(0, 0, 1)); d > most {
+			best, most = day, d
+		}
+	}
+	return best, nil
+}
+
+// describeClaims lists the stories and time entries of claims
+func describeClaims(claims []db.TimerClaim) string {
+	s := []string{}
+	for _, c := range claims {
+		entry := ""
+		if c.TimeEntryID != "" {
+			entry = " as time entry " + c.TimeEntryID
+		}
+		when := ""
+		if c.CreatedAt != nil {
+			when = " on " + c.CreatedAt.Format("Mon, Jan _2")
+		}
+		s = append(s, fmt.Sprintf("story *%s* (%d minutes%s%s)", c.MavenlinkStory, c.Minutes, entry, when))
+	}
+	return strings.Join(s, ", ")
+}
@@ -0,0 +1,161 @@
+package timer
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gistia/slackbot/db"
+	"github.com/gistia/slackbot/dialog"
+	"github.com/gistia/slackbot/robots"
+)
+
+func (r bot) registerDialogs() {
+	// timer.away is started by the bot user when someone comes back with
+	// timers paused while they were away. It's given the ids of the timers
+	// and when the user left and came back
+	dialog.Register(dialog.Dialog{
+		Name: "timer.away",
+		Steps: []dialog.Step{
+			{
+				Name:     "keep",
+				Prompt:   "Should I count the time you were away on them? (keep/discard)",
+				Validate: validateKeep,
+			},
+		},
+		Run: func(p *robots.Payload, a dialog.Answers) error {
+			if a["keep"] != "keep" {
+				r.handler.Send(p, "Ok, the time you were away won't be counted.")
+				return nil
+			}
+			return r.keepAwayTime(p, a["timers"], a["from"], a["to"])
+		},
+	})
+
+	// timer.forgotten is started by the bot user for timers that look left
+	// running. It's given the id of the timer and when the user was last
+	// seen active
+	dialog.Register(dialog.Dialog{
+		Name: "timer.forgotten",
+		Steps: []dialog.Step{
+			{
+				Name:     "action",
+				Prompt:   "Should I `stop` it when you were last active, `keep` it running or `discard` it?",
+				Validate: validateForgotten,
+			},
+		},
+		Run: func(p *robots.Payload, a dialog.Answers) error {
+			return r.forgotten(p, a["timer"], a["action"], a["last"])
+		},
+	})
+}
+
+func validateKeep(p *robots.Payload, a dialog.Answers, s string) (string, error) {
+	switch strings.ToLower(s) {
+	case "keep", "k", "yes", "y":
+		return "keep", nil
+	case "discard", "d", "no", "n":
+		return "discard", nil
+	}
+	return "", errors.New("Please answer `keep` or `discard`.")
+}
+
+func validateForgotten(p *robots.Payload, a dialog.Answers, s string) (string, error) {
+	switch s = strings.ToLower(s); s {
+	case "stop", "keep", "discard":
+		return s, nil
+	}
+	return "", errors.New("Please answer `stop`, `keep` or `discard`.")
+}
+
+// forgotten stops, keeps or discards a timer the user left running
+func (r bot) forgotten(p *robots.Payload, id, action, last string) error {
+	timerID, err := strconv.Atoi(id)
+	if err != nil {
+		return err
+	}
+
+	timer, err := db.GetTimer(timerID)
+	if err != nil {
+		return err
+	}
+	if timer == nil || timer.User != p.UserName || timer.IsFinished() {
+		r.handler.Send(p, "That timer isn't running anymore.")
+		return nil
+	}
+
+	switch action {
+	case "stop":
+		at, err := time.Parse(time.RFC3339Nano, last)
+		if err != nil {
+			return err
+		}
+		if err := timer.StopAt(at); err != nil {
+			return err
+		}
+		if timer, err = timer.Reload(); err != nil {
+			return err
+		}
+		r.handler.Send(p, "Stopped timer *"+timer.Name+"*. It ran for *"+timer.Duration()+"*.")
+	case "discard":
+		if err := timer.Delete(); err != nil {
+			return err
+		}
+		r.handler.Send(p, "Discarded timer *"+timer.Name+"*.")
+	default:
+		r.handler.Send(p, "Ok, timer *"+timer.Name+"* keeps running.")
+	}
+
+	return nil
+}
+
+// keepAwayTime counts the time between from and to on the user's timers,
+// never before each timer started
+func (r bot) keepAwayTime(p *robots.Payload, ids, from, to string) error {
+	start, err := time.Parse(time.RFC3339Nano, from)
+	if err != nil {
+		return err
+	}
+	end, err := time.Parse(time.RFC3339Nano, to)
+	if err != nil {
+		return err
+	}
+
+	names := []string{}
+	for _, s := range strings.Split(ids, ",") {
+		id, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+
+		timer, err := db.GetTimer(id)
+		if err != nil {
+			return err
+		}
+		if timer == nil || timer.User != p.UserName {
+			continue
+		}
+
+		segStart := start
+		if timer.CreatedAt != nil && timer.CreatedAt.After(segStart) {
+			segStart = *timer.CreatedAt
+		}
+		if !end.After(segStart) {
+			continue
+		}
+
+		if err := timer.AddSegment(segStart, end); err != nil {
+			return err
+		}
+		names = append(names, "*"+timer.Name+"*")
+	}
+
+	if len(names) < 1 {
+		r.handler.Send(p, "None of those timers needed the time added.")
+		return nil
+	}
+
+	r.handler.Send(p, "Ok, the time you were away was added to "+strings.Join(names, ", ")+".")
+	return nil
+}
@@ -0,0 +1,370 @@
+package timer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gistia/slackbot/db"
+	"github.com/gistia/slackbot/pivotal"
+	"github.com/gistia/slackbot/robots"
+	"github.com/gistia/slackbot/utils"
+)
+
+type bot struct {
+	handler utils.SlackHandler
+}
+
+func init() {
+	handler := utils.NewSlackHandler("Timer", ":stopwatch:")
+	s := &bot{handler: handler}
+	s.registerDialogs()
+	robots.RegisterRobot("timer", s)
+}
+
+func (r bot) Run(p *robots.Payload) string {
+	go r.DeferredAction(p)
+	return ""
+}
+
+// SupportsDryRun tells every timer command honors --dry-run
+func (r bot) SupportsDryRun() bool {
+	return true
+}
+
+func (r bot) DeferredAction(p *robots.Payload) {
+	ch := utils.NewCmdHandler(p, r.handler, "timer")
+	ch.Handle("start", r.start)
+	ch.Handle("stop", r.stop)
+	ch.Handle("pause", r.pause)
+	ch.Handle("resume", r.resume)
+	ch.Handle("adjust", r.adjust)
+	ch.Handle("note", r.note)
+	ch.Handle("status", r.status)
+	ch.HandleMany([]string{"list", "timers"}, r.list)
+	ch.Handle("claim", r.claim)
+	ch.Handle("tasks", r.tasks)
+	ch.HandleDefault(r.list)
+	ch.Process(p.Text)
+}
+
+// start creates a timer, running from now or from a time given like
+// `40m ago` or `at 9:30`
+func (r bot) start(p *robots.Payload, cmd utils.Command) error {
+	name, when := rawArgs(p)
+	if name == "" {
+		return errors.New("Missing timer name. Use `!timer start <name> [40m ago|at 9:30]`")
+	}
+
+	running, err := db.GetStartedTimerByName(p.TeamID, p.UserName, name)
+	if err != nil {
+		return err
+	}
+	if running != nil {
+		return errors.New("You already have a timer *" + name + "* running. " +
+			"Stop it first or pick another name")
+	}
+
+	at, err := r.parseWhen(p, when)
+	if err != nil {
+		return err
+	}
+
+	err = db.CreateTimer(p.TeamID, p.UserName, name, at, p.DryRun)
+	if err != nil {
+		return err
+	}
+
+	if when == "" {
+		r.handler.Send(p, "Created timer *"+name+"*")
+	} else {
+		r.handler.Send(p, "Created timer *"+name+"* started at *"+at.Format("Mon 3:04pm")+"*")
+	}
+	return nil
+}
+
+// stop finishes a timer now or at a time given like `at 17:30`
+func (r bot) stop(p *robots.Payload, cmd utils.Command) error {
+	name, when := rawArgs(p)
+	if name == "" {
+		return errors.New("Missing timer name. Use `!timer stop <name> [10m ago|at 17:30]`")
+	}
+
+	timer, err := db.GetStartedTimerByName(p.TeamID, p.UserName, name)
+	if err != nil {
+		return err
+	}
+	if timer == nil {
+		return errors.New("You have no started timer with name *" + name + "*")
+	}
+	timer.DryRun = p.DryRun
+
+	at, err := r.parseWhen(p, when)
+	if err != nil {
+		return err
+	}
+	if timer.CreatedAt != nil && at.Before(*timer.CreatedAt) {
+		return errors.New("Timer *" + name + "* only started at *" +
+			timer.CreatedAt.In(at.Location()).Format("Mon 3:04pm") + "*")
+	}
+
+	if err := timer.StopAt(at); err != nil {
+		return err
+	}
+
+	if timer, err = timer.Reload(); err != nil {
+		return err
+	}
+
+	r.handler.Send(p, "Your timer *"+name+"* has stopped. It ran for *"+timer.Duration()+"*.")
+	return nil
+}
+
+// pause stops counting time on a running timer until it's resumed
+func (r bot) pause(p *robots.Payload, cmd utils.Command) error {
+	timer, err := r.runningTimer(p, cmd, "pause")
+	if err != nil {
+		return err
+	}
+	if timer.IsPaused() {
+		return errors.New("Timer *" + timer.Name + "* is already paused")
+	}
+
+	if err := timer.Pause(time.Now(), db.PauseManual); err != nil {
+		return err
+	}
+
+	r.handler.Send(p, "Paused timer *"+timer.Name+"*. It ran for *"+timer.Duration()+"*.")
+	return nil
+}
+
+func (r bot) resume(p *robots.Payload, cmd utils.Command) error {
+	timer, err := r.runningTimer(p, cmd, "resume")
+	if err != nil {
+		return err
+	}
+	if !timer.IsPaused() {
+		return errors.New("Timer *" + timer.Name + "* isn't paused")
+	}
+
+	if err := timer.Resume(time.Now()); err != nil {
+		return err
+	}
+
+	r.handler.Send(p, "Resumed timer *"+timer.Name+"*")
+	return nil
+}
+
+// adjust corrects the time a timer ran by a duration like +15m or -1h
+func (r bot) adjust(p *robots.Payload, cmd utils.Command) error {
+	args, err := cmd.ParseArgs("timer-name", "duration")
+	if err != nil {
+		return err
+	}
+
+	d, err := time.ParseDuration(args[1])
+	if err != nil {
+		return errors.New("Invalid duration *" + args[1] + "*. Use something like `+15m` or `-1h`")
+	}
+
+	timer, err := db.GetTimerByName(p.TeamID, p.UserName, args[0])
+	if err != nil {
+		return err
+	}
+	if timer == nil {
+		return errors.New("You have no timer with name *" + args[0] + "*")
+	}
+	timer.DryRun = p.DryRun
+
+	if err := timer.Adjust(d); err != nil {
+		return err
+	}
+
+	if timer, err = timer.Reload(); err != nil {
+		return err
+	}
+
+	r.handler.Send(p, "Adjusted timer *"+timer.Name+"* by *"+d.String()+"*. It ran for *"+timer.Duration()+"*.")
+	return nil
+}
+
+func (r bot) note(p *robots.Payload, cmd utils.Command) error {
+	name, text := rawArgs(p)
+	if name == "" || text == "" {
+		return errors.New("Use `!timer note <name> <text>`")
+	}
+
+	timer, err := db.GetTimerByName(p.TeamID, p.UserName, name)
+	if err != nil {
+		return err
+	}
+	if timer == nil {
+		return errors.New("You have no timer with name *" + name + "*")
+	}
+	timer.DryRun = p.DryRun
+
+	if err := timer.AddNote(text); err != nil {
+		return err
+	}
+
+	r.handler.Send(p, "Added a note to timer *"+timer.Name+"*")
+	return nil
+}
+
+func (r bot) status(p *robots.Payload, cmd utils.Command) error {
+	name := cmd.Arg(0)
+	if name == "" {
+		return errors.New("Missing timer name. Use `!timer status <name>`")
+	}
+
+	timer, err := db.GetTimerByName(p.TeamID, p.UserName, name)
+	if err != nil {
+		return err
+	}
+	if timer == nil {
+		return errors.New("You have no timer with name *" + name + "*")
+	}
+
+	if timer.IsFinished() {
+		r.handler.Send(p, "Your timer *"+name+"* is finished. It ran for *"+timer.Duration()+"*.")
+	} else if timer.IsPaused() {
+		r.handler.Send(p, "Your timer *"+name+"* is paused. It ran for *"+timer.Duration()+"* so far.")
+	} else {
+		r.handler.Send(p, "Your timer *"+name+"* has been running for *"+timer.Duration()+"*")
+	}
+	if timer.Notes != "" {
+		r.handler.Send(p, "Notes:\n"+timer.Notes)
+	}
+	return nil
+}
+
+func (r bot) list(p *robots.Payload, cmd utils.Command) error {
+	timers, err := db.GetRunningTimers(p.TeamID, p.UserName)
+	if err != nil {
+		return err
+	}
+
+	if len(timers) < 1 {
+		r.handler.Send(p, "You have no running timers")
+		return nil
+	}
+
+	s := "You have the following running timers:\n"
+	for _, t := range timers {
+		s += fmt.Sprintf("- *%s* %s for *%s*\n", t.Name, t.Status(), t.Duration())
+	}
+	r.handler.Send(p, s)
+	return nil
+}
+
+// tasks lists the Pivotal stories the user has started in the linked
+// projects
+func (r bot) tasks(p *robots.Payload, cmd utils.Command) error {
+	projects, err := db.GetProjects(p.TeamID)
+	if err != nil {
+		return err
+	}
+
+	if len(projects) < 1 {
+		r.handler.Send(p, "There are no linked projects currently. Use `/project link` command to add one.")
+		return nil
+	}
+
+	user, err := db.GetUserByName(p.TeamID, p.UserName)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("You aren't registered yet. Use `!user set " + p.UserName + " pvt:<pivotal-id>`")
+	}
+
+	pvt, err := pivotal.NewFor(p.TeamID, p.UserName)
+	if err != nil {
+		return err
+	}
+
+	msg := ""
+	for _, pr := range projects {
+		filter := map[string]string{
+			"owned_by": user.StrPivotalId(),
+			"state":    "started",
+		}
+		stories, err := pvt.FilteredStories(pr.StrPivotalId(), filter)
+		if err != nil {
+			return err
+		}
+
+		if len(stories) < 1 {
+			continue
+		}
+
+		msg += "Stories for *" + pr.Name + "*:\n"
+		for _, s := range stories {
+			msg += fmt.Sprintf("%d - %s - %s\n", s.Id, s.Name, s.State)
+		}
+	}
+
+	if msg == "" {
+		msg = "No started tasks for you"
+	}
+
+	r.handler.Send(p, msg)
+	return nil
+}
+
+// runningTimer returns the unfinished timer named by the first argument
+func (r bot) runningTimer(p *robots.Payload, cmd utils.Command, action string) (*db.Timer, error) {
+	name := cmd.Arg(0)
+	if name == "" {
+		return nil, errors.New("Missing timer name. Use `!timer " + action + " <name>`")
+	}
+
+	timer, err := db.GetStartedTimerByName(p.TeamID, p.UserName, name)
+	if err != nil {
+		return nil, err
+	}
+	if timer == nil {
+		return nil, errors.New("You have no started timer with name *" + name + "*")
+	}
+	timer.DryRun = p.DryRun
+	return timer, nil
+}
+
+// parseWhen reads a time in the past in the user's timezone, now when
+// none is given
+func (r bot) parseWhen(p *robots.Payload, when string) (time.Time, error) {
+	now := time.Now()
+	if when == "" {
+		return now, nil
+	}
+
+	at, err := utils.ParsePast(when, now.In(utils.UserLocation(p.UserID)))
+	if err != nil {
+		return now, err
+	}
+	if at.After(now) {
+		return now, errors.New("That time is in the future")
+	}
+	return at, nil
+}
+
+// rawArgs returns the first argument of the command and the text after it.
+// Commands split arguments like 17:30 into params, so it reads the raw text
+func rawArgs(p *robots.Payload) (string, string) {
+	fields := []string{}
+	for _, f := range strings.Fields(p.Text) {
+		if !strings.HasPrefix(f, "--") {
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) < 2 {
+		return "", ""
+	}
+
+	return fields[1], strings.Join(fields[2:], " ")
+}
+
+func (r bot) Description() (description string) {
+	return "Timer bot\n\tUsage: !timer <start|stop|pause|resume|adjust|note|status|list|claim|tasks>\n"
+}
@@ -0,0 +1,114 @@
+package timesheet
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gistia/slackbot/robots"
+	sheet "github.com/gistia/slackbot/timesheet"
+	"github.com/gistia/slackbot/utils"
+	"github.com/gistia/slackbot/web"
+)
+
+// linkTTL is how long CSV export links work
+const linkTTL = 24 * time.Hour
+
+type bot struct {
+	handler utils.SlackHandler
+}
+
+func init() {
+	handler := utils.NewSlackHandler("Timesheet", ":spiral_calendar_pad:")
+	s := &bot{handler: handler}
+	robots.RegisterRobot("timesheet", s)
+}
+
+func (r bot) Run(p *robots.Payload) string {
+	go r.DeferredAction(p)
+	return ""
+}
+
+func (r bot) DeferredAction(p *robots.Payload) {
+	ch := utils.NewCmdHandler(p, r.handler, "timesheet")
+	ch.HandleMany([]string{"day", "today", "week", "month"}, r.report)
+	ch.HandleDefault(r.report)
+
+	// the period is optional, so `!timesheet @user` reports on the day
+	text := p.Text
+	if f := strings.Fields(text); len(f) > 0 && (f[0] == "team" || strings.HasPrefix(f[0], "@")) {
+		text = "day " + text
+	}
+	ch.Process(text)
+}
+
+// report shows the time spent by the user, another user given as @user or
+// the whole team given as team, in the day, week or month
+func (r bot) report(p *robots.Payload, cmd utils.Command) error {
+	user := p.UserName
+	switch arg := cmd.Arg(0); {
+	case arg == "team":
+		user = ""
+	case strings.HasPrefix(arg, "@"):
+		user = strings.TrimPrefix(arg, "@")
+	}
+
+	now := time.Now().In(utils.UserLocation(p.UserID))
+	from, to, err := sheet.Period(cmd.Command, now)
+	if err != nil {
+		return err
+	}
+
+	report, err := sheet.Build(p.TeamID, user, from, to)
+	if err != nil {
+		return err
+	}
+
+	who := "*" + user + "*"
+	if user == "" {
+		who = "the team"
+	}
+	s := fmt.Sprintf("Timesheet for %s from *%s* to *%s*: *%s*\n", who,
+		from.Format("Mon, Jan _2"), to.AddDate(0, 0, -1).Format("Mon, Jan _2"),
+		sheet.FormatDuration(report.Total()))
+	if len(report.Entries) < 1 {
+		r.handler.Send(p, s+"No time tracked.")
+		return nil
+	}
+
+	if user == "" {
+		s += section("By user", report.ByUser())
+	}
+	s += section("By timer", report.ByTimer())
+	s += section("By story", report.ByStory())
+	if to.Sub(from) > 24*time.Hour {
+		s += section("By day", report.ByDay())
+	}
+
+	if cmd.Flag("csv") {
+		link, err := web.TimesheetLink(p.TeamID, user, from, to, time.Now().Add(linkTTL))
+		if err != nil {
+			return err
+		}
+		s += "CSV export, valid for 24 hours: " + link + "\n"
+	}
+
+	r.handler.Send(p, s)
+	return nil
+}
+
+func section(title string, totals []sheet.Total) string {
+	s := title + ":\n"
+	for _, t := range totals {
+		name := t.Name
+		if name == "" {
+			name = "not claimed"
+		}
+		s += fmt.Sprintf("- *%s* %s\n", name, sheet.FormatDuration(t.Duration))
+	}
+	return s
+}
+
+func (r bot) Description() (description string) {
+	return "Timesheet bot\n\tUsage: !timesheet [day|week|month] [@user|team] [--csv]\n"
+}
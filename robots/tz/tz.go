@@ -0,0 +1,328 @@
+package tz
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gistia/slackbot/db"
+	"github.com/gistia/slackbot/robots"
+	"github.com/gistia/slackbot/utils"
+	"github.com/nlopes/slack"
+)
+
+// slot is the step the meeting planner moves in
+const slot = 30 * time.Minute
+
+// planDays is how many days ahead the meeting planner looks
+const planDays = 7
+
+type bot struct {
+	handler utils.SlackHandler
+}
+
+// member is someone in the channel, with where and when they work
+type member struct {
+	name     string
+	loc      *time.Location
+	label    string
+	from, to int
+	out      *db.Vacation
+}
+
+func init() {
+	handler := utils.NewSlackHandler("Timezones", ":earth_americas:")
+	s := &bot{handler: handler}
+	robots.RegisterRobot("tz", s)
+}
+
+func (r bot) Run(p *robots.Payload) string {
+	go r.DeferredAction(p)
+	return ""
+}
+
+func (r bot) DeferredAction(p *robots.Payload) {
+	ch := utils.NewCmdHandler(p, r.handler, "tz")
+	ch.Handle("list", r.list)
+	ch.Handle("at", r.at)
+	ch.Handle("plan", r.plan)
+	ch.HandleDefault(r.list)
+	ch.Process(p.Text)
+}
+
+// list shows the local time of every member of the channel
+func (r bot) list(p *robots.Payload, cmd utils.Command) error {
+	members, err := r.members(p)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	s := "Local times in this channel:\n"
+	for _, m := range members {
+		s += fmt.Sprintf("- *%s* %s (%s)%s\n", m.name,
+			now.In(m.loc).Format("Mon 3:04pm"), m.label, m.away(now))
+	}
+
+	r.handler.Send(p, s)
+	return nil
+}
+
+// at converts a time given in the user's timezone, like "tue 3pm my time",
+// to the local time of every member of the channel
+func (r bot) at(p *robots.Payload, cmd utils.Command) error {
+	// times like 3:30pm would be taken as params, so the raw text is used
+	expr := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(p.Text), cmd.Command))
+	if expr == "" {
+		return errors.New("Missing time. Use `!tz at <time>`, like `!tz at tue 3pm my time`")
+	}
+
+	now := time.Now().In(utils.UserLocation(p.UserID))
+	t, err := parseWhen(expr, now)
+	if err != nil {
+		return err
+	}
+
+	members, err := r.members(p)
+	if err != nil {
+		return err
+	}
+
+	s := fmt.Sprintf("*%s* your time is:\n", t.Format("Mon, Jan _2 3:04pm"))
+	for _, m := range members {
+		note := ""
+		if !m.working(t) {
+			note = " (outside work hours)"
+		}
+		s += fmt.Sprintf("- *%s* %s%s%s\n", m.name, t.In(m.loc).Format("Mon 3:04pm"),
+			note, m.away(t))
+	}
+
+	r.handler.Send(p, s)
+	return nil
+}
+
+// plan suggests the next windows within everyone's work hours that are
+// long enough for a meeting, leaving out the members who are out
+func (r bot) plan(p *robots.Payload, cmd utils.Command) error {
+	length := time.Hour
+	if arg := cmd.Arg(0); arg != "" {
+		d, err := time.ParseDuration(arg)
+		if err != nil || d < slot {
+			return errors.New("Invalid meeting length *" + arg + "*. Use something like `30m` or `1h30m`")
+		}
+		length = d
+	}
+
+	members, err := r.members(p)
+	if err != nil {
+		return err
+	}
+
+	loc := utils.UserLocation(p.UserID)
+	windows := findWindows(members, time.Now().In(loc), length)
+	if len(windows) < 1 {
+		r.handler.Send(p, fmt.Sprintf("There's no %s window within everyone's work hours in the next %d days.",
+			length, planDays))
+		return nil
+	}
+
+	s := fmt.Sprintf("Windows of at least %s within everyone's work hours, in your time:\n", length)
+	for _, w := range windows {
+		s += fmt.Sprintf("- *%s* to *%s*\n", w[0].Format("Mon, Jan _2 3:04pm"), w[1].Format("3:04pm"))
+	}
+	for _, m := range members {
+		if m.out != nil {
+			s += fmt.Sprintf("*%s* is out until *%s* and was left out while away.\n",
+				m.name, m.out.EndDate.Format("Mon, Jan _2"))
+		}
+	}
+
+	r.handler.Send(p, s)
+	return nil
+}
+
+// findWindows returns up to five stretches of at least length when every
+// member that isn't out is within their work hours
+func findWindows(members []member, now time.Time, length time.Duration) [][2]time.Time {
+	windows := [][2]time.Time{}
+	start := now.Truncate(slot).Add(slot)
+	end := start.AddDate(0, 0, planDays)
+
+	var open *time.Time
+	for t := start; t.Before(end) && len(windows) < 5; t = t.Add(slot) {
+		free, present := true, 0
+		for _, m := range members {
+			if m.isOut(t) {
+				continue
+			}
+			present++
+			free = free && m.working(t)
+		}
+		free = free && present > 0
+
+		switch {
+		case free && open == nil:
+			from := t
+			open = &from
+		case !free && open != nil:
+			if t.Sub(*open) >= length {
+				windows = append(windows, [2]time.Time{*open, t})
+			}
+			open = nil
+		}
+	}
+	if open != nil && end.Sub(*open) >= length && len(windows) < 5 {
+		windows = append(windows, [2]time.Time{*open, end})
+	}
+
+	return windows
+}
+
+// members returns the people in the channel the command came from, or in
+// the whole team for direct messages, sorted by their UTC offset
+func (r bot) members(p *robots.Payload) ([]member, error) {
+	api := utils.SlackAPI()
+
+	ids := []string{}
+	switch {
+	case strings.HasPrefix(p.ChannelID, "C"):
+		ch, err := api.GetChannelInfo(p.ChannelID)
+		if err != nil {
+			return nil, err
+		}
+		ids = ch.Members
+	case strings.HasPrefix(p.ChannelID, "G"):
+		g, err := api.GetGroupInfo(p.ChannelID)
+		if err != nil {
+			return nil, err
+		}
+		ids = g.Members
+	default:
+		users, err := api.GetUsers()
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			ids = append(ids, u.Id)
+		}
+	}
+
+	vacations, err := db.GetCurrentVacations(p.TeamID)
+	if err != nil {
+		return nil, err
+	}
+
+	members := []member{}
+	offsets := map[string]int{}
+	for _, id := range ids {
+		u, err := api.GetUserInfo(id)
+		if err != nil {
+			return nil, err
+		}
+		if u.IsBot || u.Deleted {
+			continue
+		}
+
+		m, err := newMember(p, u)
+		if err != nil {
+			return nil, err
+		}
+		for i, v := range vacations {
+			if v.User == u.Name {
+				m.out = &vacations[i]
+			}
+		}
+
+		members = append(members, m)
+		offsets[m.name] = u.TZOffset
+	}
+
+	sort.SliceStable(members, func(i, j int) bool {
+		return offsets[members[i].name] < offsets[members[j].name]
+	})
+	return members, nil
+}
+
+func newMember(p *robots.Payload, u *slack.User) (member, error) {
+	m := member{name: u.Name, loc: utils.Location(u), label: u.TZLabel}
+	if m.label == "" {
+		m.label = m.loc.String()
+	}
+
+	var err error
+	m.from, m.to, err = utils.WorkHours(db.SettingContext{Team: p.TeamID, User: u.Name})
+	return m, err
+}
+
+// working returns true if t is on a weekday within the member's work hours
+func (m member) working(t time.Time) bool {
+	local := t.In(m.loc)
+	if local.Weekday() == time.Saturday || local.Weekday() == time.Sunday {
+		return false
+	}
+	return local.Hour() >= m.from && local.Hour() < m.to
+}
+
+// isOut returns true if the member is on vacation at t. Vacations last
+// until the end of their last day
+func (m member) isOut(t time.Time) bool {
+	if m.out == nil || m.out.StartDate == nil || m.out.EndDate == nil {
+		return false
+	}
+	return !t.Before(*m.out.StartDate) && t.Before(m.out.EndDate.AddDate(0, 0, 1))
+}
+
+func (m member) away(t time.Time) string {
+	if !m.isOut(t) {
+		return ""
+	}
+	return " - out until " + m.out.EndDate.Format("Mon, Jan _2")
+}
+
+var shortWeekdays = map[string]string{
+	"mon": "monday", "tue": "tuesday", "wed": "wednesday", "thu": "thursday",
+	"fri": "friday", "sat": "saturday", "sun": "sunday",
+}
+
+// parseWhen reads times like "3pm", "tue 3pm my time", "tomorrow at 9:30am"
+// or "2016-03-01 10am" in now's location
+func parseWhen(s string, now time.Time) (time.Time, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimSpace(strings.TrimSuffix(s, "my time"))
+
+	fields := strings.Fields(s)
+	if len(fields) < 1 {
+		return now, errors.New("Missing time")
+	}
+
+	day := fields[0]
+	if full, ok := shortWeekdays[day]; ok {
+		day = full
+	}
+	rest := strings.TrimPrefix(strings.Join(fields[1:], " "), "at ")
+
+	if rest != "" {
+		rest = " at " + rest
+	}
+
+	expr := "at " + strings.TrimPrefix(s, "at ")
+	switch {
+	case day == "today" || day == "tomorrow":
+		expr = day + rest
+	case strings.HasSuffix(day, "day") || strings.Count(day, "-") == 2:
+		expr = "on " + day + rest
+	}
+
+	_, sch, err := utils.ParseSchedule(expr, now)
+	if err != nil {
+		return now, errors.New("I couldn't understand *" + s + "*. Try something like `tue 3pm` or `tomorrow at 10am`")
+	}
+	return sch.Next, nil
+}
+
+func (r bot) Description() (description string) {
+	return "Timezones bot\n\tUsage: !tz <list|at|plan>\n"
+}
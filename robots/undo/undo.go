@@ -0,0 +1,158 @@
+package undo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gistia/slackbot/db"
+	"github.com/gistia/slackbot/mavenlink"
+	"github.com/gistia/slackbot/pivotal"
+	"github.com/gistia/slackbot/robots"
+	"github.com/gistia/slackbot/utils"
+)
+
+type bot struct {
+	handler utils.SlackHandler
+}
+
+func init() {
+	handler := utils.NewSlackHandler("Undo", ":leftwards_arrow_with_hook:")
+	s := &bot{handler: handler}
+	robots.RegisterRobot("undo", s)
+}
+
+func (r bot) Run(p *robots.Payload) string {
+	go r.DeferredAction(p)
+	return ""
+}
+
+func (r bot) DeferredAction(p *robots.Payload) {
+	ch := utils.NewCmdHandler(p, r.handler, "undo")
+	ch.Handle("show", r.show)
+	ch.HandleDefault(r.undo)
+	ch.Process(p.Text)
+}
+
+// window is how long after a change it can still be undone, configured
+// through UNDO_WINDOW, like 15m or 1h
+func window() time.Duration {
+	if w := os.Getenv("UNDO_WINDOW"); w != "" {
+		if d, err := time.ParseDuration(w); err == nil {
+			return d
+		}
+	}
+	return 15 * time.Minute
+}
+
+func (r bot) lastBatch(p *robots.Payload) ([]db.UndoAction, error) {
+	actions, err := db.GetLastUndoBatch(p.TeamID, p.UserName, window())
+	if err != nil {
+		return nil, err
+	}
+	if len(actions) < 1 {
+		return nil, fmt.Errorf("Nothing to undo from the last %s", window())
+	}
+	return actions, nil
+}
+
+func (r bot) show(p *robots.Payload, cmd utils.Command) error {
+	actions, err := r.lastBatch(p)
+	if err != nil {
+		return err
+	}
+
+	s := "Running `!undo` will:\n"
+	for _, a := range actions {
+		s += "- " + a.Description + "\n"
+	}
+	r.handler.Send(p, s)
+	return nil
+}
+
+func (r bot) undo(p *robots.Payload, cmd utils.Command) error {
+	actions, err := r.lastBatch(p)
+	if err != nil {
+		return err
+	}
+
+	done := []db.UndoAction{}
+	s := ""
+	for _, a := range actions {
+		if err := revert(p, a); err != nil {
+			s += fmt.Sprintf(":x: Could not %s: %s\n", a.Description, err.Error())
+			continue
+		}
+		done = append(done, a)
+		s += fmt.Sprintf(":white_check_mark: Did %s\n", a.Description)
+	}
+
+	if err := db.MarkUndone(done); err != nil {
+		return err
+	}
+
+	r.handler.Send(p, s)
+	return nil
+}
+
+// revert applies the inverse action, without recording it for undo itself
+func revert(p *robots.Payload, a db.UndoAction) error {
+	switch a.System {
+	case "pivotal":
+		pvt, err := pivotal.NewFor(p.TeamID, p.UserName)
+		if err != nil {
+			return err
+		}
+		pvt.RecordUndo = false
+		return revertPivotal(pvt, a)
+	case "mavenlink":
+		mvn, err := mavenlink.NewFor(p.TeamID, p.UserName)
+		if err != nil {
+			return err
+		}
+		mvn.RecordUndo = false
+		return revertMavenlink(mvn, a)
+	}
+
+	return errors.New("unknown system " + a.System)
+}
+
+func revertPivotal(pvt *pivotal.Pivotal, a db.UndoAction) error {
+	switch a.Action {
+	case "update_story":
+		fields := map[string]interface{}{}
+		if err := a.Decode(&fields); err != nil {
+			return err
+		}
+		delete(fields, "id")
+		_, err := pvt.RestoreStory(a.Target, fields)
+		return err
+	case "delete_story":
+		return pvt.DeleteStory(a.Target)
+	}
+
+	return errors.New("unknown action " + a.Action)
+}
+
+func revertMavenlink(mvn *mavenlink.Mavenlink, a db.UndoAction) error {
+	switch a.Action {
+	case "set_story_state":
+		params := map[string]string{}
+		if err := a.Decode(&params); err != nil {
+			return err
+		}
+		_, err := mvn.SetStoryState(a.Target, params["state"])
+		return err
+	case "delete_time_entry":
+		return mvn.DeleteTimeEntry(a.Target)
+	case "delete_story":
+		return mvn.DeleteStory(a.Target)
+	}
+
+	return errors.New("unknown action " + a.Action)
+}
+
+func (r bot) Description() (description string) {
+	return "Undo bot\n\tUsage: !undo [show]\n\tReverts your last Pivotal and Mavenlink changes made through the bot\n"
+}
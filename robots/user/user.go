@@ -33,7 +33,7 @@ func (r bot) DeferredAction(p *robots.Payload) {
 }
 
 func (r bot) list(p *robots.Payload, cmd utils.Command) error {
-	users, err := db.GetUsers()
+	users, err := db.GetUsers(p.TeamID)
 	if err != nil {
 		return err
 	}
@@ -57,7 +57,7 @@ func (r bot) set(p *robots.Payload, cmd utils.Command) error {
 	mvnId := cmd.Param("mvn")
 	pvtId := cmd.Param("pvt")
 
-	user := db.User{Name: name}
+	user := db.User{TeamID: p.TeamID, Name: name}
 
 	if mvnId != "" {
 		mvnInt, err := strconv.ParseInt(mvnId, 10, 64)
@@ -33,7 +33,7 @@ func (r bot) DeferredAction(p *robots.Payload) {
 }
 
 func (r bot) whoIsOut(p *robots.Payload, cmd utils.Command) error {
-	vacations, err := db.GetCurrentVacations()
+	vacations, err := db.GetCurrentVacations(p.TeamID)
 	if err != nil {
 		return err
 	}
@@ -58,7 +58,7 @@ func (r bot) whoIsOut(p *robots.Payload, cmd utils.Command) error {
 }
 
 func (r bot) list(p *robots.Payload, cmd utils.Command) error {
-	vacations, err := db.GetVacations()
+	vacations, err := db.GetVacations(p.TeamID)
 	if err != nil {
 		return err
 	}
@@ -96,7 +96,7 @@ func (r bot) set(p *robots.Payload, cmd utils.Command) error {
 		return err
 	}
 
-	_, err = db.CreateVacation(p.UserName, desc, &startDate, &endDate)
+	_, err = db.CreateVacation(p.TeamID, p.UserName, desc, &startDate, &endDate)
 
 	r.handler.Send(p, "Vacation created")
 	return nil
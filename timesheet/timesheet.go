@@ -0,0 +1,225 @@
+package timesheet
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/gistia/slackbot/db"
+)
+
+// Entry is the time a user spent on a timer in a single day
+type Entry struct {
+	Day      time.Time
+	User     string
+	Timer    string
+	Story    string
+	Duration time.Duration
+}
+
+// Report is the time spent on timers between From and To, split by day in
+// From's location
+type Report struct {
+	Team    string
+	User    string
+	From    time.Time
+	To      time.Time
+	Entries []Entry
+}
+
+// Total is the time spent on one group of entries, like a timer name or
+// a story
+type Total struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Period returns the start and end of the day, week or month now is in.
+// Weeks start on Monday
+func Period(name string, now time.Time) (time.Time, time.Time, error) {
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch name {
+	case "", "day", "today":
+		return day, day.AddDate(0, 0, 1), nil
+	case "week":
+		from := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return from, from.AddDate(0, 0, 7), nil
+	case "month":
+		from := day.AddDate(0, 0, 1-day.Day())
+		return from, from.AddDate(0, 1, 0), nil
+	}
+
+	return now, now, errors.New("Invalid period *" + name + "*. Use `day`, `week` or `month`")
+}
+
+// Build reports the time spent by user between from and to, or by the whole
+// team when user is empty
+func Build(team, user string, from, to time.Time) (*Report, error) {
+	timers, err := db.GetTimersBetween(team, user, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Report{Team: team, User: user, From: from, To: to}
+	for _, t := range timers {
+		segments, err := t.Segments()
+		if err != nil {
+			return nil, err
+		}
+		claims, err := t.Claims()
+		if err != nil {
+			return nil, err
+		}
+		parts := shares(t.ActiveIn(segments, time.Time{}, time.Now()), claims)
+
+		for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+			d := t.ActiveIn(segments, day, day.AddDate(0, 0, 1))
+			if d <= 0 {
+				continue
+			}
+
+			for _, part := range parts {
+				r.Entries = append(r.Entries, Entry{
+					Day: day, User: t.User, Timer: t.Name, Story: part.story,
+					Duration: time.Duration(float64(d) * part.fraction),
+				})
+			}
+		}
+	}
+
+	return r, nil
+}
+
+// share is the part of a timer's time claimed to a story
+type share struct {
+	story    string
+	fraction float64
+}
+
+// shares splits the time of a timer that ran for active among the stories
+// it was claimed to, by the minutes each got. The time left unclaimed goes
+// to an empty story. Claims without minutes, from before timers could be
+// split, share the time evenly
+func shares(active time.Duration, claims []db.TimerClaim) []share {
+	if len(claims) < 1 {
+		return []share{{fraction: 1}}
+	}
+
+	claimed := 0
+	for _, c := range claims {
+		claimed += c.Minutes
+	}
+	if claimed == 0 {
+		parts := []share{}
+		for _, c := range claims {
+			parts = append(parts, share{c.MavenlinkStory, 1 / float64(len(claims))})
+		}
+		return parts
+	}
+
+	total := int(active.Minutes())
+	if total < claimed {
+		total = claimed
+	}
+	parts := []share{}
+	for _, c := range claims {
+		parts = append(parts, share{c.MavenlinkStory, float64(c.Minutes) / float64(total)})
+	}
+	if total > claimed {
+		parts = append(parts, share{"", float64(total-claimed) / float64(total)})
+	}
+	return parts
+}
+
+// Total returns the time spent on every entry of the report
+func (r *Report) Total() time.Duration {
+	var d time.Duration
+	for _, e := range r.Entries {
+		d += e.Duration
+	}
+	return d
+}
+
+// By groups the entries by the name key returns, the largest totals first
+func (r *Report) By(key func(Entry) string) []Total {
+	sums := map[string]time.Duration{}
+	for _, e := range r.Entries {
+		sums[key(e)] += e.Duration
+	}
+
+	totals := []Total{}
+	for name, d := range sums {
+		totals = append(totals, Total{Name: name, Duration: d})
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Duration == totals[j].Duration {
+			return totals[i].Name < totals[j].Name
+		}
+		return totals[i].Duration > totals[j].Duration
+	})
+	return totals
+}
+
+// ByTimer groups the entries by timer name
+func (r *Report) ByTimer() []Total {
+	return r.By(func(e Entry) string { return e.Timer })
+}
+
+// ByStory groups the entries by the Mavenlink story they were claimed to,
+// an empty name holding the unclaimed ones
+func (r *Report) ByStory() []Total {
+	return r.By(func(e Entry) string { return e.Story })
+}
+
+// ByUser groups the entries by user
+func (r *Report) ByUser() []Total {
+	return r.By(func(e Entry) string { return e.User })
+}
+
+// ByDay groups the entries by day, in the order the days came
+func (r *Report) ByDay() []Total {
+	totals := []Total{}
+	for _, e := range r.Entries {
+		name := e.Day.Format("2006-01-02 Mon")
+		i := sort.Search(len(totals), func(i int) bool { return totals[i].Name >= name })
+		if i < len(totals) && totals[i].Name == name {
+			totals[i].Duration += e.Duration
+			continue
+		}
+		totals = append(totals, Total{})
+		copy(totals[i+1:], totals[i:])
+		totals[i] = Total{Name: name, Duration: e.Duration}
+	}
+	return totals
+}
+
+// WriteCSV writes one line per entry, with the time in minutes
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"date", "user", "timer", "mavenlink_story", "minutes"}); err != nil {
+		return err
+	}
+
+	for _, e := range r.Entries {
+		err := cw.Write([]string{
+			e.Day.Format("2006-01-02"), e.User, e.Timer, e.Story,
+			fmt.Sprintf("%d", int(e.Duration.Minutes())),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// FormatDuration formats d as hours and minutes, like 3h 05m
+func FormatDuration(d time.Duration) string {
+	mins := int(d.Minutes())
+	return fmt.Sprintf("%dh %02dm", mins/60, mins%60)
+}
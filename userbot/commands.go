@@ -1,276 +1,160 @@
 package userbot
 
 import (
-	"errors"
-	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/gistia/slackbot/db"
-	"github.com/gistia/slackbot/mavenlink"
-	"github.com/gistia/slackbot/pivotal"
+	"github.com/gistia/slackbot/dryrun"
+	"github.com/gistia/slackbot/robots"
 	"github.com/gistia/slackbot/utils"
 )
 
+// fallbackRobot gets the messages that aren't a userbot command nor start
+// with a robot name, so the timer commands keep working without one
+const fallbackRobot = "timer"
+
 func (bot *UserBot) SetupCommands() {
 	bot.handler = NewCmdHandler(bot)
-	bot.handler.Handle("start", StartTimer)
-	bot.handler.Handle("stop", StopTimer)
-	bot.handler.Handle("status", TimerStatus)
-	bot.handler.Handle("timers", RunningTimers)
-	bot.handler.Handle("claim", claimTimer)
-	bot.handler.Handle("tasks", StartedTasks)
+	bot.handler.Handle("snooze", SnoozeReminder)
+	bot.handler.Handle("done", CompleteReminder)
 }
 
+// Handle runs the command in msg. Its first word is either a userbot
+// command or the name of a robot, like in `project stories foo`
 func (bot *UserBot) Handle(msg *IncomingMsg) {
-	bot.handler.Process(msg.Text)
-}
-
-func claimTimer(bot *UserBot, cmd utils.Command) error {
-	timerName := cmd.Arg(0)
-	if timerName == "" {
-		return errors.New("Missing timer. Usage: `claim <timer-name> <pivotal-task-id>`")
-	}
-
-	taskID := cmd.Arg(1)
-	if taskID == "" {
-		return errors.New("Missing task id. Usage: `claim <timer-name> <pivotal-task-id>`")
-	}
-
-	username := bot.lastMessage.User.Name
-	timer, err := db.GetTimerByName(username, timerName)
-	if err != nil {
-		return err
-	}
-	if timer == nil {
-		return errors.New("You have no timer with name *" + timerName + "*")
-	}
-
-	err = timer.Stop()
-	if err != nil {
-		return err
-	}
-	bot.reply("Timer *" + timer.Name + "* stopped.")
+	msg.Text, msg.DryRun = dryrun.Parse(msg.Text)
+	cmd := utils.NewCommand(msg.Text)
+	name := strings.TrimPrefix(cmd.Command, "!")
 
-	pvt, err := pivotal.NewFor(username)
-	if err != nil {
-		return err
-	}
-
-	mvn, err := mavenlink.NewFor(username)
-	if err != nil {
-		return err
-	}
-
-	task, err := pvt.GetStory(taskID)
-	if err != nil {
-		return err
-	}
-
-	mvnID := task.GetMavenlinkId()
-	if mvnID == "" {
-		return errors.New("Can't claim because the Pivotal task doesn't have a mavenlink tag like `[mvn:<id>]`")
-	}
-
-	story, err := mvn.GetStory(mvnID)
-	if err != nil {
-		return err
+	if cmd.IsDefault() || cmd.Is("help") || bot.handler.Handles(cmd.Command) {
+		db.RecordCommand(msg.TeamId, msg.User.Name, msg.ChannelName, "userbot", msg.Text)
+		bot.handler.Process(msg)
+		return
 	}
 
-	_, err = mvn.AddTimeEntry(story, timer.Minutes())
-	if err != nil {
-		return err
+	if rs := robots.Get(name); len(rs) > 0 {
+		text := strings.TrimSpace(strings.TrimPrefix(msg.Text, cmd.Command))
+		bot.runRobots(msg, name, text, rs)
+		return
 	}
 
-	bot.reply(fmt.Sprintf("Added *%d* minutes to story *%s - %s*",
-		timer.Minutes(), story.Id, story.Title))
-
-	return nil
+	bot.runRobots(msg, fallbackRobot, msg.Text, robots.Get(fallbackRobot))
 }
 
-func StartedTasks(bot *UserBot, cmd utils.Command) error {
-	username := bot.lastMessage.User.Name
-	projects, err := db.GetProjects()
-	if err != nil {
-		return err
-	}
-
-	if projects == nil || len(projects) < 1 {
-		bot.reply("There are no linked projects currently. Use `/project link` command to add one.")
-		return nil
+// runRobots hands text to the robots registered as name, the same way a
+// slash command would. What they return is sent as a reply
+func (bot *UserBot) runRobots(msg *IncomingMsg, name, text string, rs []robots.Robot) {
+	if len(rs) < 1 {
+		bot.reply(msg, "Invalid command *"+name+"*")
+		bot.handler.sendHelp(msg)
+		return
 	}
 
-	user, err := db.GetUserByName(username)
-	if err != nil {
-		return err
-	}
+	p := msg.Payload()
+	p.Robot = name
+	p.Text = text
 
-	pvt, err := pivotal.NewFor(username)
-	if err != nil {
-		return err
+	if s := robots.DryRunError(name, rs, p.DryRun); s != "" {
+		bot.reply(msg, s)
+		return
 	}
 
-	msg := ""
-	for _, p := range projects {
-		filter := map[string]string{
-			"owned_by": user.StrPivotalId(),
-			"state":    "started",
+	db.RecordCommand(p.TeamID, p.UserName, p.ChannelName, name, p.Text)
+	for _, r := range rs {
+		if s := strings.TrimSpace(r.Run(p)); s != "" {
+			bot.reply(msg, s)
 		}
-		stories, err := pvt.FilteredStories(p.StrPivotalId(), filter)
-		if err != nil {
-			return err
-		}
-
-		if len(stories) < 1 {
-			continue
-		}
-
-		msg += "Stories for *" + p.Name + "*:\n"
-		for _, s := range stories {
-			msg += fmt.Sprintf("%d - %s - %s\n", s.Id, s.Name, s.State)
-		}
-	}
-
-	if msg == "" {
-		msg = "No started tasks for you"
-	}
-
-	bot.reply(msg)
-	return nil
-}
-
-func RunningTimers(bot *UserBot, cmd utils.Command) error {
-	timers, err := db.GetRunningTimers(bot.lastMessage.User.Name)
-	if err != nil {
-		return err
 	}
-
-	if len(timers) < 1 {
-		bot.reply("You have no running timers")
-		return nil
-	}
-
-	s := "You have the following running timers:\n"
-	for _, t := range timers {
-		s += fmt.Sprintf("- *%s* running for *%s*\n", t.Name, t.Duration())
-	}
-	bot.reply(s)
-	return nil
-}
-
-func StartTimer(bot *UserBot, cmd utils.Command) error {
-	name := cmd.Arg(0)
-	if name == "" {
-		return errors.New("Missing timer name")
-	}
-	err := db.CreateTimer(bot.lastMessage.User.Name, name)
-	if err != nil {
-		return err
-	}
-	bot.reply("Created timer *" + name + "*")
-	return nil
-}
-
-func StopTimer(bot *UserBot, cmd utils.Command) error {
-	name := cmd.Arg(0)
-	if name == "" {
-		return errors.New("Missing timer name")
-	}
-	timer, err := db.GetStartedTimerByName(bot.lastMessage.User.Name, name)
-	if err != nil {
-		return err
-	}
-	if timer == nil {
-		return errors.New("You have no started timer with name *" + name + "*")
-	}
-
-	err = timer.Stop()
-	if err != nil {
-		return err
-	}
-
-	timer, err = timer.Reload()
-	if err != nil {
-		return err
-	}
-
-	bot.reply("Your timer *" + name + "* has stopped. It ran for *" + timer.Duration() + "*.")
-	return nil
-}
-
-func TimerStatus(bot *UserBot, cmd utils.Command) error {
-	name := cmd.Arg(0)
-	if name == "" {
-		return errors.New("Missing timer name")
-	}
-	timer, err := db.GetTimerByName(bot.lastMessage.User.Name, name)
-	if err != nil {
-		return err
-	}
-	if timer == nil {
-		return errors.New("You have no started timer with name *" + name + "*")
-	}
-
-	if timer.IsFinished() {
-		bot.reply("Your timer *" + name + "* is finished. It ran for *" + timer.Duration() + "*.")
-	} else {
-		bot.reply("Your timer *" + name + "* has been running for *" + timer.Duration() + "*")
-	}
-	return nil
 }
 
 //------
 
-type HandlerFunc func(*UserBot, utils.Command) error
+// HandlerFunc handles a command, replying to the message it came in
+type HandlerFunc func(*UserBot, *IncomingMsg, utils.Command) error
 
 type CmdHandler struct {
 	handlers map[string]HandlerFunc
+	dryRun   map[string]bool
 	bot      *UserBot
 }
 
 func NewCmdHandler(bot *UserBot) CmdHandler {
-	return CmdHandler{bot: bot, handlers: map[string]HandlerFunc{}}
+	return CmdHandler{
+		bot:      bot,
+		handlers: map[string]HandlerFunc{},
+		dryRun:   map[string]bool{},
+	}
 }
 
 func (c *CmdHandler) Handle(cmd string, handler HandlerFunc) {
 	c.handlers[cmd] = handler
 }
 
-func (c *CmdHandler) Process(s string) {
-	cmd := utils.NewCommand(s)
+// Handles tells if cmd is one of the userbot's own commands
+func (c *CmdHandler) Handles(cmd string) bool {
+	_, ok := c.handlers[cmd]
+	return ok
+}
+
+// SupportDryRun marks commands that honor --dry-run
+func (c *CmdHandler) SupportDryRun(cmds ...string) {
+	for _, cmd := range cmds {
+		c.dryRun[cmd] = true
+	}
+}
+
+// Process runs the command in msg. It's safe to call from several
+// goroutines at once
+func (c *CmdHandler) Process(msg *IncomingMsg) {
+	cmd := utils.NewCommand(msg.Text)
 
 	if cmd.IsDefault() {
 		if h := c.handlers["_default"]; h != nil {
-			err := h(c.bot, cmd)
-			if err != nil {
-				c.bot.replyError(err)
-			}
+			c.run(msg, "_default", h, cmd)
 			return
 		}
 
-		c.bot.reply("You must enter a command.")
-		c.sendHelp()
+		c.bot.reply(msg, "You must enter a command.")
+		c.sendHelp(msg)
 		return
 	}
 
 	if cmd.Is("help") {
-		c.sendHelp()
+		c.sendHelp(msg)
 		return
 	}
 
 	for k := range c.handlers {
 		if cmd.Is(k) {
-			err := c.handlers[k](c.bot, cmd)
-			if err != nil {
-				c.bot.replyError(err)
-			}
+			c.run(msg, k, c.handlers[k], cmd)
 			return
 		}
 	}
 
-	c.bot.reply("Invalid command *" + cmd.Command + "*\n")
-	c.sendHelp()
+	c.bot.reply(msg, "Invalid command *"+cmd.Command+"*\n")
+	c.sendHelp(msg)
+}
+
+// run calls the handler and, for commands run with --dry-run, reports
+// the changes it would have made
+func (c *CmdHandler) run(msg *IncomingMsg, name string, h HandlerFunc, cmd utils.Command) {
+	rec := msg.DryRun
+	if rec.Active() && !c.dryRun[name] {
+		c.bot.reply(msg, "*"+name+"* doesn't support "+dryrun.Flag)
+		return
+	}
+
+	err := h(c.bot, msg, cmd)
+	if err != nil {
+		c.bot.replyError(msg, err)
+	}
+	if rec.Active() {
+		c.bot.reply(msg, rec.Report())
+	}
 }
 
-func (c *CmdHandler) sendHelp() {
+func (c *CmdHandler) sendHelp(msg *IncomingMsg) {
 	s := ""
 	if len(c.handlers) > 0 {
 		cmds := ""
@@ -287,5 +171,15 @@ func (c *CmdHandler) sendHelp() {
 
 		s += "*Commands:* " + cmds + "\n"
 	}
-	c.bot.reply(s)
+
+	names := []string{}
+	for name := range robots.Robots {
+		names = append(names, "`"+name+"`")
+	}
+	sort.Strings(names)
+	s += "*Robots:* " + strings.Join(names, ", ") + "\n"
+	s += "Run a robot with `<robot> <command>`, like `project list`. " +
+		"Anything else goes to `" + fallbackRobot + "`.\n"
+
+	c.bot.reply(msg, s)
 }
@@ -0,0 +1,346 @@
+package userbot
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gistia/slackbot/db"
+	"github.com/gistia/slackbot/dialog"
+	"github.com/gistia/slackbot/robots"
+	"github.com/gistia/slackbot/utils"
+	"github.com/nlopes/slack"
+)
+
+// defaultAwayThreshold is how long users have to be away before their
+// timers are paused, unless the TIMER_AWAY_THRESHOLD setting says otherwise
+const defaultAwayThreshold = 15 * time.Minute
+
+// away tracks a user that is away or has do not disturb on
+type away struct {
+	// gone is whether the user was away when last checked, since do not
+	// disturb ends without any change to the state
+	gone     bool
+	presence bool
+	dndUntil time.Time
+	since    time.Time
+	pause    *time.Timer
+}
+
+func (a *away) isAway(now time.Time) bool {
+	return a.presence || now.Before(a.dndUntil)
+}
+
+// dndEvent is sent when the do not disturb settings of the bot
+// (dnd_updated) or of another user (dnd_updated_user) change
+type dndEvent struct {
+	UserId string `json:"user"`
+	Status struct {
+		Enabled       bool  `json:"dnd_enabled"`
+		NextStart     int64 `json:"next_dnd_start_ts"`
+		NextEnd       int64 `json:"next_dnd_end_ts"`
+		SnoozeEnabled bool  `json:"snooze_enabled"`
+		SnoozeEnd     int64 `json:"snooze_endtime"`
+	} `json:"dnd_status"`
+}
+
+// until returns when the user stops being in do not disturb mode, which is
+// in the past if they aren't in it now
+func (evt *dndEvent) until(now time.Time) time.Time {
+	s := evt.Status
+	until := time.Time{}
+	if s.SnoozeEnabled {
+		until = time.Unix(s.SnoozeEnd, 0)
+	}
+	if s.Enabled && s.NextStart > 0 && s.NextEnd > 0 {
+		start, end := time.Unix(s.NextStart, 0), time.Unix(s.NextEnd, 0)
+		if !now.Before(start) && now.Before(end) && end.After(until) {
+			until = end
+		}
+	}
+	return until
+}
+
+func (bot *UserBot) presenceChanged(evt *slack.PresenceChangeEvent) {
+	// going away means the user was active until now
+	bot.markSeen(evt.UserId)
+	bot.updateAway(evt.UserId, func(a *away) {
+		a.presence = evt.Presence == "away"
+	})
+}
+
+func (bot *UserBot) dndChanged(evt *dndEvent) {
+	userId := evt.UserId
+	if userId == "" {
+		userId = bot.info().User.Id
+	}
+
+	until := evt.until(time.Now())
+	bot.updateAway(userId, func(a *away) {
+		a.dndUntil = until
+	})
+
+	// do not disturb ends on its own, without an event
+	if d := until.Sub(time.Now()); d > 0 {
+		time.AfterFunc(d, func() {
+			bot.updateAway(userId, func(*away) {})
+		})
+	}
+}
+
+// markSeen records the user as active now
+func (bot *UserBot) markSeen(userId string) {
+	bot.awayMu.Lock()
+	defer bot.awayMu.Unlock()
+
+	if bot.seen == nil {
+		bot.seen = map[string]time.Time{}
+	}
+	bot.seen[userId] = time.Now()
+}
+
+// lastSeen returns when the user was last active, the zero time if the bot
+// didn't see them since it started
+func (bot *UserBot) lastSeen(userId string) time.Time {
+	bot.awayMu.Lock()
+	defer bot.awayMu.Unlock()
+
+	return bot.seen[userId]
+}
+
+// subscribePresence asks Slack for the presence changes of every user of
+// the team
+func (bot *UserBot) subscribePresence() {
+	bot.mu.RLock()
+	conn := bot.conn
+	bot.mu.RUnlock()
+	if conn == nil {
+		return
+	}
+
+	ids := []string{}
+	for _, u := range conn.info.Users {
+		if !u.IsBot && !u.Deleted {
+			ids = append(ids, u.Id)
+		}
+	}
+
+	err := conn.send(map[string]interface{}{"type": "presence_sub", "ids": ids})
+	if err != nil {
+		log.Printf("Error subscribing to presence changes: %s\n", err)
+	}
+}
+
+// updateAway applies a change to the user's away state. Leaving starts the
+// countdown to pause their timers, coming back resumes the timers paused
+// while they were away
+func (bot *UserBot) updateAway(userId string, change func(*away)) {
+	now := time.Now()
+	left, back := bot.changeAway(userId, now, change)
+	if left != nil {
+		bot.schedulePause(userId, left)
+	}
+	if back {
+		go bot.resumeTimers(userId, now)
+	}
+}
+
+// changeAway applies the change to the user's away state, returning the
+// state if the user just left and whether they came back
+func (bot *UserBot) changeAway(userId string, now time.Time, change func(*away)) (*away, bool) {
+	bot.awayMu.Lock()
+	defer bot.awayMu.Unlock()
+
+	if bot.away == nil {
+		bot.away = map[string]*away{}
+	}
+
+	a, tracked := bot.away[userId]
+	if !tracked {
+		a = &away{}
+	}
+	wasAway := a.gone
+	change(a)
+	a.gone = a.isAway(now)
+
+	switch {
+	case a.gone && !wasAway:
+		a.since = now
+		bot.away[userId] = a
+		return a, false
+	case a.gone:
+		bot.away[userId] = a
+		return nil, false
+	}
+
+	if a.pause != nil {
+		a.pause.Stop()
+	}
+	delete(bot.away, userId)
+
+	// the bot may have been disconnected when the user left, so even
+	// users it didn't see leaving get their timers resumed
+	return nil, wasAway || !tracked
+}
+
+// schedulePause starts the countdown to pause the timers of a user that
+// left, unless they came back while the threshold was being loaded
+func (bot *UserBot) schedulePause(userId string, a *away) {
+	d := bot.awayThreshold(userId)
+
+	bot.awayMu.Lock()
+	defer bot.awayMu.Unlock()
+
+	if bot.away[userId] != a {
+		return
+	}
+	a.pause = time.AfterFunc(d-time.Since(a.since), func() {
+		bot.pauseTimers(userId, a)
+	})
+}
+
+// stillAway returns when the user left, or false if they came back since
+// a was their state
+func (bot *UserBot) stillAway(userId string, a *away) (time.Time, bool) {
+	bot.awayMu.Lock()
+	defer bot.awayMu.Unlock()
+
+	if bot.away[userId] != a {
+		return time.Time{}, false
+	}
+	return a.since, true
+}
+
+// awayThreshold returns how long the user has to be away before their
+// timers are paused
+func (bot *UserBot) awayThreshold(userId string) time.Duration {
+	s, err := db.ResolveSetting(utils.SettingContext(bot.payload(userId)), "TIMER_AWAY_THRESHOLD")
+	if err != nil {
+		log.Printf("Error loading TIMER_AWAY_THRESHOLD: %s\n", err)
+	}
+	if err != nil || s == nil {
+		return defaultAwayThreshold
+	}
+
+	d, err := time.ParseDuration(s.Value)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid TIMER_AWAY_THRESHOLD %q, using %s\n", s.Value, defaultAwayThreshold)
+		return defaultAwayThreshold
+	}
+	return d
+}
+
+// pauseTimers pauses the running timers of a user that has been away for
+// longer than the threshold, counting the pause from when they left
+func (bot *UserBot) pauseTimers(userId string, a *away) {
+	bot.pauseMu.Lock()
+	defer bot.pauseMu.Unlock()
+
+	// the user may have come back while the timers of others were paused
+	since, ok := bot.stillAway(userId, a)
+	if !ok {
+		return
+	}
+
+	p := bot.payload(userId)
+	timers, err := db.GetRunningTimers(p.TeamID, p.UserName)
+	if err != nil {
+		log.Printf("Error loading timers of %s: %s\n", p.UserName, err)
+		return
+	}
+
+	for _, t := range timers {
+		if t.IsPaused() {
+			continue
+		}
+		if err := t.Pause(since, db.PauseAway); err != nil {
+			log.Printf("Error pausing timer %d: %s\n", t.ID, err)
+		}
+	}
+}
+
+// resumeTimers resumes the timers paused while the user was away and asks
+// them whether the time away should be counted anyway
+func (bot *UserBot) resumeTimers(userId string, at time.Time) {
+	bot.pauseMu.Lock()
+	defer bot.pauseMu.Unlock()
+
+	p := bot.payload(userId)
+	timers, err := db.GetRunningTimers(p.TeamID, p.UserName)
+	if err != nil {
+		log.Printf("Error loading timers of %s: %s\n", p.UserName, err)
+		return
+	}
+
+	resumed := []db.Timer{}
+	for _, t := range timers {
+		if !t.IsPaused() || t.PauseReason != db.PauseAway {
+			continue
+		}
+		if err := t.Resume(at); err != nil {
+			log.Printf("Error resuming timer %d: %s\n", t.ID, err)
+			continue
+		}
+		resumed = append(resumed, t)
+	}
+
+	if len(resumed) > 0 {
+		go bot.askAwayTime(p, resumed, at)
+	}
+}
+
+// askAwayTime starts a conversation with the user about the time their
+// timers were paused
+func (bot *UserBot) askAwayTime(p *robots.Payload, timers []db.Timer, at time.Time) {
+	ch, err := bot.imChannel(p.UserName)
+	if err != nil {
+		log.Printf("Error opening a direct message with %s: %s\n", p.UserName, err)
+		return
+	}
+	p.ChannelID = ch
+
+	from := at
+	ids, names := []string{}, []string{}
+	for _, t := range timers {
+		if t.PausedAt.Before(from) {
+			from = *t.PausedAt
+		}
+		ids = append(ids, strconv.Itoa(t.ID))
+		names = append(names, "*"+t.Name+"*")
+	}
+
+	bot.Send(p, fmt.Sprintf("Welcome back! I paused %s while you were away for %d minutes.",
+		strings.Join(names, ", "), int(at.Sub(from).Minutes())))
+
+	err = dialog.Start(p, bot, "timer.away", dialog.Answers{
+		"timers": strings.Join(ids, ","),
+		"from":   from.UTC().Format(time.RFC3339Nano),
+		"to":     at.UTC().Format(time.RFC3339Nano),
+	})
+	if err != nil {
+		bot.Send(p, "Error: "+err.Error())
+	}
+}
+
+// payload builds the payload for the bot acting on its own on behalf of the
+// user, in a direct message with them
+func (bot *UserBot) payload(userId string) *robots.Payload {
+	info := bot.info()
+	name := userId
+	if u := info.GetUserById(userId); u != nil {
+		name = u.Name
+	} else if u, err := bot.api.GetUserInfo(userId); err == nil {
+		name = u.Name
+	}
+
+	return &robots.Payload{
+		TeamID:      bot.teamID(),
+		TeamDomain:  os.Getenv("SLACK_TEAM_DOMAIN"),
+		ChannelName: "directmessage",
+		UserID:      userId,
+		UserName:    name,
+	}
+}
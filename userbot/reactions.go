@@ -0,0 +1,63 @@
+package userbot
+
+import (
+	"log"
+
+	"github.com/gistia/slackbot/db"
+	"github.com/gistia/slackbot/utils"
+)
+
+// storyReactions maps the reactions to story cards to the project command
+// they run on the story
+var storyReactions = map[string]string{
+	"eyes":             "assign",
+	"hammer":           "start",
+	"white_check_mark": "finish",
+}
+
+// reactionEvent is sent when someone reacts to a message (reaction_added)
+type reactionEvent struct {
+	UserId   string `json:"user"`
+	Reaction string `json:"reaction"`
+	Item     struct {
+		Type    string `json:"type"`
+		Channel string `json:"channel"`
+		Ts      string `json:"ts"`
+	} `json:"item"`
+}
+
+// reactionAdded acts on the story a card the bot posted is about, as the
+// user that reacted to it
+func (bot *UserBot) reactionAdded(evt *reactionEvent) {
+	cmd, ok := storyReactions[evt.Reaction]
+	if !ok || evt.Item.Type != "message" || evt.UserId == bot.info().User.Id {
+		return
+	}
+
+	card, err := db.GetStoryCard(bot.teamID(), evt.Item.Channel, evt.Item.Ts)
+	if err != nil {
+		log.Printf("Error finding story card: %s\n", err)
+		return
+	}
+	if card == nil {
+		return
+	}
+
+	p := bot.payload(evt.UserId)
+	p.ChannelID = evt.Item.Channel
+	p.ChannelName = bot.channelName(evt.Item.Channel)
+
+	if card.PivotalStory == "" {
+		bot.send(p.ChannelID, "Mavenlink story *"+card.MavenlinkStory+
+			"* isn't tagged with a Pivotal story, so it can't be updated from here.")
+		return
+	}
+
+	text := cmd + " " + card.PivotalStory
+	if cmd == "assign" {
+		text += " " + p.UserName
+	}
+	if err := utils.RunRobot(p, "project", text); err != nil {
+		bot.send(p.ChannelID, "Error: "+err.Error())
+	}
+}
@@ -0,0 +1,209 @@
+package userbot
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gistia/slackbot/db"
+	"github.com/gistia/slackbot/utils"
+)
+
+// retryReminder is how long a reminder that couldn't be delivered waits
+// before being tried again
+const retryReminder = 15 * time.Minute
+
+func (bot *UserBot) watchReminders() {
+	for {
+		reminders, err := db.GetDueReminders(bot.teamID())
+		if err != nil {
+			fmt.Println("Error loading reminders:", err)
+		}
+
+		for _, r := range reminders {
+			if err := bot.deliverReminder(&r); err != nil {
+				fmt.Printf("Error delivering reminder %d: %s\n", r.ID, err)
+				bot.undeliverable(&r, err)
+			}
+		}
+
+		time.Sleep(time.Minute)
+	}
+}
+
+func (bot *UserBot) deliverReminder(r *db.Reminder) error {
+	now := time.Now().UTC()
+
+	if r.IsChannel() {
+		h := utils.NewSlackHandler("Remind", ":alarm_clock:")
+		h.SendMsg(r.Target, fmt.Sprintf(
+			":alarm_clock: Reminder from @%s: *%s*", r.User, r.Message))
+	} else {
+		chanId, err := bot.imChannel(strings.TrimPrefix(r.Target, "@"))
+		if err != nil {
+			return err
+		}
+
+		msg := fmt.Sprintf(":alarm_clock: Reminder: *%s*\n", r.Message)
+		if r.User != strings.TrimPrefix(r.Target, "@") {
+			msg = fmt.Sprintf(":alarm_clock: @%s asked me to remind you: *%s*\n",
+				r.User, r.Message)
+		}
+		msg += fmt.Sprintf("Reply `snooze %d [time]` or `done %d`.", r.ID, r.ID)
+		if err := bot.send(chanId, msg); err != nil {
+			return err
+		}
+	}
+
+	if r.SnoozedUntil != nil && !r.SnoozedUntil.After(now) {
+		if err := r.Snooze(nil); err != nil {
+			return err
+		}
+	}
+
+	if r.NextRunAt == nil || r.NextRunAt.After(now) {
+		return nil
+	}
+
+	if !r.IsRecurring() {
+		if r.IsChannel() {
+			return r.Cancel()
+		}
+		return r.Reschedule(nil)
+	}
+
+	next, err := utils.NextOccurrence(r.Recurrence, now.In(r.Location()))
+	if err != nil {
+		return err
+	}
+	next = next.UTC()
+	return r.Reschedule(&next)
+}
+
+// undeliverable deals with a reminder that failed to be delivered, so it
+// isn't retried every minute. Reminders for users that left the team are
+// cancelled and their creator told, others are tried again later
+func (bot *UserBot) undeliverable(r *db.Reminder, err error) {
+	if err != utils.ErrUnknownTarget {
+		now := time.Now().UTC()
+		retry := now.Add(retryReminder)
+		var err error
+		if r.SnoozedUntil != nil && !r.SnoozedUntil.After(now) {
+			err = r.Snooze(&retry)
+		}
+		if err == nil && r.NextRunAt != nil && !r.NextRunAt.After(now) {
+			err = r.Reschedule(&retry)
+		}
+		if err != nil {
+			fmt.Printf("Error postponing reminder %d: %s\n", r.ID, err)
+		}
+		return
+	}
+
+	if err := r.Cancel(); err != nil {
+		fmt.Printf("Error cancelling reminder %d: %s\n", r.ID, err)
+		return
+	}
+
+	if chanId, err := bot.imChannel(r.User); err == nil {
+		bot.send(chanId, fmt.Sprintf(
+			"I couldn't find %s to remind them to *%s*, so I cancelled the reminder.",
+			r.Target, r.Message))
+	}
+}
+
+// imChannel opens a direct message with the user, returning
+// utils.ErrUnknownTarget if they aren't in the team
+func (bot *UserBot) imChannel(username string) (string, error) {
+	users, err := bot.api.GetUsers()
+	if err != nil {
+		return "", err
+	}
+
+	for _, u := range users {
+		if u.Name == username && !u.Deleted {
+			_, _, chanId, err := bot.api.OpenIMChannel(u.Id)
+			return chanId, err
+		}
+	}
+
+	return "", utils.ErrUnknownTarget
+}
+
+func getUserReminder(msg *IncomingMsg, cmd utils.Command) (*db.Reminder, error) {
+	args, err := cmd.ParseArgs("reminder-id")
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	username := msg.User.Name
+	r, err := db.GetReminder(id)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil || r.Done || r.TeamID != db.TeamKey(msg.TeamId) ||
+		(r.User != username && r.Target != "@"+username) {
+		return nil, errors.New("You have no pending reminder with id *" + args[0] + "*")
+	}
+
+	return r, nil
+}
+
+func SnoozeReminder(bot *UserBot, msg *IncomingMsg, cmd utils.Command) error {
+	r, err := getUserReminder(msg, cmd)
+	if err != nil {
+		return err
+	}
+
+	phrase := "15m"
+	if parts := strings.SplitN(msg.Text, " ", 3); len(parts) > 2 {
+		phrase = strings.TrimSpace(parts[2])
+	}
+
+	now := time.Now().In(r.Location())
+	var until time.Time
+	if d, err := time.ParseDuration(phrase); err == nil {
+		until = now.Add(d)
+	} else {
+		_, schedule, err := utils.ParseSchedule(phrase, now)
+		if err != nil {
+			return err
+		}
+		until = schedule.Next
+	}
+
+	utc := until.UTC()
+	if err := r.Snooze(&utc); err != nil {
+		return err
+	}
+
+	bot.reply(msg, fmt.Sprintf("Snoozed *%s* until *%s*",
+		r.Message, until.Format("Mon, Jan _2 at 3:04pm MST")))
+	return nil
+}
+
+func CompleteReminder(bot *UserBot, msg *IncomingMsg, cmd utils.Command) error {
+	r, err := getUserReminder(msg, cmd)
+	if err != nil {
+		return err
+	}
+
+	if err := r.Complete(); err != nil {
+		return err
+	}
+
+	if r.IsRecurring() {
+		bot.reply(msg, "Marked *"+r.Message+"* as done. I'll remind you again next time.")
+		return nil
+	}
+
+	bot.reply(msg, "Marked *"+r.Message+"* as done.")
+	return nil
+}
@@ -0,0 +1,154 @@
+package userbot
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/nlopes/slack"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// pingInterval is how often the connection is checked. A connection
+	// that doesn't get any event, pongs included, for two intervals is
+	// considered dropped
+	pingInterval = 20 * time.Second
+
+	minBackoff = time.Second
+	maxBackoff = 2 * time.Minute
+)
+
+// rtm is a connection to the Slack real time messaging API. The vendored
+// client can neither notice a dropped connection nor close it, so the bot
+// keeps its own and starts a new one after every drop
+type rtm struct {
+	info slack.Info
+	conn *websocket.Conn
+
+	sync.Mutex
+	lastID int
+}
+
+// rtmEvent holds the fields every event has, the rest is decoded
+// according to Type
+type rtmEvent struct {
+	Type  string              `json:"type"`
+	Error *slack.SlackWSError `json:"error"`
+}
+
+// dialRTM starts a real time messaging session and connects to it
+func dialRTM(token, origin string) (*rtm, error) {
+	resp, err := http.PostForm(slack.SLACK_API+"rtm.start", url.Values{"token": {token}})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	start := struct {
+		slack.Info
+		Ok    bool   `json:"ok"`
+		Error string `json:"error"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&start); err != nil {
+		return nil, err
+	}
+	if !start.Ok {
+		return nil, errors.New("rtm.start: " + start.Error)
+	}
+
+	wsURL, err := withPort(start.Url)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := websocket.Dial(wsURL, "", origin)
+	if err != nil {
+		return nil, err
+	}
+
+	return &rtm{info: start.Info, conn: conn}, nil
+}
+
+// withPort adds the default port to the websocket url, which Slack leaves
+// out and websocket.Dial requires
+func withPort(s string) (string, error) {
+	u, err := url.ParseRequestURI(s)
+	if err != nil {
+		return "", err
+	}
+	if _, _, err := net.SplitHostPort(u.Host); err == nil {
+		return s, nil
+	}
+
+	port := "443"
+	if u.Scheme == "ws" {
+		port = "80"
+	}
+	u.Host = net.JoinHostPort(u.Host, port)
+	return u.String(), nil
+}
+
+// send writes a message of the given type, giving it a new id
+func (c *rtm) send(msg map[string]interface{}) error {
+	c.Lock()
+	defer c.Unlock()
+
+	c.lastID++
+	msg["id"] = c.lastID
+	return websocket.JSON.Send(c.conn, msg)
+}
+
+// sendMessage posts text to the channel
+func (c *rtm) sendMessage(channelId, text string) error {
+	return c.send(map[string]interface{}{
+		"type": "message", "channel": channelId, "text": text,
+	})
+}
+
+// keepalive pings Slack until the connection is closed
+func (c *rtm) keepalive(done chan struct{}) {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := c.send(map[string]interface{}{"type": "ping"}); err != nil {
+				c.conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// receive reads the next event, returning its type and raw JSON. It fails
+// once the connection drops or stays silent for too long
+func (c *rtm) receive() (string, json.RawMessage, error) {
+	c.conn.SetReadDeadline(time.Now().Add(2 * pingInterval))
+
+	raw := json.RawMessage{}
+	if err := websocket.JSON.Receive(c.conn, &raw); err != nil {
+		return "", nil, err
+	}
+
+	evt := rtmEvent{}
+	if err := json.Unmarshal(raw, &evt); err != nil {
+		return "", nil, err
+	}
+	if evt.Type == "" && evt.Error != nil {
+		return "error", raw, nil
+	}
+
+	return evt.Type, raw, nil
+}
+
+func (c *rtm) close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,118 @@
+package userbot
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gistia/slackbot/db"
+	"github.com/gistia/slackbot/dialog"
+	"github.com/gistia/slackbot/utils"
+)
+
+// timerCheckInterval is how often running timers are checked for having
+// been forgotten
+const timerCheckInterval = 5 * time.Minute
+
+func (bot *UserBot) watchTimers() {
+	for {
+		time.Sleep(timerCheckInterval)
+
+		if err := bot.nudgeForgottenTimers(); err != nil {
+			fmt.Println("Error checking timers:", err)
+		}
+	}
+}
+
+// nudgeForgottenTimers asks the users of the bot's team with timers that
+// look forgotten what to do with them, once per timer
+func (bot *UserBot) nudgeForgottenTimers() error {
+	info := bot.info()
+	if info.Team == nil {
+		return nil
+	}
+	team := db.TeamKey(info.Team.Id)
+
+	timers, err := db.GetAllRunningTimers()
+	if err != nil {
+		return err
+	}
+
+	for _, t := range timers {
+		if t.TeamID != team || t.IsPaused() || t.NudgedAt != nil {
+			continue
+		}
+		if err := bot.nudgeTimer(&t); err != nil {
+			fmt.Printf("Error checking timer %d: %s\n", t.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (bot *UserBot) nudgeTimer(t *db.Timer) error {
+	var userId string
+	for _, u := range bot.info().Users {
+		if u.Name == t.User {
+			userId = u.Id
+		}
+	}
+	if userId == "" {
+		return nil
+	}
+
+	u, err := bot.api.GetUserInfo(userId)
+	if err != nil {
+		return err
+	}
+
+	p := bot.payload(userId)
+	c := utils.SettingContext(p)
+	loc := utils.Location(u)
+	reason, err := utils.ForgottenTimer(t, c, loc)
+	if err != nil || reason == "" {
+		return err
+	}
+
+	ch, err := bot.imChannel(t.User)
+	if err != nil {
+		return err
+	}
+	p.ChannelID = ch
+
+	if err := t.Nudge(time.Now()); err != nil {
+		return err
+	}
+
+	last := bot.lastActivity(userId, t, c, loc)
+	bot.Send(p, fmt.Sprintf(
+		"Your timer *%s* is still running and %s. Did you forget to stop it? I last saw you active at *%s*.",
+		t.Name, reason, last.In(loc).Format("Mon 3:04pm")))
+
+	return dialog.Start(p, bot, "timer.forgotten", dialog.Answers{
+		"timer": strconv.Itoa(t.ID),
+		"last":  last.UTC().Format(time.RFC3339Nano),
+	})
+}
+
+// lastActivity guesses when the user stopped working on the timer: when
+// the bot last saw them active, or else at the end of the work day the timer
+// started in
+func (bot *UserBot) lastActivity(userId string, t *db.Timer, c db.SettingContext, loc *time.Location) time.Time {
+	now := time.Now()
+
+	started := now
+	if segments, err := t.Segments(); err == nil && len(segments) > 0 {
+		started = *segments[len(segments)-1].StartedAt
+	} else if t.CreatedAt != nil {
+		started = *t.CreatedAt
+	}
+
+	if seen := bot.lastSeen(userId); seen.After(started) && seen.Before(now) {
+		return seen
+	}
+	if end := utils.WorkDayEnd(t, c, loc); end != nil && end.After(started) && end.Before(now) {
+		return *end
+	}
+	return now
+}
@@ -1,36 +1,62 @@
 package userbot
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/gistia/slackbot/dialog"
+	"github.com/gistia/slackbot/dryrun"
+	"github.com/gistia/slackbot/listener"
+	"github.com/gistia/slackbot/robots"
 	"github.com/gistia/slackbot/utils"
 	"github.com/nlopes/slack"
 )
 
 type UserBot struct {
-	api         *slack.Slack
-	wsAPI       *slack.SlackWS
-	handler     CmdHandler
-	lastMessage *IncomingMsg
+	api     *slack.Slack
+	handler CmdHandler
+
+	// conn is replaced every time the bot reconnects
+	mu   sync.RWMutex
+	conn *rtm
+
+	// away holds the users that are away or in do not disturb mode and
+	// seen when each user was last active
+	awayMu sync.Mutex
+	away   map[string]*away
+	seen   map[string]time.Time
+	// pauseMu keeps the timers of users that left from being paused while
+	// the ones of users coming back are resumed, without holding awayMu
+	// during the database and Slack calls
+	pauseMu sync.Mutex
 }
 
 type IncomingMsg struct {
+	TeamId    string
 	UserId    string
 	Text      string
 	RawText   string
 	ChannelId string
-	Private   bool
-	Highlight bool
-	Direct    bool
-	User      *slack.User
+	// ChannelName is the name robots know the channel by, directmessage
+	// for direct messages
+	ChannelName string
+	Private     bool
+	Highlight   bool
+	Direct      bool
+	User        *slack.User
+	DryRun      *dryrun.Recorder
 }
 
 func NewIncomingMsg(bot *UserBot, evt *slack.MessageEvent) (*IncomingMsg, error) {
 	msg := evt.Msg
-	botUser := bot.api.GetInfo().User
+	info := bot.info()
+	botUser := info.User
 	user, err := bot.api.GetUserInfo(msg.UserId)
 	if err != nil {
 		return nil, err
@@ -40,37 +66,93 @@ func NewIncomingMsg(bot *UserBot, evt *slack.MessageEvent) (*IncomingMsg, error)
 	highlight := strings.Contains(msg.Text, "<@"+botUser.Id+">")
 	direct := private || highlight
 	return &IncomingMsg{
-		UserId:    msg.UserId,
-		RawText:   msg.Text,
-		Text:      utils.StripUser(msg.Text),
-		ChannelId: msg.ChannelId,
-		Private:   private,
-		Highlight: highlight,
-		Direct:    direct,
-		User:      user,
+		TeamId:      bot.teamID(),
+		ChannelName: bot.channelName(msg.ChannelId),
+		UserId:      msg.UserId,
+		RawText:     msg.Text,
+		Text:        utils.StripUser(msg.Text),
+		ChannelId:   msg.ChannelId,
+		Private:     private,
+		Highlight:   highlight,
+		Direct:      direct,
+		User:        user,
 	}, nil
 }
 
+// Payload builds a robots.Payload out of the message, so it can be handled
+// the same way as slash commands and outgoing webhooks
+func (msg *IncomingMsg) Payload() *robots.Payload {
+	return &robots.Payload{
+		TeamID:      msg.TeamId,
+		TeamDomain:  os.Getenv("SLACK_TEAM_DOMAIN"),
+		ChannelID:   msg.ChannelId,
+		ChannelName: msg.ChannelName,
+		UserID:      msg.UserId,
+		UserName:    msg.User.Name,
+		Text:        msg.Text,
+		DryRun:      msg.DryRun,
+	}
+}
+
+// channelName looks up the name of a channel or private group the way
+// slash commands report it
+func (bot *UserBot) channelName(id string) string {
+	switch {
+	case strings.HasPrefix(id, "C"):
+		if ch, err := bot.api.GetChannelInfo(id); err == nil {
+			return ch.Name
+		}
+	case strings.HasPrefix(id, "G"):
+		if g, err := bot.api.GetGroupInfo(id); err == nil {
+			return g.Name
+		}
+	case strings.HasPrefix(id, "D"):
+		return "directmessage"
+	}
+	return id
+}
+
+// listen runs the listeners that match an ordinary channel message
+func (bot *UserBot) listen(msg *IncomingMsg) {
+	if _, err := listener.Handle(msg.Payload(), bot); err != nil {
+		bot.send(msg.ChannelId, "Error: "+err.Error())
+	}
+}
+
 func (bot *UserBot) messageReceived(evt *slack.MessageEvent) {
-	// doesn't act on messages sent by the bot itself
-	if evt.Msg.UserId == bot.api.GetInfo().User.Id {
+	// doesn't act on messages sent by the bot itself, nor on the ones
+	// without a user, like the replies robots post through webhooks. Edits,
+	// deletions, joins and the like aren't something people said, so they
+	// are left out before looking the user up
+	if evt.Msg.UserId == "" || evt.Msg.SubType != "" ||
+		evt.Msg.UserId == bot.info().User.Id {
 		return
 	}
 
+	bot.markSeen(evt.Msg.UserId)
+
 	msg, err := NewIncomingMsg(bot, evt)
 	if err != nil {
-		bot.replyError(err)
+		log.Printf("Error reading message from %s: %s\n", evt.Msg.UserId, err)
 		return
 	}
 
 	fmt.Println("UserId", msg.UserId)
 	fmt.Println("Text", msg.Text)
 
+	handled, err := dialog.Handle(msg.Payload(), bot)
+	if err != nil {
+		bot.send(msg.ChannelId, "Error: "+err.Error())
+	}
+	if handled {
+		return
+	}
+
 	if !msg.Direct {
+		bot.listen(msg)
 		return
 	}
 
-	bot.lastMessage = msg
 	bot.Handle(msg)
 
 	// if msg.Text == "timezones" {
@@ -99,103 +181,161 @@ func (bot *UserBot) messageReceived(evt *slack.MessageEvent) {
 	// return nil
 }
 
-func (bot *UserBot) replyError(err error) error {
-	msg := "Error: " + err.Error()
-	return bot.reply(msg)
+func (bot *UserBot) replyError(msg *IncomingMsg, err error) error {
+	return bot.reply(msg, "Error: "+err.Error())
 }
 
-func (bot *UserBot) reply(msg string) error {
-	if bot.lastMessage == nil {
-		return nil
-	}
-	lastMsg := bot.lastMessage
-	chanId := lastMsg.ChannelId
-	if lastMsg.Highlight {
-		msg = fmt.Sprintf("@%s: %s", lastMsg.User.Name, msg)
+// reply answers msg in the channel it came from, mentioning its author
+// when the bot was mentioned
+func (bot *UserBot) reply(msg *IncomingMsg, text string) error {
+	if msg.Highlight {
+		text = fmt.Sprintf("@%s: %s", msg.User.Name, text)
 	}
-	return bot.send(chanId, msg)
+	return bot.send(msg.ChannelId, text)
 }
 
-func (bot *UserBot) send(channelId, text string) error {
-	reply := &slack.OutgoingMessage{ChannelId: channelId, Text: text, Type: "message"}
-	return bot.wsAPI.SendMessage(reply)
+// Send replies to the channel a payload came from, allowing the bot to take
+// part in dialogs
+func (bot *UserBot) Send(p *robots.Payload, s string) {
+	bot.send(p.ChannelID, s)
 }
 
-func (bot *UserBot) sendTimezones(evt *slack.MessageEvent) error {
-	ch, err := bot.api.GetChannelInfo(evt.ChannelId)
-	if err != nil {
-		return err
+func (bot *UserBot) send(channelId, text string) error {
+	bot.mu.RLock()
+	conn := bot.conn
+	bot.mu.RUnlock()
+
+	if conn == nil {
+		return errors.New("The bot isn't connected to Slack")
 	}
+	return conn.sendMessage(channelId, text)
+}
 
-	fmt.Println("Members", ch.Members)
+// info returns what Slack told about the bot and its team when it last
+// connected
+func (bot *UserBot) info() slack.Info {
+	bot.mu.RLock()
+	defer bot.mu.RUnlock()
 
-	t := time.Now()
-	r := ""
-	for _, m := range ch.Members {
-		u, err := bot.api.GetUserInfo(m)
-		if err != nil {
-			return err
-		}
-		tStr := t.Add(time.Duration(u.TZOffset) * time.Second).Format("3:04pm")
-		r += fmt.Sprintf("*%s* timezone is *%s* and local time is *%s*\n", u.Name, u.TZLabel, tStr)
+	if bot.conn == nil {
+		return slack.Info{User: &slack.UserDetails{}}
 	}
-
-	bot.send(evt.Msg.ChannelId, r)
-	return nil
+	return bot.conn.info
 }
 
-func (bot *UserBot) presenceChanged(evt *slack.PresenceChangeEvent) {
+// teamID returns the id of the team the bot is connected to, empty until
+// it connects
+func (bot *UserBot) teamID() string {
+	if info := bot.info(); info.Team != nil {
+		return info.Team.Id
+	}
+	return ""
 }
 
 func Start() {
-	token := os.Getenv("GISTIA_BOT_TOKEN")
-	chSender := make(chan slack.OutgoingMessage)
-	chReceiver := make(chan slack.SlackEvent)
+	api := slack.New(os.Getenv("GISTIA_BOT_TOKEN"))
+	bot := &UserBot{api: api}
+	bot.SetupCommands()
 
-	api := slack.New(token)
-	api.SetDebug(true)
-	wsAPI, err := api.StartRTM("", os.Getenv("APP_URL"))
-	if err == nil {
-		fmt.Errorf("%s\n", err)
-	}
+	go bot.watchReminders()
+	go bot.watchTimers()
+	bot.run(os.Getenv("GISTIA_BOT_TOKEN"), os.Getenv("APP_URL"))
+}
 
-	bot := UserBot{api: api, wsAPI: wsAPI}
-	bot.SetupCommands()
+// run keeps the bot connected, reconnecting with an exponential backoff
+// whenever the connection can't be made or drops
+func (bot *UserBot) run(token, origin string) {
+	backoff := minBackoff
+	for {
+		started := time.Now()
+		err := bot.connect(token, origin)
+
+		// a connection that lasted resets the backoff
+		if time.Since(started) > maxBackoff {
+			backoff = minBackoff
+		}
+
+		log.Printf("Slack connection lost: %s, reconnecting in %s\n", err, backoff)
+		time.Sleep(backoff)
 
-	go wsAPI.HandleIncomingEvents(chReceiver)
-	go wsAPI.Keepalive(20 * time.Second)
-	go func(wsAPI *slack.SlackWS, chSender chan slack.OutgoingMessage) {
-		for {
-			select {
-			case msg := <-chSender:
-				wsAPI.SendMessage(&msg)
-			}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
 		}
-	}(wsAPI, chSender)
+	}
+}
+
+// connect starts a new session and handles its events until it drops
+func (bot *UserBot) connect(token, origin string) error {
+	conn, err := dialRTM(token, origin)
+	if err != nil {
+		return err
+	}
+	defer conn.close()
+
+	bot.mu.Lock()
+	bot.conn = conn
+	bot.mu.Unlock()
+
+	done := make(chan struct{})
+	defer close(done)
+	go conn.keepalive(done)
+
 	for {
-		select {
-		case msg := <-chReceiver:
-			fmt.Print("Event Received: ")
-			switch msg.Data.(type) {
-			case slack.HelloEvent:
-				// Ignore hello
-			case *slack.MessageEvent:
-				a := msg.Data.(*slack.MessageEvent)
-				fmt.Printf("Message: %v\n", a)
-				go bot.messageReceived(a)
-			case *slack.PresenceChangeEvent:
-				a := msg.Data.(*slack.PresenceChangeEvent)
-				fmt.Printf("Presence Change: %v\n", a)
-				go bot.presenceChanged(a)
-			case slack.LatencyReport:
-				a := msg.Data.(slack.LatencyReport)
-				fmt.Printf("Current latency: %v\n", a.Value)
-			case *slack.SlackWSError:
-				error := msg.Data.(*slack.SlackWSError)
-				fmt.Printf("Error: %d - %s\n", error.Code, error.Msg)
-			default:
-				fmt.Printf("Unexpected: %v\n", msg.Data)
-			}
+		typ, raw, err := conn.receive()
+		if err != nil {
+			return err
+		}
+
+		if err := bot.dispatch(typ, raw); err != nil {
+			return err
 		}
 	}
 }
+
+// dispatch handles an event, each message in its own goroutine. It only
+// fails for errors that end the session
+func (bot *UserBot) dispatch(typ string, raw json.RawMessage) error {
+	switch typ {
+	case "hello":
+		go bot.subscribePresence()
+	case "pong":
+		// nothing to do
+	case "message":
+		evt := &slack.MessageEvent{}
+		if err := json.Unmarshal(raw, evt); err != nil {
+			log.Printf("Error decoding message: %s\n", err)
+			return nil
+		}
+		go bot.messageReceived(evt)
+	case "presence_change":
+		evt := &slack.PresenceChangeEvent{}
+		if err := json.Unmarshal(raw, evt); err != nil {
+			log.Printf("Error decoding presence change: %s\n", err)
+			return nil
+		}
+		go bot.presenceChanged(evt)
+	case "dnd_updated", "dnd_updated_user":
+		evt := &dndEvent{}
+		if err := json.Unmarshal(raw, evt); err != nil {
+			log.Printf("Error decoding do not disturb change: %s\n", err)
+			return nil
+		}
+		go bot.dndChanged(evt)
+	case "reaction_added":
+		evt := &reactionEvent{}
+		if err := json.Unmarshal(raw, evt); err != nil {
+			log.Printf("Error decoding reaction: %s\n", err)
+			return nil
+		}
+		go bot.reactionAdded(evt)
+	case "team_migration_started":
+		return errors.New("team migration started")
+	case "error":
+		evt := rtmEvent{}
+		json.Unmarshal(raw, &evt)
+		log.Printf("Slack error: %d - %s\n", evt.Error.Code, evt.Error.Msg)
+	}
+
+	return nil
+}
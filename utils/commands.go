@@ -44,10 +44,7 @@ func (c *CmdHandler) Process(s string) {
 
 	if cmd.IsDefault() {
 		if h := c.handlers["_default"]; h != nil {
-			err := h(c.payload, cmd)
-			if err != nil {
-				c.msgr.SendError(c.payload, err)
-			}
+			c.run(h, cmd)
 			return
 		}
 
@@ -63,10 +60,7 @@ func (c *CmdHandler) Process(s string) {
 
 	for k := range c.handlers {
 		if cmd.Is(k) {
-			err := c.handlers[k](c.payload, cmd)
-			if err != nil {
-				c.msgr.SendError(c.payload, err)
-			}
+			c.run(c.handlers[k], cmd)
 			return
 		}
 	}
@@ -75,6 +69,18 @@ func (c *CmdHandler) Process(s string) {
 	c.sendHelp()
 }
 
+// run calls the handler and, for commands run with --dry-run, reports
+// the changes it would have made
+func (c *CmdHandler) run(h HandlerFunc, cmd Command) {
+	err := h(c.payload, cmd)
+	if err != nil {
+		c.msgr.SendError(c.payload, err)
+	}
+	if c.payload.DryRun.Active() {
+		c.msgr.Send(c.payload, c.payload.DryRun.Report())
+	}
+}
+
 func (c *CmdHandler) sendHelp() {
 	s := "*Usage:* `!" + c.name + " <command>`\n"
 	if len(c.handlers) > 0 {
@@ -101,10 +107,12 @@ type Command struct {
 	Command   string
 	Arguments []string
 	Params    map[string]string
+	Flags     map[string]bool
 }
 
 func NewCommand(c string) Command {
 	params := map[string]string{}
+	flags := map[string]bool{}
 	args := []string{}
 	parts := strings.Split(c, " ")
 
@@ -115,6 +123,11 @@ func NewCommand(c string) Command {
 		p := parts[0]
 		parts = append(parts[:0], parts[1:]...)
 
+		if strings.HasPrefix(p, "--") && len(p) > 2 {
+			flags[p[2:]] = true
+			continue
+		}
+
 		r := strings.Split(p, ":")
 		if len(r) > 1 {
 			params[r[0]] = r[1]
@@ -123,7 +136,7 @@ func NewCommand(c string) Command {
 		}
 	}
 
-	return Command{Command: cmd, Arguments: args, Params: params}
+	return Command{Command: cmd, Arguments: args, Params: params, Flags: flags}
 }
 
 func (c *Command) Arg(idx int) string {
@@ -142,6 +155,11 @@ func (c *Command) Param(s string) string {
 	return c.Params[s]
 }
 
+// Flag returns true if the command was given --s
+func (c *Command) Flag(s string) bool {
+	return c.Flags[s]
+}
+
 func (c *Command) IsDefault() bool {
 	return c.Command == ""
 }
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/gistia/slackbot/robots"
+	"github.com/nlopes/slack"
 )
 
 type SlackHandler struct {
@@ -37,6 +38,9 @@ func (sh SlackHandler) SendError(p *robots.Payload, err error) {
 }
 
 func (sh SlackHandler) SendWithAttachments(p *robots.Payload, s string, atts []robots.Attachment) {
+	if p.DryRun.Active() && s != "" {
+		s = "*[dry run]* " + s
+	}
 	fmt.Println(p.TeamDomain)
 	fmt.Println(" ->", s)
 	for _, a := range atts {
@@ -62,6 +66,45 @@ func (sh SlackHandler) SendWithAttachments(p *robots.Payload, s string, atts []r
 	response.Send()
 }
 
+// SendCard posts a single attachment through the Web API and returns the
+// timestamp of the posted message, so reactions to it can be traced back.
+// Without a bot token, or on a dry run, it falls back to the incoming
+// webhook and returns an empty timestamp.
+func (sh SlackHandler) SendCard(p *robots.Payload, att robots.Attachment) (string, error) {
+	if os.Getenv("GISTIA_BOT_TOKEN") == "" || p.DryRun.Active() {
+		sh.SendWithAttachments(p, "", []robots.Attachment{att})
+		return "", nil
+	}
+
+	params := slack.NewPostMessageParameters()
+	params.Username = sh.BotName
+	params.IconEmoji = sh.Icon
+	params.IconURL = sh.IconUrl
+	params.Attachments = []slack.Attachment{slackAttachment(att)}
+
+	_, ts, err := SlackAPI().PostMessage(p.ChannelID, "", params)
+	return ts, err
+}
+
+func slackAttachment(a robots.Attachment) slack.Attachment {
+	att := slack.Attachment{
+		Fallback:  a.Fallback,
+		Color:     a.Color,
+		Pretext:   a.Pretext,
+		Title:     a.Title,
+		TitleLink: a.TitleLink,
+		Text:      a.Text,
+	}
+	for _, f := range a.Fields {
+		att.Fields = append(att.Fields, slack.AttachmentField{
+			Title: f.Title, Value: f.Value, Short: f.Short})
+	}
+	for _, m := range a.MarkdownIn {
+		att.MarkdownIn = append(att.MarkdownIn, string(m))
+	}
+	return att
+}
+
 func (sh SlackHandler) SendMsg(channel, s string) {
 	domain := os.Getenv("SLACK_TEAM_DOMAIN")
 	response := &robots.IncomingWebhook{
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"errors"
+
+	"github.com/gistia/slackbot/db"
+	"github.com/gistia/slackbot/robots"
+)
+
+// RunRobot runs the robots registered for name with text, as if the user
+// that sent p had run them in the same channel
+func RunRobot(p *robots.Payload, name string, text string) error {
+	rs := robots.Get(name)
+	if len(rs) < 1 {
+		return errors.New("The " + name + " bot isn't available")
+	}
+
+	fp := &robots.Payload{}
+	*fp = *p
+	fp.Text = text
+	fp.Robot = name
+	for _, r := range rs {
+		r.Run(fp)
+	}
+	return nil
+}
+
+// ClaimStoryTimer has the timer bot stop the timer the user started for the
+// Pivotal story and claim its time to it. It returns false when the user
+// has no such timer running
+func ClaimStoryTimer(p *robots.Payload, storyID string) (bool, error) {
+	timer, err := db.GetStartedTimerByStory(p.TeamID, p.UserName, storyID)
+	if err != nil || timer == nil {
+		return false, err
+	}
+
+	return true, RunRobot(p, "timer", "claim "+timer.Name)
+}
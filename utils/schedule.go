@@ -0,0 +1,280 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule describes when something should happen: the next occurrence and,
+// for repeating schedules, a recurrence rule like "weekday 09:00"
+type Schedule struct {
+	Next       time.Time
+	Recurrence string
+}
+
+const (
+	timeExpr    = `(\d{1,2}(?::\d{2})?\s*(?:am|pm)?|noon|midnight)`
+	weekdayExpr = `(?:monday|tuesday|wednesday|thursday|friday|saturday|sunday)`
+)
+
+var (
+	inRegex = regexp.MustCompile(
+		`(?i)(?:^|\s+)in\s+(\d+|an?|one)\s*(minutes?|mins?|m|hours?|hrs?|h|days?|d|weeks?|w)$`)
+	everyRegex = regexp.MustCompile(
+		`(?i)(?:^|\s+)every\s+(day|weekday|` + weekdayExpr + `)(?:\s+at\s+` + timeExpr + `)?$`)
+	onRegex = regexp.MustCompile(
+		`(?i)(?:^|\s+)(today|tomorrow|on\s+` + weekdayExpr + `|on\s+\d{4}-\d{2}-\d{2})(?:\s+at\s+` + timeExpr + `)?$`)
+	atRegex = regexp.MustCompile(
+		`(?i)(?:^|\s+)at\s+` + timeExpr + `$`)
+	clockRegex = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?\s*(am|pm)?$`)
+	agoRegex   = regexp.MustCompile(
+		`(?i)^(\d+|an?|one)\s*(minutes?|mins?|m|hours?|hrs?|h)\s+ago$`)
+)
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// ParseSchedule looks for a time expression at the end of s, like "in 2 hours",
+// "tomorrow at 9am", "on friday", "at 17:30" or "every weekday at 9am". It
+// returns the text preceding the expression and the parsed schedule, relative
+// to now and in now's location.
+func ParseSchedule(s string, now time.Time) (string, *Schedule, error) {
+	s = strings.TrimSpace(s)
+
+	if m := inRegex.FindStringSubmatchIndex(s); m != nil {
+		amount := strings.ToLower(s[m[2]:m[3]])
+		unit := strings.ToLower(s[m[4]:m[5]])
+
+		n := 1
+		if IsNumber(amount) {
+			n, _ = strconv.Atoi(amount)
+		}
+
+		var d time.Duration
+		switch unit[0] {
+		case 'm':
+			d = time.Duration(n) * time.Minute
+		case 'h':
+			d = time.Duration(n) * time.Hour
+		case 'd':
+			d = time.Duration(n) * 24 * time.Hour
+		case 'w':
+			d = time.Duration(n) * 7 * 24 * time.Hour
+		}
+
+		return s[:m[0]], &Schedule{Next: now.Add(d)}, nil
+	}
+
+	if m := everyRegex.FindStringSubmatchIndex(s); m != nil {
+		days := strings.ToLower(s[m[2]:m[3]])
+		clock := "9am"
+		if m[4] >= 0 {
+			clock = s[m[4]:m[5]]
+		}
+
+		hour, min, err := parseClock(clock)
+		if err != nil {
+			return "", nil, err
+		}
+
+		rec := fmt.Sprintf("%s %02d:%02d", days, hour, min)
+		next, err := NextOccurrence(rec, now)
+		if err != nil {
+			return "", nil, err
+		}
+
+		return s[:m[0]], &Schedule{Next: next, Recurrence: rec}, nil
+	}
+
+	if m := onRegex.FindStringSubmatchIndex(s); m != nil {
+		day := strings.ToLower(s[m[2]:m[3]])
+		clock := "9am"
+		if m[4] >= 0 {
+			clock = s[m[4]:m[5]]
+		}
+
+		hour, min, err := parseClock(clock)
+		if err != nil {
+			return "", nil, err
+		}
+
+		base := time.Date(now.Year(), now.Month(), now.Day(), hour, min, 0, 0, now.Location())
+		day = strings.TrimSpace(strings.TrimPrefix(day, "on"))
+
+		wd, isWeekday := weekdays[day]
+		switch {
+		case day == "today":
+		case day == "tomorrow":
+			base = base.AddDate(0, 0, 1)
+		case isWeekday:
+			offset := (int(wd) - int(now.Weekday()) + 7) % 7
+			base = base.AddDate(0, 0, offset)
+			if !base.After(now) {
+				base = base.AddDate(0, 0, 7)
+			}
+		default:
+			date, err := time.ParseInLocation("2006-01-02", day, now.Location())
+			if err != nil {
+				return "", nil, err
+			}
+			base = date.Add(time.Duration(hour)*time.Hour + time.Duration(min)*time.Minute)
+		}
+
+		if !base.After(now) {
+			return "", nil, errors.New("That time is already in the past")
+		}
+
+		return s[:m[0]], &Schedule{Next: base}, nil
+	}
+
+	if m := atRegex.FindStringSubmatchIndex(s); m != nil {
+		hour, min, err := parseClock(s[m[2]:m[3]])
+		if err != nil {
+			return "", nil, err
+		}
+
+		next := time.Date(now.Year(), now.Month(), now.Day(), hour, min, 0, 0, now.Location())
+		if !next.After(now) {
+			next = next.AddDate(0, 0, 1)
+		}
+
+		return s[:m[0]], &Schedule{Next: next}, nil
+	}
+
+	return "", nil, errors.New("I couldn't understand when. Try something like `in 2 hours`, `tomorrow at 9am` or `every weekday at 9am`")
+}
+
+// ParsePast reads a time that already happened, like "40m ago", "an hour
+// ago", "1h30m ago" or "at 17:30", the latter being the last time the clock
+// showed it. An empty s means now
+func ParsePast(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return now, nil
+	}
+
+	if m := agoRegex.FindStringSubmatch(s); m != nil {
+		n := 1
+		if IsNumber(m[1]) {
+			n, _ = strconv.Atoi(m[1])
+		}
+
+		unit := time.Minute
+		if strings.ToLower(m[2])[0] == 'h' {
+			unit = time.Hour
+		}
+		return now.Add(-time.Duration(n) * unit), nil
+	}
+
+	if strings.HasSuffix(s, " ago") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimSuffix(s, " ago")))
+		if err == nil && d >= 0 {
+			return now.Add(-d), nil
+		}
+	}
+
+	hour, min, err := parseClock(strings.TrimPrefix(strings.ToLower(s), "at "))
+	if err != nil {
+		return now, errors.New("I couldn't understand when. Try something like `40m ago` or `at 17:30`")
+	}
+
+	t := time.Date(now.Year(), now.Month(), now.Day(), hour, min, 0, 0, now.Location())
+	if t.After(now) {
+		t = t.AddDate(0, 0, -1)
+	}
+	return t, nil
+}
+
+// NextOccurrence returns the first time after the given time matching a
+// recurrence rule like "day 09:00", "weekday 09:00" or "friday 16:00",
+// in after's location
+func NextOccurrence(rec string, after time.Time) (time.Time, error) {
+	parts := strings.Split(rec, " ")
+	if len(parts) != 2 {
+		return after, errors.New("Invalid recurrence: " + rec)
+	}
+
+	hour, min, err := parseClock(parts[1])
+	if err != nil {
+		return after, err
+	}
+
+	days := parts[0]
+	for i := 0; i <= 7; i++ {
+		d := after.AddDate(0, 0, i)
+		candidate := time.Date(d.Year(), d.Month(), d.Day(), hour, min, 0, 0, after.Location())
+		if !candidate.After(after) {
+			continue
+		}
+
+		wd := candidate.Weekday()
+		switch {
+		case days == "day":
+			return candidate, nil
+		case days == "weekday":
+			if wd != time.Saturday && wd != time.Sunday {
+				return candidate, nil
+			}
+		default:
+			day, ok := weekdays[days]
+			if !ok {
+				return after, errors.New("Invalid recurrence: " + rec)
+			}
+			if wd == day {
+				return candidate, nil
+			}
+		}
+	}
+
+	return after, errors.New("Invalid recurrence: " + rec)
+}
+
+func parseClock(s string) (int, int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "noon":
+		return 12, 0, nil
+	case "midnight":
+		return 0, 0, nil
+	}
+
+	m := clockRegex.FindStringSubmatch(s)
+	if m == nil {
+		return 0, 0, errors.New("Invalid time: " + s)
+	}
+
+	hour, _ := strconv.Atoi(m[1])
+	min := 0
+	if m[2] != "" {
+		min, _ = strconv.Atoi(m[2])
+	}
+
+	if m[3] != "" {
+		if hour < 1 || hour > 12 {
+			return 0, 0, errors.New("Invalid time: " + s)
+		}
+		if hour == 12 {
+			hour = 0
+		}
+		if m[3] == "pm" {
+			hour += 12
+		}
+	}
+
+	if hour > 23 || min > 59 {
+		return 0, 0, errors.New("Invalid time: " + s)
+	}
+
+	return hour, min, nil
+}
@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"github.com/gistia/slackbot/db"
+	"github.com/gistia/slackbot/robots"
+)
+
+// SettingContext returns where settings are looked up from for a command:
+// the user that ran it, the channel it was run in and their team
+func SettingContext(p *robots.Payload) db.SettingContext {
+	return db.SettingContext{
+		Team:    p.TeamID,
+		Channel: p.ChannelName,
+		User:    p.UserName,
+	}
+}
+
+// ArgOrSetting returns the argument at idx or, when it's missing, the value
+// of the setting that applies to the command
+func ArgOrSetting(p *robots.Payload, cmd Command, idx int, name string) (string, error) {
+	if arg := cmd.Arg(idx); arg != "" {
+		return arg, nil
+	}
+
+	s, err := db.ResolveSetting(SettingContext(p), name)
+	if err != nil || s == nil {
+		return "", err
+	}
+	return s.Value, nil
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nlopes/slack"
+)
+
+// SlackAPI returns a Slack Web API client using the bot token
+func SlackAPI() *slack.Slack {
+	return slack.New(os.Getenv("GISTIA_BOT_TOKEN"))
+}
+
+// ErrUnknownTarget is returned for a @user or #channel that isn't, or is no
+// longer, in the team
+var ErrUnknownTarget = errors.New("not found in the team")
+
+// CheckTarget makes sure a @user or #channel can be messaged, returning
+// ErrUnknownTarget when it can't. Without a bot token it can't tell, and
+// only fails on Slack errors
+func CheckTarget(target string) error {
+	if os.Getenv("GISTIA_BOT_TOKEN") == "" {
+		return nil
+	}
+
+	api := SlackAPI()
+	name := target[1:]
+	if strings.HasPrefix(target, "#") {
+		channels, err := api.GetChannels(true)
+		if err != nil {
+			return err
+		}
+		for _, c := range channels {
+			if c.Name == name {
+				return nil
+			}
+		}
+		return ErrUnknownTarget
+	}
+
+	users, err := api.GetUsers()
+	if err != nil {
+		return err
+	}
+	for _, u := range users {
+		if u.Name == name && !u.Deleted {
+			return nil
+		}
+	}
+	return ErrUnknownTarget
+}
+
+// UserLocation returns the timezone set on the Slack profile for the given
+// user id, falling back to UTC when it can't be determined
+func UserLocation(userId string) *time.Location {
+	u, err := SlackAPI().GetUserInfo(userId)
+	if err != nil {
+		return time.UTC
+	}
+
+	return Location(u)
+}
+
+// Location returns the timezone set on the user's Slack profile, falling
+// back to UTC when there's none
+func Location(u *slack.User) *time.Location {
+	if u.TZ == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(u.TZ)
+	if err != nil {
+		return time.FixedZone(u.TZLabel, u.TZOffset)
+	}
+
+	return loc
+}
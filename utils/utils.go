@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"os"
 	"reflect"
 	"regexp"
 	"strconv"
@@ -62,3 +63,18 @@ func FormatHour(h int64) string {
 	v := float64(h) / 60
 	return fmt.Sprintf("%.2f", v)
 }
+
+// IsAdmin returns true if the user is listed in ADMIN_USERS, a comma
+// separated list of TEAMID:USERID pairs, so that admins of one team are
+// never taken for admins of another and renaming a user changes nothing
+func IsAdmin(team, user string) bool {
+	if team == "" || user == "" {
+		return false
+	}
+	for _, u := range strings.Split(os.Getenv("ADMIN_USERS"), ",") {
+		if strings.TrimSpace(u) == team+":"+user {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gistia/slackbot/db"
+)
+
+// DefaultWorkHours are the hours people are expected to work in their own
+// timezone, unless the WORK_HOURS setting says otherwise
+const DefaultWorkHours = "9-17"
+
+// DefaultTimerNudge is how long a timer runs before it's considered
+// forgotten, unless the TIMER_NUDGE_AFTER setting says otherwise
+const DefaultTimerNudge = 6 * time.Hour
+
+// WorkHours returns the first and last hour of work that apply in c
+func WorkHours(c db.SettingContext) (int, int, error) {
+	hours := DefaultWorkHours
+	s, err := db.ResolveSetting(c, "WORK_HOURS")
+	if err != nil {
+		return 0, 0, err
+	}
+	if s != nil {
+		hours = s.Value
+	}
+
+	from, to, err := ParseWorkHours(hours)
+	if err != nil {
+		return 0, 0, fmt.Errorf("Invalid WORK_HOURS for %s: %s", c.User, err)
+	}
+	return from, to, nil
+}
+
+// ParseWorkHours parses work hours like "9-17"
+func ParseWorkHours(s string) (int, int, error) {
+	invalid := errors.New("use hours like 9-17")
+
+	parts := strings.Split(s, "-")
+	if len(parts) != 2 {
+		return 0, 0, invalid
+	}
+
+	from, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, invalid
+	}
+	to, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil {
+		return 0, 0, invalid
+	}
+	if from < 0 || to > 24 || from >= to {
+		return 0, 0, invalid
+	}
+
+	return from, to, nil
+}
+
+// ForgottenTimer returns why the timer looks like it was left running,
+// or an empty string if it doesn't. That's when it ran for longer than the
+// TIMER_NUDGE_AFTER setting or past the end of the work day it started in
+func ForgottenTimer(t *db.Timer, c db.SettingContext, loc *time.Location) (string, error) {
+	limit := DefaultTimerNudge
+	s, err := db.ResolveSetting(c, "TIMER_NUDGE_AFTER")
+	if err != nil {
+		return "", err
+	}
+	if s != nil {
+		if limit, err = time.ParseDuration(s.Value); err != nil {
+			return "", fmt.Errorf("Invalid TIMER_NUDGE_AFTER %q", s.Value)
+		}
+	}
+
+	if d := t.Active(); d > limit {
+		return fmt.Sprintf("it ran for *%s*", t.Duration()), nil
+	}
+
+	end := WorkDayEnd(t, c, loc)
+	if end == nil {
+		return "", nil
+	}
+
+	stopped := time.Now()
+	if t.FinishedAt != nil {
+		stopped = *t.FinishedAt
+	}
+	if stopped.After(*end) {
+		return fmt.Sprintf("it ran past the end of the work day at *%s*", end.In(loc).Format("Mon 3:04pm")), nil
+	}
+	return "", nil
+}
+
+// WorkDayEnd returns when the work day the timer started in ended, or nil
+// if it started after work hours
+func WorkDayEnd(t *db.Timer, c db.SettingContext, loc *time.Location) *time.Time {
+	if t.CreatedAt == nil {
+		return nil
+	}
+
+	_, to, err := WorkHours(c)
+	if err != nil {
+		return nil
+	}
+
+	start := t.CreatedAt.In(loc)
+	end := time.Date(start.Year(), start.Month(), start.Day(), to, 0, 0, 0, loc)
+	if !start.Before(end) {
+		return nil
+	}
+	return &end
+}
@@ -0,0 +1,119 @@
+package web
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/gistia/slackbot/timesheet"
+)
+
+// Sign adds an expiration and a signature to the query, so a link built from
+// it works until expires without any other authentication. It needs the
+// LINK_SECRET environment variable
+func Sign(v url.Values, expires time.Time) error {
+	v.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	sig, err := signature(v)
+	if err != nil {
+		return err
+	}
+	v.Set("sig", sig)
+	return nil
+}
+
+// Verify checks the signature and expiration added by Sign
+func Verify(v url.Values) error {
+	expires, err := strconv.ParseInt(v.Get("expires"), 10, 64)
+	if err != nil {
+		return errors.New("Invalid link")
+	}
+	if time.Now().Unix() > expires {
+		return errors.New("This link has expired")
+	}
+
+	sig, err := signature(v)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal([]byte(sig), []byte(v.Get("sig"))) {
+		return errors.New("Invalid link")
+	}
+	return nil
+}
+
+// signature signs every value of the query but the signature itself
+func signature(v url.Values) (string, error) {
+	secret := os.Getenv("LINK_SECRET")
+	if secret == "" {
+		return "", errors.New("Signed links need LINK_SECRET to be set")
+	}
+
+	signed := url.Values{}
+	for k, vs := range v {
+		if k != "sig" {
+			signed[k] = vs
+		}
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed.Encode()))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// TimesheetLink returns a signed link to the CSV export of the timesheet of
+// user, or of the whole team if user is empty, which works until expires
+func TimesheetLink(team, user string, from, to, expires time.Time) (string, error) {
+	v := url.Values{
+		"team": {team},
+		"user": {user},
+		"from": {from.Format(time.RFC3339)},
+		"to":   {to.Format(time.RFC3339)},
+	}
+	if err := Sign(v, expires); err != nil {
+		return "", err
+	}
+
+	return os.Getenv("APP_URL") + "timesheet.csv?" + v.Encode(), nil
+}
+
+// TimesheetCSV serves the timesheet a link from TimesheetLink points to
+func TimesheetCSV(w http.ResponseWriter, r *http.Request) {
+	v := r.URL.Query()
+	if err := Verify(v); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	from, err := time.Parse(time.RFC3339, v.Get("from"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := time.Parse(time.RFC3339, v.Get("to"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := timesheet.Build(v.Get("team"), v.Get("user"), from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	name := v.Get("user")
+	if name == "" {
+		name = "team"
+	}
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(
+		`attachment; filename="timesheet-%s-%s.csv"`, name, from.Format("2006-01-02")))
+	report.WriteCSV(w)
+}
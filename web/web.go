@@ -16,6 +16,7 @@ var store = sessions.NewCookieStore([]byte("6051dfc4b4602d7d8261f51c3bea2ad941a4
 
 type PokerPage struct {
 	SessionTitle string
+	TeamID       string
 	ChannelName  string
 	ChannelID    string
 	Message      string
@@ -24,10 +25,11 @@ type PokerPage struct {
 
 func NewPokerStories(w http.ResponseWriter, r *http.Request) {
 	webSession, _ := store.Get(r, "session")
+	team := q(r.URL, "team")
 	channel := q(r.URL, "channel")
 	channelId := q(r.URL, "channel_id")
 
-	session, err := db.GetCurrentSession(channel)
+	session, err := db.GetCurrentSession(team, channel)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -52,6 +54,7 @@ func NewPokerStories(w http.ResponseWriter, r *http.Request) {
 
 	page := &PokerPage{
 		SessionTitle: session.Title,
+		TeamID:       team,
 		ChannelName:  channel,
 		ChannelID:    channelId,
 		Stories:      stories,
@@ -76,11 +79,12 @@ func CreatePokerStories(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	team := r.PostFormValue("team")
 	channel := r.PostFormValue("channel")
 	channelId := r.PostFormValue("channel_id")
 	stories := r.PostFormValue("stories")
 
-	session, err := db.GetCurrentSession(channel)
+	session, err := db.GetCurrentSession(team, channel)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -104,7 +108,8 @@ func CreatePokerStories(w http.ResponseWriter, r *http.Request) {
 	webSession.Values["message"] = msg
 	webSession.Save(r, w)
 
-	location := fmt.Sprintf("/poker?channel_id=%s&channel=%s", channelId, channel)
+	location := fmt.Sprintf("/poker?team=%s&channel_id=%s&channel=%s",
+		team, channelId, channel)
 	http.Redirect(w, r, location, 301)
 }
 